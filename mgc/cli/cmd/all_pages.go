@@ -0,0 +1,21 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+const allPagesFlag = "cli.all-pages"
+
+func addAllPagesFlag(cmd *cobra.Command) {
+	cmd.Root().PersistentFlags().Bool(
+		allPagesFlag,
+		false,
+		"Fetch every page of list operations that support pagination, merging them in a single result",
+	)
+}
+
+func getAllPagesFlag(cmd *cobra.Command) bool {
+	all, err := cmd.Root().PersistentFlags().GetBool(allPagesFlag)
+	if err != nil {
+		return false
+	}
+	return all
+}
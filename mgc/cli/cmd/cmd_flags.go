@@ -27,6 +27,8 @@ type cmdFlags struct {
 	extraFlags     []*flag.Flag
 	childFlags     []*flag.Flag
 
+	resources *resourceCompleter // optional, completes ID-like values with existing resources
+
 	knownFlags map[flag.NormalizedName]*flag.Flag // all known flags, both existing and schemaFlags
 }
 
@@ -306,6 +308,9 @@ func (cf *cmdFlags) completeFlagValues(f *flag.Flag, cmd *cobra.Command, args []
 		directive = cobra.ShellCompDirectiveFilterDirs
 
 	default:
+		if cf.resources != nil {
+			completions = cf.resources.complete(cf, f, toComplete, completions)
+		}
 		if f.DefValue != "" {
 			completions = append(completions, f.DefValue)
 		}
@@ -66,8 +66,14 @@ func handleResultWithValue(result core.ResultWithValue, output string, cmd *cobr
 		logValidationErr(err)
 	}
 
+	query := getQueryFlag(cmd)
+	value, err = applyQuery(value, query)
+	if err != nil {
+		return err
+	}
+
 	name, options := parseOutputFormatter(output)
-	if name == "" {
+	if name == "" && query == "" {
 		if formatter, ok := core.ResultAs[core.ResultWithDefaultFormatter](result); ok {
 			fmt.Println(formatter.DefaultFormatter())
 			return nil
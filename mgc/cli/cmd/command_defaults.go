@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/cli/cmd/schema_flags"
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+const noDefaultsFlag = "cli.no-defaults"
+
+func addNoDefaultsFlag(cmd *cobra.Command) {
+	cmd.Root().PersistentFlags().Bool(
+		noDefaultsFlag,
+		false,
+		"Ignore the defaults of this command set in the 'commandDefaults' config",
+	)
+}
+
+func getNoDefaultsFlag(cmd *cobra.Command) bool {
+	noDefaults, err := cmd.Root().PersistentFlags().GetBool(noDefaultsFlag)
+	if err != nil {
+		return false
+	}
+	return noDefaults
+}
+
+// Keys are resolved as if typed in the command line, so command aliases (ie: "vm") also work
+func findCommandDefaults(cmd *cobra.Command, all map[string]config.CommandDefaults) (defaults config.CommandDefaults, ok bool) {
+	for key, d := range all {
+		found, args, err := cmd.Root().Find(strings.Fields(key))
+		if err == nil && len(args) == 0 && found == cmd {
+			return d, true
+		}
+	}
+	return
+}
+
+func commandDefaultValue(value any) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func setDefaultFlagValue(f *flag.Flag, value any) error {
+	s, err := commandDefaultValue(value)
+	if err != nil {
+		return &flagError{Flag: f, Err: err}
+	}
+	if err = f.Value.Set(s); err != nil {
+		return &flagError{Flag: f, Err: err}
+	}
+	f.Changed = true
+	return nil
+}
+
+// Config keys are case insensitive, so names are matched ignoring the case
+func findSchemaFlag(cf *cmdFlags, propName string, isConfig bool) *flag.Flag {
+	for _, f := range cf.schemaFlags {
+		desc := f.Value.(schema_flags.SchemaFlagValue).Desc()
+		if desc.IsConfig == isConfig && strings.EqualFold(desc.PropName, propName) {
+			return f
+		}
+	}
+	return nil
+}
+
+func findFlag(cmd *cobra.Command, name string) (found *flag.Flag) {
+	name = strings.TrimLeft(name, "-")
+	cmd.Flags().VisitAll(func(f *flag.Flag) {
+		if found == nil && (strings.EqualFold(f.Name, name) || f.Shorthand == name) {
+			found = f
+		}
+	})
+	return
+}
+
+// Only flags not given in the command line (including positional arguments) receive the defaults
+func applyCommandDefaults(cmd *cobra.Command, cf *cmdFlags, defaults config.CommandDefaults) error {
+	apply := func(kind string, values map[string]any, lookup func(name string) *flag.Flag) error {
+		for name, value := range values {
+			f := lookup(name)
+			if f == nil {
+				return fmt.Errorf("command defaults of %q: unknown %s %q", cmd.CommandPath(), kind, name)
+			}
+			if fv, ok := f.Value.(schema_flags.SchemaFlagValue); (ok && fv.Changed()) || f.Changed {
+				continue
+			}
+			if err := setDefaultFlagValue(f, value); err != nil {
+				return err
+			}
+			logger().Debugw("applied command default", "flag", f.Name, "value", value)
+		}
+		return nil
+	}
+
+	err := apply("parameter", defaults.Parameters, func(name string) *flag.Flag {
+		return findSchemaFlag(cf, name, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = apply("config", defaults.Configs, func(name string) *flag.Flag {
+		return findSchemaFlag(cf, name, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	return apply("flag", defaults.Flags, func(name string) *flag.Flag {
+		return findFlag(cmd, name)
+	})
+}
+
+func loadCommandDefaults(sdk *mgcSdk.Sdk, cmd *cobra.Command, cf *cmdFlags) error {
+	if getNoDefaultsFlag(cmd) {
+		return nil
+	}
+
+	var all map[string]config.CommandDefaults
+	if err := sdk.Config().Get(config.CommandDefaultsKey, &all); err != nil {
+		logger().Warnw("ignored command defaults", "error", err)
+		return nil
+	}
+
+	defaults, ok := findCommandDefaults(cmd, all)
+	if !ok {
+		return nil
+	}
+
+	return applyCommandDefaults(cmd, cf, defaults)
+}
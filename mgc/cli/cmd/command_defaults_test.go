@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/cli/cmd/schema_flags"
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/spf13/cobra"
+)
+
+func Test_findCommandDefaults(t *testing.T) {
+	root := &cobra.Command{Use: "mgc"}
+	group := &cobra.Command{Use: "virtual-machine", Aliases: []string{"vm"}}
+	list := &cobra.Command{Use: "list", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(group)
+	group.AddCommand(list)
+
+	all := map[string]config.CommandDefaults{
+		"vm list":         {Flags: map[string]any{"output": "json"}},
+		"virtual-machine": {Flags: map[string]any{"output": "yaml"}},
+	}
+
+	defaults, ok := findCommandDefaults(list, all)
+	if !ok {
+		t.Fatalf("expected defaults to be found by the command alias")
+	}
+	checkExpectedString(t, "output", "json", defaults.Flags["output"].(string))
+
+	if _, ok = findCommandDefaults(list, map[string]config.CommandDefaults{"virtual-machine get": {}}); ok {
+		t.Errorf("unexpected defaults of another command")
+	}
+}
+
+func Test_applyCommandDefaults(t *testing.T) {
+	type parameters struct {
+		Name  string   `json:"name"`
+		Other string   `json:"other"`
+		Tags  []string `json:"tags"`
+	}
+	type configs struct {
+		Region string `json:"region"`
+	}
+
+	paramsSchema, err := mgcSchemaPkg.SchemaFromType[parameters]()
+	checkError(t, "SchemaFromType", nil, err)
+	configsSchema, err := mgcSchemaPkg.SchemaFromType[configs]()
+	checkError(t, "SchemaFromType", nil, err)
+
+	flags, err := newCmdFlags(&cobra.Command{}, paramsSchema, configsSchema, []string{"name"}, nil)
+	checkError(t, "newCmdFlags", nil, err)
+
+	cmd := &cobra.Command{Use: "testing", Args: flags.positionalArgsFunction}
+	flags.addFlags(cmd)
+	cmd.Flags().StringP("output", "o", "", "output")
+
+	err = cmd.ParseFlags([]string{"--other", "explicit"})
+	checkError(t, "ParseFlags", nil, err)
+	err = cmd.Args(cmd, []string{"positional"})
+	checkError(t, "cmd.Args", nil, err)
+
+	err = applyCommandDefaults(cmd, flags, config.CommandDefaults{
+		Parameters: map[string]any{"name": "default-name", "other": "default-other", "tags": []any{"a", "b"}},
+		Configs:    map[string]any{"REGION": "br-ne1"},
+		Flags:      map[string]any{"o": "table=id,name"},
+	})
+	checkError(t, "applyCommandDefaults", nil, err)
+
+	checkExpectedString(t, "name", "positional", flags.knownFlags["name"].Value.String())
+	checkExpectedString(t, "other", "explicit", flags.knownFlags["other"].Value.String())
+	checkExpectedString(t, "region", "br-ne1", flags.knownFlags["region"].Value.String())
+	output, _ := cmd.Flags().GetString("output")
+	checkExpectedString(t, "output", "table=id,name", output)
+
+	v, err := flags.knownFlags["tags"].Value.(schema_flags.SchemaFlagValue).Parse()
+	checkError(t, "parse tags", nil, err)
+	checkExpectedString(t, "tags", "[a b]", fmt.Sprint(v))
+
+	err = applyCommandDefaults(cmd, flags, config.CommandDefaults{Flags: map[string]any{"missing": true}})
+	checkError(t, "unknown flag", fmt.Errorf(`command defaults of "testing": unknown flag "missing"`), err)
+}
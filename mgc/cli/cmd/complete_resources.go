@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/cli/cmd/schema_flags"
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
+	"github.com/invopop/yaml"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+const completionCacheFilename = "completion-cache.yaml"
+
+// The cache is short-lived and of this machine, so it's never exported or cloned with the workspace
+func init() {
+	profile_manager.RegisterLocalFile(completionCacheFilename)
+}
+
+// Variables so tests may replace them
+var (
+	completionCacheTTL = 2 * time.Minute
+	completionTimeout  = 5 * time.Second
+	timeNow            = time.Now
+)
+
+type resourceChoice struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type completionCacheEntry struct {
+	Time    time.Time         `json:"time"`
+	Choices []*resourceChoice `json:"choices"`
+}
+
+// Entries are keyed by the list command and its parameters and configs, as they change the listed resources
+type completionCache map[string]*completionCacheEntry
+
+// Completes ID-like flags and positional arguments with the resources returned by a sibling
+// 'list' executor, the same matched by the select helper, with descriptions from their names
+type resourceCompleter struct {
+	sdk       *mgcSdk.Sdk
+	parentCmd *cobra.Command
+}
+
+func newResourceCompleter(sdk *mgcSdk.Sdk, parentCmd *cobra.Command) *resourceCompleter {
+	return &resourceCompleter{sdk: sdk, parentCmd: parentCmd}
+}
+
+func isIdLikeName(name string) bool {
+	name = strings.ToLower(name)
+	return name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "-id")
+}
+
+func (c *resourceCompleter) complete(cf *cmdFlags, f *flag.Flag, toComplete string, completions []string) []string {
+	fv, ok := f.Value.(schema_flags.SchemaFlagValue)
+	if !ok || fv.Desc().IsConfig || !isIdLikeName(fv.Desc().PropName) {
+		return completions
+	}
+
+	choices, err := c.choices(cf, fv.Desc())
+	if err != nil {
+		logger().Debugw("unable to complete resources", "flag", f.Name, "error", err)
+		return completions
+	}
+
+	var prefixMatches, containsMatches []string
+	for _, choice := range choices {
+		s := choice.Value
+		if choice.Description != "" {
+			s += "\t" + choice.Description
+		}
+		if strings.HasPrefix(choice.Value, toComplete) {
+			prefixMatches = append(prefixMatches, s)
+		} else if strings.Contains(choice.Value, toComplete) {
+			containsMatches = append(containsMatches, s)
+		}
+	}
+
+	if len(prefixMatches) > 0 {
+		return append(completions, prefixMatches...)
+	}
+	return append(completions, containsMatches...)
+}
+
+func (c *resourceCompleter) group() (core.Grouper, error) {
+	var names []string
+	for cmd := c.parentCmd; cmd.HasParent(); cmd = cmd.Parent() {
+		names = append([]string{cmd.Name()}, names...)
+	}
+
+	grouper := c.sdk.Group()
+	for _, name := range names {
+		child, err := findChildByNameOrAliases(grouper, name)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if grouper, ok = child.(core.Grouper); !ok {
+			return nil, fmt.Errorf("command %q is not a group", name)
+		}
+	}
+	return grouper, nil
+}
+
+// Besides the results accepted by findListSchema(), objects with a single array property
+// are also accepted, as paginated results often have another one with the page metadata
+func findResourceListSchema(schema *mgcSchemaPkg.Schema) (*mgcSchemaPkg.Schema, error) {
+	if listSchema, err := findListSchema(schema); err == nil {
+		return listSchema, nil
+	}
+
+	var listSchema *mgcSchemaPkg.Schema
+	for _, propRef := range schema.Properties {
+		if propRef.Value == nil || propRef.Value.Items == nil {
+			continue
+		}
+		if listSchema != nil {
+			return nil, fmt.Errorf("multiple arrays in list result schema")
+		}
+		listSchema = (*mgcSchemaPkg.Schema)(propRef.Value.Items.Value)
+	}
+	if listSchema == nil {
+		return nil, fmt.Errorf("unable to find resource schema from list result schema")
+	}
+	return listSchema, nil
+}
+
+func findListForParameter(grouper core.Grouper, paramName string, paramSchema *mgcSchemaPkg.Schema) (listExec core.Executor, listSchema *mgcSchemaPkg.Schema) {
+	_, _ = grouper.VisitChildren(func(child core.Descriptor) (bool, error) {
+		exec, ok := child.(core.Executor)
+		if !ok || !strings.HasPrefix(exec.Name(), listExecNamePrefix) {
+			return true, nil
+		}
+
+		schema, err := findResourceListSchema(exec.ResultSchema())
+		if err != nil || !listItemMatchesParameter(paramName, paramSchema, schema) {
+			return true, nil
+		}
+
+		listExec, listSchema = exec, schema
+		return false, nil
+	})
+	return
+}
+
+func (c *resourceCompleter) choices(cf *cmdFlags, desc schema_flags.SchemaFlagValueDesc) (choices []*resourceChoice, err error) {
+	grouper, err := c.group()
+	if err != nil {
+		return
+	}
+
+	listExec, listSchema := findListForParameter(grouper, desc.PropName, (*mgcSchemaPkg.Schema)(desc.Schema))
+	if listExec == nil {
+		err = fmt.Errorf("no list command for %q", desc.PropName)
+		return
+	}
+
+	parameters, configs, err := c.listValues(cf, listExec)
+	if err != nil {
+		return
+	}
+
+	key, err := completionCacheKey(c.parentCmd, listExec, parameters, configs)
+	if err != nil {
+		return
+	}
+
+	profile := c.sdk.ProfileManager().Current()
+	cache := readCompletionCache(profile)
+	if entry, ok := cache[key]; ok && timeNow().Sub(entry.Time) < completionCacheTTL {
+		logger().Debugw("using cached completions", "key", key)
+		return entry.Choices, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.sdk.NewContext(), completionTimeout)
+	defer cancel()
+
+	result, err := listExec.Execute(ctx, parameters, configs)
+	if err != nil {
+		return
+	}
+	resultWithValue, ok := core.ResultAs[core.ResultWithValue](result)
+	if !ok {
+		err = fmt.Errorf("list returned no value")
+		return
+	}
+
+	var humanFields []string
+	if humanResultExec, ok := core.ExecutorAs[core.HumanIdentifiableFieldsExecutor](listExec); ok {
+		humanFields = humanResultExec.HumanIdentifiableFields()
+	}
+
+	choices, err = resourceChoices(resultWithValue.Value(), desc.PropName, (*mgcSchemaPkg.Schema)(desc.Schema), listSchema, humanFields)
+	if err != nil {
+		return
+	}
+
+	cache[key] = &completionCacheEntry{Time: timeNow(), Choices: choices}
+	writeCompletionCache(profile, cache)
+	return
+}
+
+// The list parameters are taken from the flags with the same name, as the parent resource ID.
+// Configs not given use the current ones, as the CLI does
+func (c *resourceCompleter) listValues(cf *cmdFlags, listExec core.Executor) (parameters core.Parameters, configs core.Configs, err error) {
+	config := c.sdk.Config()
+	paramFlags := map[string]*flag.Flag{}
+	configFlags := map[string]*flag.Flag{}
+	for _, f := range cf.schemaFlags {
+		desc := f.Value.(schema_flags.SchemaFlagValue).Desc()
+		if desc.IsConfig {
+			configFlags[desc.PropName] = f
+		} else {
+			paramFlags[desc.PropName] = f
+		}
+	}
+
+	parameters = core.Parameters{}
+	paramsSchema := listExec.ParametersSchema()
+	for _, name := range paramsSchema.Required {
+		if f, ok := paramFlags[name]; ok && f.Changed {
+			if parameters[name], err = schema_flags.GetFlagValue(f, config); err != nil {
+				return
+			}
+			continue
+		}
+		if propRef := paramsSchema.Properties[name]; propRef != nil && propRef.Value != nil && propRef.Value.Default != nil {
+			parameters[name] = propRef.Value.Default
+			continue
+		}
+		err = fmt.Errorf("missing required parameter %q", name)
+		return
+	}
+
+	configs = core.Configs{}
+	configsSchema := listExec.ConfigsSchema()
+	for name, propRef := range configsSchema.Properties {
+		if f, ok := configFlags[name]; ok {
+			if value, err := schema_flags.GetFlagValue(f, config); err == nil {
+				configs[name] = value
+			}
+			continue
+		}
+
+		var value any
+		if err := config.Get(name, &value); err == nil && value != nil {
+			configs[name] = value
+			continue
+		}
+		if propRef.Value != nil && propRef.Value.Default != nil &&
+			(slices.Contains(configsSchema.Required, name) || name == "env" || name == "region") {
+			configs[name] = propRef.Value.Default
+		}
+	}
+	return
+}
+
+func completionCacheKey(parentCmd *cobra.Command, listExec core.Executor, parameters core.Parameters, configs core.Configs) (string, error) {
+	data, err := json.Marshal(map[string]any{"parameters": parameters, "configs": configs})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", parentCmd.CommandPath(), listExec.Name(), data), nil
+}
+
+// List results are either the items array or an object with a single array property, see findResourceListSchema()
+func listResultItems(value any) ([]any, error) {
+	switch v := value.(type) {
+	case []any:
+		return v, nil
+	case map[string]any:
+		var items []any
+		found := false
+		for _, prop := range v {
+			if array, ok := prop.([]any); ok {
+				if found {
+					return nil, fmt.Errorf("list returned multiple arrays")
+				}
+				items, found = array, true
+			}
+		}
+		if found {
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("list expected to return array, got %T instead", value)
+}
+
+func resourceChoices(value any, paramName string, paramSchema, listSchema *mgcSchemaPkg.Schema, humanFields []string) ([]*resourceChoice, error) {
+	items, err := listResultItems(value)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptionFields := slices.DeleteFunc(slices.Clone(humanFields), func(field string) bool {
+		return field == paramName
+	})
+
+	choices := make([]*resourceChoice, 0, len(items))
+	for _, item := range items {
+		v, ok := getChoiceValue(item, paramName, paramSchema, listSchema)
+		if !ok || v == nil {
+			continue
+		}
+		choice := &resourceChoice{Value: fmt.Sprint(v)}
+		if m, ok := item.(map[string]any); ok {
+			if len(descriptionFields) > 0 {
+				choice.Description = getSelectLabel(m, descriptionFields)
+			} else if name, ok := m["name"]; ok && name != nil {
+				choice.Description = fmt.Sprint(name)
+			}
+		}
+		choices = append(choices, choice)
+	}
+	return choices, nil
+}
+
+func readCompletionCache(p *profile_manager.Profile) completionCache {
+	cache := completionCache{}
+	data, err := p.Read(completionCacheFilename)
+	if err != nil {
+		return cache
+	}
+
+	if err = yaml.Unmarshal(data, &cache); err != nil {
+		logger().Debugw("ignored bad format completion cache", "error", err)
+		return completionCache{}
+	}
+	return cache
+}
+
+// Expired entries are dropped, so the cache doesn't grow with every listed resource
+func writeCompletionCache(p *profile_manager.Profile, cache completionCache) {
+	now := timeNow()
+	for key, entry := range cache {
+		if now.Sub(entry.Time) >= completionCacheTTL {
+			delete(cache, key)
+		}
+	}
+
+	data, err := yaml.Marshal(cache)
+	if err == nil {
+		err = p.Write(completionCacheFilename, data)
+	}
+	if err != nil {
+		logger().Debugw("unable to write completion cache", "error", err)
+	}
+}
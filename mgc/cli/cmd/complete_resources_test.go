@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+func newCompleteTestSchemas() (resultSchema, idSchema *mgcSchemaPkg.Schema) {
+	instanceSchema := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"id":    mgcSchemaPkg.NewStringSchema(),
+		"name":  mgcSchemaPkg.NewStringSchema(),
+		"state": mgcSchemaPkg.NewStringSchema(),
+	}, []string{"id"})
+	metaSchema := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"total": mgcSchemaPkg.NewIntegerSchema(),
+	}, nil)
+	resultSchema = mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"instances": mgcSchemaPkg.NewArraySchema(instanceSchema),
+		"meta":      metaSchema,
+	}, nil)
+	return resultSchema, mgcSchemaPkg.NewStringSchema()
+}
+
+func Test_findResourceListSchema(t *testing.T) {
+	resultSchema, idSchema := newCompleteTestSchemas()
+
+	listSchema, err := findResourceListSchema(resultSchema)
+	checkError(t, "findResourceListSchema", nil, err)
+
+	if !listItemMatchesParameter("id", idSchema, listSchema) {
+		t.Errorf("expected list items to match parameter 'id'")
+	}
+	if listItemMatchesParameter("other_id", idSchema, listSchema) {
+		t.Errorf("expected list items to not match parameter 'other_id'")
+	}
+
+	resultSchema.Properties["other"] = mgcSchemaPkg.NewSchemaRef("", mgcSchemaPkg.NewArraySchema(idSchema))
+	if _, err = findResourceListSchema(resultSchema); err == nil {
+		t.Errorf("expected error with multiple arrays")
+	}
+}
+
+func Test_resourceChoices(t *testing.T) {
+	resultSchema, idSchema := newCompleteTestSchemas()
+	listSchema, err := findResourceListSchema(resultSchema)
+	checkError(t, "findResourceListSchema", nil, err)
+
+	value := map[string]any{
+		"instances": []any{
+			map[string]any{"id": "a1", "name": "vm-a", "state": "running"},
+			map[string]any{"id": "b2", "name": "vm-b", "state": "stopped"},
+			map[string]any{"name": "no-id"},
+		},
+		"meta": map[string]any{"total": 3},
+	}
+
+	choices, err := resourceChoices(value, "id", idSchema, listSchema, nil)
+	checkError(t, "resourceChoices", nil, err)
+	expected := []*resourceChoice{{"a1", "vm-a"}, {"b2", "vm-b"}}
+	if !reflect.DeepEqual(expected, choices) {
+		t.Errorf("expected %v, got %v", expected, choices)
+	}
+
+	choices, err = resourceChoices(value, "id", idSchema, listSchema, []string{"id", "name", "state"})
+	checkError(t, "resourceChoices human fields", nil, err)
+	expected = []*resourceChoice{{"a1", `name: "vm-a" | state: "running"`}, {"b2", `name: "vm-b" | state: "stopped"`}}
+	if !reflect.DeepEqual(expected, choices) {
+		t.Errorf("expected %v, got %v", expected, choices)
+	}
+
+	_, err = resourceChoices(map[string]any{"a": []any{}, "b": []any{}}, "id", idSchema, listSchema, nil)
+	if err == nil {
+		t.Errorf("expected error with multiple arrays")
+	}
+}
+
+func Test_completionCache(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	p := m.Current()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	origTimeNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = origTimeNow }()
+
+	cache := completionCache{
+		"fresh":   {Time: now.Add(-time.Second), Choices: []*resourceChoice{{Value: "a1", Description: "vm-a"}}},
+		"expired": {Time: now.Add(-completionCacheTTL), Choices: []*resourceChoice{{Value: "b2"}}},
+	}
+	writeCompletionCache(p, cache)
+
+	archive, err := m.Export(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := archive.Files[completionCacheFilename]; ok {
+		t.Errorf("the completion cache must not be exported with the workspace")
+	}
+
+	got := readCompletionCache(p)
+	if _, ok := got["expired"]; ok {
+		t.Errorf("expected expired entry to be removed")
+	}
+	entry, ok := got["fresh"]
+	if !ok {
+		t.Fatalf("expected fresh entry to be kept")
+	}
+	if !entry.Time.Equal(cache["fresh"].Time) || !reflect.DeepEqual(entry.Choices, cache["fresh"].Choices) {
+		t.Errorf("expected %v, got %v", cache["fresh"], entry)
+	}
+}
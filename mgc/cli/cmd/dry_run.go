@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
+	"github.com/spf13/cobra"
+)
+
+const dryRunFlag = "cli.dry-run"
+
+func addDryRunFlag(cmd *cobra.Command) {
+	cmd.Root().PersistentFlags().String(
+		dryRunFlag,
+		"",
+		`Don't execute the command, only show what it would do: the HTTP request that would be sent or, for object storage
+transfers, the planned uploads, downloads, copies and deletions. Use --cli.dry-run=curl to show requests as curl commands`,
+	)
+	cmd.Root().PersistentFlags().Lookup(dryRunFlag).NoOptDefVal = string(core.DryRunFormatPlan)
+}
+
+func getDryRunFlag(cmd *cobra.Command) string {
+	format, err := cmd.Root().PersistentFlags().GetString(dryRunFlag)
+	if err != nil {
+		return ""
+	}
+	return format
+}
+
+func dryRunCurlLines(actions []core.DryRunAction) []string {
+	lines := make([]string, 0, len(actions))
+	for _, action := range actions {
+		switch {
+		case action.Request != nil:
+			lines = append(lines, action.Request.Curl)
+		case action.Source != "":
+			lines = append(lines, fmt.Sprintf("# %s %s -> %s", action.Action, action.Source, action.Destination))
+		default:
+			lines = append(lines, fmt.Sprintf("# %s %s", action.Action, action.Destination))
+		}
+	}
+	return lines
+}
+
+func formatDryRunPlan(sdk *mgcSdk.Sdk, cmd *cobra.Command, source core.ResultSource, plan *core.DryRunPlan) error {
+	actions := plan.Actions()
+	if plan.Format == core.DryRunFormatCurl {
+		for _, line := range dryRunCurlLines(actions) {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	// The reflected schema loses the nested request, so don't validate the actions' fields
+	schema := mgcSchemaPkg.NewArraySchema(mgcSchemaPkg.NewAnySchema())
+	value, err := utils.SimplifyAny(actions)
+	if err != nil {
+		return err
+	}
+	return formatResult(sdk, cmd, core.NewSimpleResult(source, schema, value))
+}
+
+// Confirmations, wait termination and retries are skipped, as nothing is actually executed
+func handleDryRun(
+	ctx context.Context,
+	sdk *mgcSdk.Sdk,
+	cmd *cobra.Command,
+	exec core.Executor,
+	parameters core.Parameters,
+	configs core.Configs,
+	format string,
+) error {
+	if !slices.Contains(core.DryRunFormats, core.DryRunFormat(format)) {
+		return core.UsageError{Err: fmt.Errorf("--%s must be one of %v, got %q", dryRunFlag, core.DryRunFormats, format)}
+	}
+
+	if _, ok := core.ExecutorAs[core.DryRunExecutor](exec); !ok {
+		return core.UsageError{Err: fmt.Errorf("%q does not support --%s", cmd.CommandPath(), dryRunFlag)}
+	}
+
+	if err := exec.ParametersSchema().VisitJSON(parameters); err != nil {
+		return core.UsageError{Err: err}
+	}
+
+	if err := exec.ConfigsSchema().VisitJSON(configs); err != nil {
+		return core.UsageError{Err: err}
+	}
+
+	plan := core.NewDryRunPlan(core.DryRunFormat(format))
+	_, err := exec.Execute(core.NewDryRunContext(ctx, plan), parameters, configs)
+	if pb != nil {
+		pb.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	source := core.ResultSource{Executor: exec, Context: ctx, Parameters: parameters, Configs: configs}
+	return formatDryRunPlan(sdk, cmd, source, plan)
+}
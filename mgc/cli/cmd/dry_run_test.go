@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+)
+
+func Test_dryRunCurlLines(t *testing.T) {
+	actions := []core.DryRunAction{
+		{Action: "request", Request: &core.DryRunRequest{Curl: "curl -X 'GET' 'https://api/v0/vms'"}},
+		{Action: "upload", Source: "a.txt", Destination: "s3://bucket/a.txt"},
+		{Action: "delete", Destination: "s3://bucket/b.txt"},
+	}
+	expected := []string{
+		"curl -X 'GET' 'https://api/v0/vms'",
+		"# upload a.txt -> s3://bucket/a.txt",
+		"# delete s3://bucket/b.txt",
+	}
+
+	lines := dryRunCurlLines(actions)
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d: %v", len(expected), len(lines), lines)
+	}
+	for i := range expected {
+		checkExpectedString(t, "line", expected[i], lines[i])
+	}
+}
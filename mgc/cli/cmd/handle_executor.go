@@ -11,6 +11,7 @@ import (
 	"github.com/MagaluCloud/magalu/mgc/core"
 	"github.com/MagaluCloud/magalu/mgc/core/auth"
 	"github.com/MagaluCloud/magalu/mgc/core/progress_report"
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
 	"github.com/MagaluCloud/magalu/mgc/sdk/openapi"
 	"github.com/spf13/cobra"
@@ -150,18 +151,38 @@ func handleExecutor(
 	exec core.Executor,
 	parameters core.Parameters,
 	configs core.Configs,
-) (core.Result, error) {
+) (result core.Result, err error) {
 	ctx = openapi.WithRawOutputFlag(ctx, getRawOutputFlag(cmd))
+	ctx = openapi.WithAllPagesFlag(ctx, getAllPagesFlag(cmd))
 
-	err := cmd.ParseFlags(argParser.MainArgs())
+	err = cmd.ParseFlags(argParser.MainArgs())
 	if err != nil {
 		return nil, err
 	}
 
-	if err = initLogger(sdk, getLogFilterFlag(cmd)); err != nil {
+	logFormat, err := getLogFormatFlag(cmd)
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+	if err = initLogger(sdk, getLogFilterFlag(cmd), logFormat); err != nil {
 		return nil, err
 	}
 
+	ctx, span, err := startCommandTrace(ctx, sdk, cmd)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { tracing.End(span, err) }()
+
+	// Planning has no side effects: the version isn't checked and neither the region nor the API key are persisted
+	if format := getDryRunFlag(cmd); format != "" {
+		if key := getApiKey(cmd); key != "" {
+			sdk.Auth().SetTempAPIKey(key)
+		}
+		setKeyPair(sdk)
+		return nil, handleDryRun(ctx, sdk, cmd, exec, parameters, configs, format)
+	}
+
 	if !getRawOutputFlag(cmd) {
 		core.NewVersionChecker(
 			sdk.HttpClient().Get,
@@ -181,7 +202,7 @@ func handleExecutor(
 	setApiKey(cmd, sdk)
 	setKeyPair(sdk)
 
-	result, err := handleExecutorPre(ctx, sdk, cmd, exec, parameters, configs)
+	result, err = handleExecutorPre(ctx, sdk, cmd, exec, parameters, configs)
 	err = handleExecutorResult(ctx, sdk, cmd, result, err)
 	if err != nil {
 		return nil, err
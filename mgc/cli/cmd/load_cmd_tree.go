@@ -272,6 +272,8 @@ func addAction(
 		return
 	}
 
+	flags.resources = newResourceCompleter(sdk, parentCmd)
+
 	name, aliases := getCommandNameAndAliases(exec.Name())
 	cmdPath := fmt.Sprintf("%s %s", parentCmd.CommandPath(), name)
 
@@ -292,6 +294,10 @@ func addAction(
 		Annotations:       make(map[string]string),
 
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadCommandDefaults(sdk, cmd, flags); err != nil {
+				return err
+			}
+
 			// First chained args structure is MainArgs
 			linkChainedArgs := argParser.ChainedArgs()[1:]
 			if getWatchFlag(cmd) {
@@ -314,6 +320,11 @@ func addAction(
 				return err
 			}
 
+			// Linked commands would need the actual result
+			if getDryRunFlag(cmd) != "" {
+				return nil
+			}
+
 			return links.handle(result, getOutputFlag(cmd))
 		},
 	}
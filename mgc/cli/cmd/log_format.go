@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	logFormatFlag    = "log-format"
+	logFormatConsole = "console"
+	logFormatJSON    = "json"
+)
+
+var logFormats = []string{logFormatConsole, logFormatJSON}
+
+func addLogFormatFlag(cmd *cobra.Command) {
+	cmd.Root().PersistentFlags().String(
+		logFormatFlag,
+		logFormatConsole,
+		fmt.Sprintf(`Format of the log events, one of %v. With json, each event is a line tagged with the correlation ID
+of the invocation, which is also sent as the X-Request-Id header of every HTTP request`, logFormats),
+	)
+}
+
+func getLogFormatFlag(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Root().PersistentFlags().GetString(logFormatFlag)
+	if err != nil || format == "" {
+		return logFormatConsole, nil
+	}
+	if !slices.Contains(logFormats, format) {
+		return "", fmt.Errorf("invalid --%s %q, expected one of %v", logFormatFlag, format, logFormats)
+	}
+	return format, nil
+}
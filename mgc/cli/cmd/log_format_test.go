@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"testing"
+
+	mgcLoggerPkg "github.com/MagaluCloud/magalu/mgc/core/logger"
+	"github.com/spf13/cobra"
+)
+
+func Test_getLogFormatFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+		fail     bool
+	}{
+		{args: []string{}, expected: logFormatConsole},
+		{args: []string{"--log-format", "json"}, expected: logFormatJSON},
+		{args: []string{"--log-format", "xml"}, fail: true},
+	} {
+		cmd := &cobra.Command{Use: "mgc"}
+		addLogFormatFlag(cmd)
+		if err := cmd.ParseFlags(tc.args); err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+
+		format, err := getLogFormatFlag(cmd)
+		if tc.fail {
+			if err == nil {
+				t.Errorf("%v: expected error, got format %q", tc.args, format)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.args, err)
+		}
+		checkExpectedString(t, "format", tc.expected, format)
+	}
+}
+
+func Test_setJSONLogConfig(t *testing.T) {
+	zapConfig := newLogConfig()
+	setJSONLogConfig(&zapConfig)
+
+	checkExpectedString(t, "encoding", "json", zapConfig.Encoding)
+	if id := zapConfig.InitialFields["correlationId"]; id != mgcLoggerPkg.CorrelationID() {
+		t.Errorf("expected correlation ID %q, got %v", mgcLoggerPkg.CorrelationID(), id)
+	}
+	if mgcLoggerPkg.CorrelationID() != mgcLoggerPkg.CorrelationID() {
+		t.Errorf("correlation ID must be the same for the whole invocation")
+	}
+}
@@ -23,13 +23,29 @@ func newLogConfig() zap.Config {
 	return zapConfig
 }
 
-func initLogger(sdk *mgcSdk.Sdk, filterRules string) error {
+// One JSON event per line, with the fields expected by log pipelines
+func setJSONLogConfig(zapConfig *zap.Config) {
+	zapConfig.Encoding = logFormatJSON
+	zapConfig.EncoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+	zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	zapConfig.EncoderConfig.TimeKey = "time"
+	zapConfig.EncoderConfig.LevelKey = "level"
+	zapConfig.EncoderConfig.NameKey = "logger"
+	zapConfig.EncoderConfig.MessageKey = "msg"
+	zapConfig.InitialFields = map[string]any{"correlationId": mgcLoggerPkg.CorrelationID()}
+}
+
+func initLogger(sdk *mgcSdk.Sdk, filterRules string, format string) error {
 	zapConfig := newLogConfig()
 
 	if err := sdk.Config().Get(loggerConfigKey, &zapConfig); err != nil {
 		return fmt.Errorf("unable to get logger configuration: %w", err)
 	}
 
+	if format == logFormatJSON {
+		setJSONLogConfig(&zapConfig)
+	}
+
 	logger, err := zapConfig.Build()
 	if err != nil {
 		return fmt.Errorf(
@@ -39,3 +39,10 @@ func (o *osArgParser) ChainedArgs() [][]string {
 	}
 	return o.chainedArgs
 }
+
+// Replaces the arguments, ie: after expanding aliases. Must be called before any other method
+func (o *osArgParser) setAllArgs(args []string) {
+	o.allArgs = args
+	o.mainArgs = nil
+	o.chainedArgs = nil
+}
@@ -86,10 +86,20 @@ func getOutputFor(sdk *mgcSdk.Sdk, cmd *cobra.Command, result core.Result) strin
 	}
 
 	if output == "" {
-		if outputOptions, ok := core.ResultAs[core.ResultWithDefaultOutputOptions](result); ok {
-			return outputOptions.DefaultOutputOptions()
-		}
+		output = getDefaultOutputOptions(cmd, result)
 	}
 
 	return output
 }
+
+// The default output options refer to paths of the value returned by the executor. As --query
+// reshapes the value, they're only used without it and the user must pass -o to use a formatter
+func getDefaultOutputOptions(cmd *cobra.Command, result core.Result) string {
+	if getQueryFlag(cmd) != "" {
+		return ""
+	}
+	if outputOptions, ok := core.ResultAs[core.ResultWithDefaultOutputOptions](result); ok {
+		return outputOptions.DefaultOutputOptions()
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+const noHeaderOption = "noheader"
+
+type csvOutputFormatter struct {
+	separator rune
+}
+
+// Columns with "[*]" in their JSONPath produce one row per element, the remaining of the path
+// is evaluated for each of them, so elements missing a field result in empty cells
+// instead of shifting the following rows
+type flatColumn struct {
+	header   string
+	rowsPath string
+	itemPath string
+	isList   bool
+}
+
+func newFlatColumn(c *column) *flatColumn {
+	header := strings.Join(append(append([]string{}, c.Parents...), c.Name), ".")
+	rowsPath, after, isList := strings.Cut(c.JSONPath, "[*]")
+	if !isList {
+		return &flatColumn{header: header, rowsPath: c.JSONPath}
+	}
+	return &flatColumn{header: header, rowsPath: rowsPath, itemPath: "$" + after, isList: true}
+}
+
+func (c *flatColumn) values(val any) ([]any, error) {
+	result, err := utils.GetJsonPath(c.rowsPath, val)
+	if err != nil {
+		return nil, err
+	}
+	if !c.isList {
+		return []any{result}, nil
+	}
+
+	items, ok := result.([]any)
+	if !ok {
+		return nil, nil
+	}
+
+	jp, err := utils.NewJsonPath(c.itemPath)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(items))
+	for i, item := range items {
+		// missing fields are left empty
+		values[i], _ = jp(context.Background(), item)
+	}
+	return values, nil
+}
+
+// Selects the columns given in the options, using the table syntax "NAME:jsonpath,...",
+// or infers them from the data layout, as the table formatter does
+func flatColumnsFromOptions(val any, options string) ([]*flatColumn, error) {
+	tableOptions, err := tableOptionsFromString(options, val)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*flatColumn, len(tableOptions.Columns))
+	for i, c := range tableOptions.Columns {
+		columns[i] = newFlatColumn(c)
+	}
+	return columns, nil
+}
+
+// Returns the rows as a matrix, each row with one value per column
+func flatRows(val any, columns []*flatColumn) ([][]any, error) {
+	var rows [][]any
+	for colIdx, col := range columns {
+		values, err := col.values(val)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", col.header, err)
+		}
+		for rowIdx, v := range values {
+			if rowIdx >= len(rows) {
+				rows = append(rows, make([]any, len(columns)))
+			}
+			rows[rowIdx][colIdx] = v
+		}
+	}
+	return rows, nil
+}
+
+func flatCellString(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		// Objects and arrays are flattened as compact JSON, numbers avoid the exponent notation
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+func (f *csvOutputFormatter) Format(val any, options string, isRaw bool) error {
+	options, noHeader := strings.CutPrefix(options, noHeaderOption)
+	options = strings.TrimPrefix(options, ",")
+
+	val, err := utils.SimplifyAny(val)
+	if err != nil {
+		return err
+	}
+
+	columns, err := flatColumnsFromOptions(val, options)
+	if err != nil {
+		return err
+	}
+	rows, err := flatRows(val, columns)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Comma = f.separator
+
+	if !noHeader {
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = c.header
+		}
+		if err = w.Write(headers); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			if record[i], err = flatCellString(value); err != nil {
+				return err
+			}
+		}
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func (f *csvOutputFormatter) Description() string {
+	name := "csv"
+	if f.separator == '\t' {
+		name = "tsv"
+	}
+	return fmt.Sprintf(`Format as %s, one row per list element.`+
+		` May be used as "%s=COLNAME1:jsonpath-expression1,COLNAME2:jsonpath-expression2",`+
+		` otherwise columns are automatically inferred from data layout, as in "table".`+
+		` Objects and arrays are written as JSON. Use "%s=noheader" or "%s=noheader,COLNAME1:jsonpath-expression1" to omit the header line.`,
+		strings.ToUpper(name), name, name, name)
+}
+
+func init() {
+	outputFormatters["csv"] = &csvOutputFormatter{separator: ','}
+	outputFormatters["tsv"] = &csvOutputFormatter{separator: '\t'}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatRows(t *testing.T) {
+	value := map[string]any{
+		"instances": []any{
+			map[string]any{"id": "a1", "name": "vm-a", "machine": map[string]any{"cpus": float64(2)}},
+			map[string]any{"id": "b2", "machine": map[string]any{"cpus": float64(4)}, "labels": []any{"x"}},
+		},
+	}
+
+	testCases := []struct {
+		name    string
+		options string
+		headers []string
+		rows    [][]string
+	}{
+		{
+			name:    "inferred",
+			headers: []string{"ID", "MACHINE.CPUS", "NAME"},
+			rows:    [][]string{{"a1", "2", "vm-a"}, {"b2", "4", ""}},
+		},
+		{
+			name:    "explicit",
+			options: `NAME:$.instances[*].name,LABELS:$.instances[*].labels,ID:$.instances[*].id`,
+			headers: []string{"NAME", "LABELS", "ID"},
+			rows:    [][]string{{"vm-a", "", "a1"}, {"", `["x"]`, "b2"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			columns, err := flatColumnsFromOptions(value, tc.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			headers := make([]string, len(columns))
+			for i, c := range columns {
+				headers[i] = c.header
+			}
+			if !reflect.DeepEqual(tc.headers, headers) {
+				t.Errorf("expected headers %v, got %v", tc.headers, headers)
+			}
+
+			rows, err := flatRows(value, columns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([][]string, len(rows))
+			for i, row := range rows {
+				got[i] = make([]string, len(row))
+				for j, v := range row {
+					if got[i][j], err = flatCellString(v); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+			}
+			if !reflect.DeepEqual(tc.rows, got) {
+				t.Errorf("expected rows %v, got %v", tc.rows, got)
+			}
+		})
+	}
+}
+
+func TestNdjsonItems(t *testing.T) {
+	list := []any{map[string]any{"id": "a1"}, map[string]any{"id": "b2"}}
+	if got := ndjsonItems(map[string]any{"instances": list}); !reflect.DeepEqual(list, got) {
+		t.Errorf("expected single key list to be promoted, got %v", got)
+	}
+
+	object := map[string]any{"instances": list, "meta": map[string]any{}}
+	if got := ndjsonItems(object); !reflect.DeepEqual([]any{object}, got) {
+		t.Errorf("expected object in a single line, got %v", got)
+	}
+}
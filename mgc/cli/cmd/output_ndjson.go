@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+type ndjsonOutputFormatter struct{}
+
+// Lists are written one element per line, any other value in a single line
+func ndjsonItems(val any) []any {
+	switch v := val.(type) {
+	case []any:
+		return v
+	case map[string]any:
+		// single key objects have their single child promoted, as in "table"
+		if len(v) == 1 {
+			for _, child := range v {
+				if items, ok := child.([]any); ok {
+					return items
+				}
+			}
+		}
+	}
+	return []any{val}
+}
+
+func (*ndjsonOutputFormatter) Format(val any, options string, isRaw bool) error {
+	val, err := utils.SimplifyAny(val)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+
+	if options == "" {
+		for _, item := range ndjsonItems(val) {
+			if err = enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	columns, err := flatColumnsFromOptions(val, options)
+	if err != nil {
+		return err
+	}
+	rows, err := flatRows(val, columns)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		object := make(map[string]any, len(columns))
+		for i, c := range columns {
+			object[c.header] = row[i]
+		}
+		if err = enc.Encode(object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*ndjsonOutputFormatter) Description() string {
+	return `Format as newline delimited JSON, one compact JSON per list element.` +
+		` May be used as "ndjson=COLNAME1:jsonpath-expression1,COLNAME2:jsonpath-expression2"` +
+		` to write objects with the selected columns only, as in "table".`
+}
+
+func init() {
+	outputFormatters["ndjson"] = &ndjsonOutputFormatter{}
+}
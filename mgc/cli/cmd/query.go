@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/spf13/cobra"
+)
+
+const queryFlag = "query"
+
+func addQueryFlag(cmd *cobra.Command) {
+	cmd.Root().PersistentFlags().String(
+		queryFlag,
+		"",
+		`JMESPath query applied to the result before it's formatted with the output format,
+such as "[?status=='running'].{id:id,name:name}". See https://jmespath.org`,
+	)
+}
+
+func getQueryFlag(cmd *cobra.Command) string {
+	query, err := cmd.Root().PersistentFlags().GetString(queryFlag)
+	if err != nil {
+		return ""
+	}
+	return query
+}
+
+func applyQuery(value any, query string) (any, error) {
+	if query == "" {
+		return value, nil
+	}
+
+	// Struct results (ie: static commands) become maps, so they may be queried
+	value, err := utils.SimplifyAny(value)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := utils.GetJmesPath(query, value)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", queryFlag, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/spf13/cobra"
+)
+
+func Test_getDefaultOutputOptions(t *testing.T) {
+	value := map[string]any{"instances": []any{
+		map[string]any{"id": "vm-1", "status": "running"},
+		map[string]any{"id": "vm-2", "status": "stopped"},
+	}}
+	defaultOptions := "table=ID:$.instances[*].id,STATUS:$.instances[*].status"
+	result := core.NewResultWithDefaultOutputOptions(core.NewSimpleResult(core.ResultSource{}, nil, value), defaultOptions)
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{}, expected: defaultOptions},
+		// The table paths don't exist in the queried value
+		{args: []string{"--query", "instances[?status=='running'].id"}, expected: ""},
+	} {
+		cmd := &cobra.Command{Use: "mgc"}
+		addQueryFlag(cmd)
+		if err := cmd.ParseFlags(tc.args); err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		checkExpectedString(t, "default output options", tc.expected, getDefaultOutputOptions(cmd, result))
+	}
+
+	queried, err := applyQuery(value, "instances[?status=='running'].id")
+	checkError(t, "applyQuery", nil, err)
+	if ids, ok := queried.([]any); !ok || len(ids) != 1 || ids[0] != "vm-1" {
+		t.Errorf("unexpected query result %v", queried)
+	}
+}
@@ -22,7 +22,8 @@ func addRetryUntilFlag(cmd *cobra.Command) {
 		"",
 		fmt.Sprintf(`Retry the action with the same parameters until the given condition is met. The flag parameters
 use the format: '%s', where 'retries' is a positive integer, 'interval' is
-a duration (ex: 2s) and 'condition' is a '%s' pair such as "jsonpath=expression"`,
+a duration (ex: 2s) and 'condition' is a '%s' pair such as "jsonpath=expression",
+"jmespath=expression" or "template=expression"`,
 			retryUntilFlagFormat,
 			retryUntilFlagConditionFormat,
 		),
@@ -71,11 +72,13 @@ func parseRetryUntilFlag(v string) (r *core.RetryUntil, err error) {
 	}
 	switch p[0] {
 	default:
-		err = fmt.Errorf("--%s unknown condition engine: %s, supported: jsonpath|template", retryUntilFlag, p[0])
+		err = fmt.Errorf("--%s unknown condition engine: %s, supported: jsonpath|jmespath|template", retryUntilFlag, p[0])
 		return
 
 	case "jsonpath":
 		cfg.JSONPathQuery = p[1]
+	case "jmespath":
+		cfg.JMESPathQuery = p[1]
 	case "template":
 		cfg.TemplateQuery = p[1]
 	}
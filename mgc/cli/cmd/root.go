@@ -6,13 +6,16 @@ import (
 	"regexp"
 	"runtime"
 
+	"github.com/MagaluCloud/magalu/mgc/cli/ui"
 	"github.com/MagaluCloud/magalu/mgc/cli/ui/progress_bar"
+	mgcAuthPkg "github.com/MagaluCloud/magalu/mgc/core/auth"
 	mgcLoggerPkg "github.com/MagaluCloud/magalu/mgc/core/logger"
 	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/stoewer/go-strcase"
+	"golang.org/x/term"
 )
 
 const (
@@ -33,6 +36,7 @@ func normalizeFlagName(f *pflag.FlagSet, name string) pflag.NormalizedName {
 func Execute(version string) (err error) {
 	sdk := &mgcSdk.Sdk{}
 	sdk.SetVersion(version)
+	setPassphrasePrompt()
 
 	vv := fmt.Sprintf("%s (%s/%s)",
 		version,
@@ -80,9 +84,15 @@ It allows you to interact with the Magalu Cloud to manage your resources.
 	addOutputFlag(rootCmd)
 	addLogFilterFlag(rootCmd, getLogFilterConfig(sdk))
 	addLogDebugFlag(rootCmd)
+	addLogFormatFlag(rootCmd)
 	addTimeoutFlag(rootCmd)
 	addWaitTerminationFlag(rootCmd)
 	addRetryUntilFlag(rootCmd)
+	addQueryFlag(rootCmd)
+	addAllPagesFlag(rootCmd)
+	addDryRunFlag(rootCmd)
+	addTraceFileFlag(rootCmd)
+	addNoDefaultsFlag(rootCmd)
 	addBypassConfirmationFlag(rootCmd)
 	addShowInternalFlag(rootCmd)
 	addShowHiddenFlag(rootCmd)
@@ -95,12 +105,19 @@ It allows you to interact with the Magalu Cloud to manage your resources.
 		return nil
 	}
 
-	if err = initLogger(sdk, getLogFilterFlag(rootCmd)); err != nil {
+	logFormat, err := getLogFormatFlag(rootCmd)
+	if err != nil {
+		return err
+	}
+	if err = initLogger(sdk, getLogFilterFlag(rootCmd), logFormat); err != nil {
 		return err
 	}
 
 	rootCmd.AddCommand(newDumpTreeCmd(sdk))
 
+	if err = expandUserAliases(sdk); err != nil {
+		return err
+	}
 	mainArgs := argParser.MainArgs()
 
 	loadErr := loadSdkCommandTree(sdk, rootCmd, mainArgs)
@@ -109,6 +126,7 @@ It allows you to interact with the Magalu Cloud to manage your resources.
 	}
 
 	defer func() {
+		flushTrace()
 		_ = mgcLoggerPkg.Root().Sync()
 	}()
 
@@ -122,6 +140,13 @@ It allows you to interact with the Magalu Cloud to manage your resources.
 	return err
 }
 
+// Credentials in encrypted storage need their passphrase, which can only be asked in a terminal
+func setPassphrasePrompt() {
+	if term.IsTerminal(0) {
+		mgcAuthPkg.PassphrasePrompt = ui.RunPasswordPrompt
+	}
+}
+
 func setKeyPair(sdk *mgcSdk.Sdk) {
 	objId := os.Getenv("MGC_OBJ_KEY_ID")
 	objKey := os.Getenv("MGC_OBJ_KEY_SECRET")
@@ -134,15 +159,16 @@ func setKeyPair(sdk *mgcSdk.Sdk) {
 	}
 }
 
-func setApiKey(rootCmd *cobra.Command, sdk *mgcSdk.Sdk) {
+func getApiKey(rootCmd *cobra.Command) string {
 	if key := getApiKeyFlag(rootCmd); key != "" {
-		_ = sdk.Auth().SetAPIKey(key)
-		return
+		return key
 	}
+	return os.Getenv(apiKeyEnvVar)
+}
 
-	if key := os.Getenv(apiKeyEnvVar); key != "" {
+func setApiKey(rootCmd *cobra.Command, sdk *mgcSdk.Sdk) {
+	if key := getApiKey(rootCmd); key != "" {
 		_ = sdk.Auth().SetAPIKey(key)
-		return
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"unicode"
@@ -13,6 +14,7 @@ import (
 	"github.com/MagaluCloud/magalu/mgc/core"
 	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/invopop/yaml"
 )
 
 const (
@@ -24,7 +26,7 @@ const (
 
 // handles special cases, in order:
 //  1. "": empty value is returned. No error.
-//  2. "@filename": load JSON from file.
+//  2. "@filename": load JSON (or YAML, if the extension is .yaml or .yml) from file.
 //     Returns error if file was not found or it's not a valid JSON for type "T".
 //  3. try to JSON parse as value type "T"
 func parseJSONFlagValue[T any](rawValue string) (value T, err error) {
@@ -369,6 +371,10 @@ func parseObjectPropertyValue(schema *core.Schema, propName string, s string) (p
 			return
 		}
 	}
+	// Maps don't declare their keys, only the schema of their values, ie: "key1=value1,key2=value2"
+	if additional := schema.AdditionalProperties.Schema; len(schema.Properties) == 0 && additional != nil && additional.Value != nil {
+		return parseObjectValue((*core.Schema)(additional.Value), s)
+	}
 	return nil, 0, fmt.Errorf("could not find property %q", propName)
 }
 
@@ -581,12 +587,20 @@ func parseObjectFlagValue(schema *core.Schema, rawValues []string) (items map[st
 	return
 }
 
+// Files with the .yaml or .yml extensions are parsed as YAML
 func loadJSONFromFile[T any](filename string) (value T, err error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return
 	}
 
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return
+		}
+	}
+
 	err = json.Unmarshal(data, &value)
 	return
 }
@@ -3,6 +3,8 @@ package schema_flags
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -652,3 +654,42 @@ func Test_help(t *testing.T) {
 		})
 	}
 }
+
+func Test_loadJSONFromFile(t *testing.T) {
+	dir := t.TempDir()
+	expected := map[string]any{"Rules": []any{map[string]any{"Status": "Enabled", "Expiration": map[string]any{"Days": float64(30)}}}}
+
+	for name, content := range map[string]string{
+		"config.json": `{"Rules": [{"Status": "Enabled", "Expiration": {"Days": 30}}]}`,
+		"config.yaml": "Rules:\n  - Status: Enabled\n    Expiration:\n      Days: 30\n",
+		"config.yml":  "Rules: [{Status: Enabled, Expiration: {Days: 30}}]\n",
+	} {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		value, err := parseJSONFlagValue[map[string]any](ValueLoadJSONFromFilePrefix + filename)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(expected, value) {
+			t.Errorf("%s: expected %#v, got %#v", name, expected, value)
+		}
+	}
+}
+
+func Test_parseObjectFlagValueMap(t *testing.T) {
+	schema := mgcSchemaPkg.NewObjectSchema(nil, nil)
+	schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: &openapi3.SchemaRef{Value: openapi3.NewStringSchema()}}
+
+	value, err := parseObjectFlagValue(schema, []string{"env=prod,team=web", "owner=me"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{"env": "prod", "team": "web", "owner": "me"}
+	if !reflect.DeepEqual(expected, value) {
+		t.Errorf("expected %#v, got %#v", expected, value)
+	}
+}
@@ -95,6 +95,23 @@ func findListSchema(schema *mgcSchemaPkg.Schema) (resourceSchema *mgcSchemaPkg.S
 	return
 }
 
+// Either the list items are the parameter values, or they have a field with the parameter name and schema
+func listItemMatchesParameter(paramName string, paramSchema, listSchema *mgcSchemaPkg.Schema) bool {
+	if mgcSchemaPkg.CheckSimilarJsonSchemas(paramSchema, listSchema) {
+		// list of actual items to be used
+		return true
+	}
+
+	if listSchema.Type != nil && !listSchema.Type.Includes("object") {
+		return false
+	}
+	fieldSchemaRef := listSchema.Properties[paramName]
+	if fieldSchemaRef == nil {
+		return false
+	}
+	return mgcSchemaPkg.CheckSimilarJsonSchemas(paramSchema, (*mgcSchemaPkg.Schema)(fieldSchemaRef.Value))
+}
+
 func matchListAndSetExecutor(setExec, listExec core.Executor) (matchingListExec core.Executor, multiple bool) {
 	listSchema, err := findListSchema(listExec.ResultSchema())
 	if err != nil {
@@ -111,19 +128,7 @@ func matchListAndSetExecutor(setExec, listExec core.Executor) (matchingListExec
 			paramSchema = (*mgcSchemaPkg.Schema)(paramSchema.Items.Value)
 		}
 
-		if mgcSchemaPkg.CheckSimilarJsonSchemas(paramSchema, listSchema) {
-			// list of actual items to be used
-			continue
-		}
-
-		if listSchema.Type != nil && !listSchema.Type.Includes("object") {
-			return
-		}
-		fieldSchemaRef := listSchema.Properties[paramName]
-		if fieldSchemaRef == nil {
-			return
-		}
-		if !mgcSchemaPkg.CheckSimilarJsonSchemas(paramSchema, (*mgcSchemaPkg.Schema)(fieldSchemaRef.Value)) {
+		if !listItemMatchesParameter(paramName, paramSchema, listSchema) {
 			return
 		}
 	}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	mgcLoggerPkg "github.com/MagaluCloud/magalu/mgc/core/logger"
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
+	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	traceFileFlag     = "cli.trace-file"
+	traceFlushTimeout = 5 * time.Second
+)
+
+var traceShutdown func(context.Context) error
+
+func addTraceFileFlag(cmd *cobra.Command) {
+	cmd.Root().PersistentFlags().String(
+		traceFileFlag,
+		"",
+		`Write OpenTelemetry spans of the command (operations, HTTP requests, token refreshes and transfers) as JSON to this file.
+Spans are also sent with OTLP if OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT are set`,
+	)
+}
+
+func getTraceFileFlag(cmd *cobra.Command) string {
+	path, err := cmd.Root().PersistentFlags().GetString(traceFileFlag)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// Enables the exporters and starts the span of the whole command, which must be ended by the caller
+func startCommandTrace(ctx context.Context, sdk *mgcSdk.Sdk, cmd *cobra.Command) (context.Context, trace.Span, error) {
+	// Links and selections execute other commands, which are traced as children of the first one
+	if traceShutdown == nil {
+		shutdown, err := tracing.Setup(ctx, getTraceFileFlag(cmd), sdk.GetVersion())
+		if err != nil {
+			return ctx, nil, err
+		}
+		traceShutdown = shutdown
+	}
+
+	ctx, span := tracing.Start(
+		ctx,
+		cmd.CommandPath(),
+		tracing.AttrExecutorName.String(cmd.Name()),
+		tracing.AttrCorrelationID.String(mgcLoggerPkg.CorrelationID()),
+	)
+	return ctx, span, nil
+}
+
+// Exports the pending spans, called before exiting
+func flushTrace() {
+	if traceShutdown == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+	defer cancel()
+	if err := traceShutdown(ctx); err != nil {
+		logger().Warnw("failed to export trace", "error", err)
+	}
+	traceShutdown = nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/alias"
+	"github.com/spf13/cobra"
+
+	mgcSdk "github.com/MagaluCloud/magalu/mgc/sdk"
+)
+
+// Aliases set with "mgc alias set" are expanded before the command tree is loaded, as if the user
+// typed the whole command. Completions also see the expanded command
+func expandUserAliases(sdk *mgcSdk.Sdk) error {
+	args := argParser.AllArgs()
+	if len(args) == 0 {
+		return nil
+	}
+
+	aliases, err := alias.Load(sdk.ProfileManager().Current())
+	if err != nil {
+		logger().Warnw("ignored aliases", "error", err)
+		return nil
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	if args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd {
+		expanded, err := alias.Expand(aliases, args[1:])
+		if err != nil {
+			// incomplete aliases are completed as given
+			return nil
+		}
+		argParser.setAllArgs(append([]string{args[0]}, expanded...))
+		return nil
+	}
+
+	expanded, err := alias.Expand(aliases, args)
+	if err != nil {
+		return err
+	}
+	if len(expanded) != len(args) || expanded[0] != args[0] {
+		logger().Debugw("expanded alias", "alias", args[0], "args", expanded)
+	}
+	argParser.setAllArgs(expanded)
+	return nil
+}
@@ -0,0 +1,11 @@
+package ui
+
+import (
+	"github.com/erikgeiser/promptkit/textinput"
+)
+
+func RunPasswordPrompt(message string) (string, error) {
+	input := textinput.New(message)
+	input.Hidden = true
+	return input.RunPrompt()
+}
@@ -4,17 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
 	"github.com/MagaluCloud/magalu/mgc/core/config"
 	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
 	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/invopop/yaml"
@@ -68,6 +71,8 @@ type ConfigResult struct {
 	AccessKeyId     string `json:"access_key_id"`
 	SecretAccessKey string `json:"secret_access_key"`
 	CurrentEnv      string `json:"current_environment"`
+	// Set for service accounts logged in with the client credentials grant
+	ClientCredentials *clientCredentials `json:"client_credentials,omitempty"`
 }
 
 type Config struct {
@@ -77,14 +82,16 @@ type Config struct {
 	RedirectUri           string
 	LoginUrl              string
 	TokenUrl              string
-	ValidationUrl         string
-	RefreshUrl            string
-	TenantsListUrl        string
-	TokenExchangeUrl      string
-	ApiKeysUrlV1          string
-	ApiKeysUrlV2          string
-	PublicClientsUrl      string
-	ClientsV2Url          string
+	// Optional, read from the auth server metadata if not set
+	DeviceAuthorizationUrl string
+	ValidationUrl          string
+	RefreshUrl             string
+	TenantsListUrl         string
+	TokenExchangeUrl       string
+	ApiKeysUrlV1           string
+	ApiKeysUrlV2           string
+	PublicClientsUrl       string
+	ClientsV2Url           string
 }
 
 type Authenticator interface {
@@ -131,6 +138,9 @@ type Auth struct {
 	apiKey                string
 	currentSecurityMethod string
 	xTenantID             string
+	clientCredentials     *clientCredentials
+	storages              map[string]SecretStorage
+	storagesMutex         sync.Mutex
 }
 
 type Tenant struct {
@@ -396,6 +406,7 @@ func (o *Auth) Logout() error {
 	o.apiKey = ""
 	o.accessToken = ""
 	o.refreshToken = ""
+	o.clientCredentials = nil
 	return o.writeCurrentConfig()
 }
 
@@ -420,6 +431,12 @@ func (o *Auth) SetAPIKey(apiKey string) error {
 	return o.writeCurrentConfig()
 }
 
+// SetTempAPIKey uses the API key in this execution only, the auth data isn't written
+func (o *Auth) SetTempAPIKey(apiKey string) {
+	o.currentSecurityMethod = APIKey.String()
+	o.apiKey = apiKey
+}
+
 func (o *Auth) SetXTenantID(tenantId string) error {
 	err := o.setCurrentSecurityMethod(XTenantID)
 	if err != nil {
@@ -446,6 +463,7 @@ func (o *Auth) writeCurrentConfig() error {
 	authResult.RefreshToken = o.refreshToken
 	authResult.AccessKeyId = o.accessKeyId
 	authResult.SecretAccessKey = o.secretAccessKey
+	authResult.ClientCredentials = o.clientCredentials
 	return o.writeConfigFile(authResult)
 }
 
@@ -456,6 +474,7 @@ func (o *Auth) InitTokensFromFile() {
 		o.refreshToken = authResult.RefreshToken
 		o.accessKeyId = authResult.AccessKeyId
 		o.secretAccessKey = authResult.SecretAccessKey
+		o.clientCredentials = authResult.ClientCredentials
 	}
 
 	if envVal := os.Getenv("MGC_ACCESS_TOKEN"); envVal != "" {
@@ -526,6 +545,7 @@ func (o *Auth) RequestAuthTokenWithAuthorizationCode(ctx context.Context, authCo
 		return err
 	}
 
+	o.clientCredentials = nil
 	if err = o.SetTokens(&result); err != nil {
 		return err
 	}
@@ -576,6 +596,11 @@ func (o *Auth) newValidateAccessTokenRequest(ctx context.Context) (*http.Request
 }
 
 func (o *Auth) RefreshAccessToken(ctx context.Context) (string, error) {
+	// Planned requests (--cli.dry-run) must have no side effects, renewing would send a request and persist the tokens
+	if core.DryRunPlanFromContext(ctx) != nil {
+		return "", fmt.Errorf("access token isn't renewed when planning requests")
+	}
+
 	_, err, _ := o.group.Do(refreshGroupKey, func() (any, error) {
 		return o.doRefreshAccessToken(ctx)
 	})
@@ -585,8 +610,23 @@ func (o *Auth) RefreshAccessToken(ctx context.Context) (string, error) {
 	return o.accessToken, nil
 }
 
-func (o *Auth) doRefreshAccessToken(ctx context.Context) (string, error) {
-	var err error
+func (o *Auth) doRefreshAccessToken(ctx context.Context) (token string, err error) {
+	ctx, span := tracing.Start(ctx, "refresh access token")
+	retries := 0
+	defer func() {
+		span.SetAttributes(tracing.AttrRetryCount.Int(retries))
+		tracing.End(span, err)
+	}()
+
+	if o.clientCredentials != nil {
+		credentials, err := o.clientCredentials.withSecret()
+		if err != nil {
+			return "", err
+		}
+		err = o.requestClientCredentialsToken(ctx, credentials)
+		return o.accessToken, err
+	}
+
 	var resp *http.Response
 
 	r, err := o.newRefreshAccessTokenRequest(ctx)
@@ -595,6 +635,7 @@ func (o *Auth) doRefreshAccessToken(ctx context.Context) (string, error) {
 	}
 
 	for i := 0; i < maxRetryCount; i++ {
+		retries = i
 		resp, err = o.httpClient.Do(r)
 		if err != nil {
 			wait := mgcHttpPkg.DefaultBackoff(minRetryWait, maxRetryCount, i, resp)
@@ -642,9 +683,16 @@ func (o *Auth) newRefreshAccessTokenRequest(ctx context.Context) (*http.Request,
 
 func (o *Auth) readConfigFile() (*ConfigResult, error) {
 	var result ConfigResult
-	authFile, err := o.profileManager.Current().Read(authFilename)
+	p := o.profileManager.Current()
+	storage, err := o.storageFor(p)
+	if err != nil {
+		logger().Warnw("unable to get auth storage", "error", err)
+		return nil, err
+	}
+
+	authFile, err := storage.Read(p)
 	if err != nil {
-		logger().Debugw("unable to read from auth configuration file", "error", err)
+		logger().Debugw("unable to read from auth configuration file", "storage", storage.Name(), "error", err)
 		return nil, err
 	}
 
@@ -658,13 +706,112 @@ func (o *Auth) readConfigFile() (*ConfigResult, error) {
 }
 
 func (o *Auth) writeConfigFile(result *ConfigResult) error {
+	p := o.profileManager.Current()
+	storage, err := o.storageFor(p)
+	if err != nil {
+		return err
+	}
+
+	// Unlike the tokens, the client secret doesn't expire, so it's only persisted by the storages
+	// protecting it. With the plain one, it's read from MGC_CLIENT_SECRET when the token is renewed
+	if storage.Name() == PlainStorage && result.ClientCredentials != nil {
+		credentials := *result.ClientCredentials
+		credentials.ClientSecret = ""
+		result.ClientCredentials = &credentials
+	}
+
 	yamlData, err := yaml.Marshal(result)
 	if err != nil {
 		logger().Warn("unable to persist auth data", "error", err)
 		return err
 	}
 
-	return o.profileManager.Current().Write(authFilename, yamlData)
+	return storage.Write(p, yamlData)
+}
+
+// Storage used by profiles without auth data yet, MGC_AUTH_STORAGE takes precedence over the config
+func (o *Auth) defaultStorageName() string {
+	if name := os.Getenv(storageEnvVar); name != "" {
+		return name
+	}
+
+	var name string
+	if o.mgcConfig != nil {
+		if err := o.mgcConfig.Get("authStorage", &name); err != nil {
+			logger().Debugw("unable to get 'authStorage' from config", "error", err)
+		}
+	}
+	if name == "" {
+		name = PlainStorage
+	}
+	return name
+}
+
+// Storages are kept, so the ones with state (ie: cached encryption keys) are reused
+func (o *Auth) getStorage(name string) (SecretStorage, error) {
+	o.storagesMutex.Lock()
+	defer o.storagesMutex.Unlock()
+
+	if storage, ok := o.storages[name]; ok {
+		return storage, nil
+	}
+
+	storage, err := newSecretStorage(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.storages == nil {
+		o.storages = map[string]SecretStorage{}
+	}
+	o.storages[name] = storage
+	return storage, nil
+}
+
+func (o *Auth) storageFor(p *profile_manager.Profile) (SecretStorage, error) {
+	return o.getStorage(detectStorageName(p, o.defaultStorageName()))
+}
+
+// StorageName returns the name of the storage holding the auth data of the profile
+func (o *Auth) StorageName(p *profile_manager.Profile) string {
+	return detectStorageName(p, o.defaultStorageName())
+}
+
+// MigrateStorage moves the auth data of the profile to the storage named 'to', removing it
+// from the previous one. Profiles without auth data have nothing to be moved
+func (o *Auth) MigrateStorage(p *profile_manager.Profile, to string) (from string, migrated bool, err error) {
+	target, err := o.getStorage(to)
+	if err != nil {
+		return "", false, err
+	}
+
+	from = detectStorageName(p, PlainStorage)
+	if from == target.Name() {
+		return from, false, nil
+	}
+
+	source, err := o.getStorage(from)
+	if err != nil {
+		return from, false, err
+	}
+
+	data, err := source.Read(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return from, false, nil
+	}
+	if err != nil {
+		return from, false, fmt.Errorf("unable to read credentials of workspace %q from %s storage: %w", p.Name, from, err)
+	}
+
+	if err = target.Write(p, data); err != nil {
+		return from, false, fmt.Errorf("unable to write credentials of workspace %q to %s storage: %w", p.Name, to, err)
+	}
+
+	if err = source.Delete(p); err != nil {
+		return from, false, fmt.Errorf("credentials of workspace %q were copied to %s storage, but not removed from %s storage: %w", p.Name, to, from, err)
+	}
+
+	return from, true, nil
 }
 
 func (o *Auth) ListTenants(ctx context.Context) ([]*Tenant, error) {
@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+)
+
+const (
+	deviceCodeGrantType        = "urn:ietf:params:oauth:grant-type:device_code"
+	clientCredentialsGrantType = "client_credentials"
+
+	deviceAuthorizationUrlEnvVar = "MGC_DEVICE_AUTHORIZATION_URL"
+	clientSecretEnvVar           = "MGC_CLIENT_SECRET"
+)
+
+// Paths of the auth server metadata (RFC 8414 and OpenID Connect Discovery), relative to the token URL host
+var authServerMetadataPaths = []string{"/.well-known/oauth-authorization-server", "/.well-known/openid-configuration"}
+
+// Variables so tests may replace them
+var (
+	defaultDevicePollInterval  = 5 * time.Second
+	devicePollIntervalSlowDown = 5 * time.Second
+)
+
+var (
+	ErrDeviceAccessDenied = errors.New("device authorization was denied by the user")
+	ErrDeviceCodeExpired  = errors.New("device code expired before the authorization was completed")
+)
+
+// DeviceAuthorization is the response of the device authorization endpoint (RFC 8628, section 3.2)
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// The URL the user should open. When available, the complete one already carries the user code
+func (d *DeviceAuthorization) URL() string {
+	if d.VerificationUriComplete != "" {
+		return d.VerificationUriComplete
+	}
+	return d.VerificationUri
+}
+
+type oauthErrorResult struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func (r oauthErrorResult) String() string {
+	if r.ErrorDescription != "" {
+		return fmt.Sprintf("%s: %s", r.Error, r.ErrorDescription)
+	}
+	return r.Error
+}
+
+func (o *Auth) postForm(ctx context.Context, targetUrl string, data url.Values) (*http.Response, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, targetUrl, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	return o.httpClient.Do(r)
+}
+
+func decodeOAuthError(resp *http.Response) oauthErrorResult {
+	var result oauthErrorResult
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &result); err != nil || result.Error == "" {
+		result.Error = fmt.Sprintf("bad response from auth server, status %d", resp.StatusCode)
+	}
+	return result
+}
+
+/** Returns the device authorization endpoint, in order: MGC_DEVICE_AUTHORIZATION_URL, the
+ * environment Config or the 'device_authorization_endpoint' advertised in the metadata of the
+ * auth server hosting the token URL. */
+func (o *Auth) deviceAuthorizationUrl(ctx context.Context) (string, error) {
+	if envVal := os.Getenv(deviceAuthorizationUrlEnvVar); envVal != "" {
+		return envVal, nil
+	}
+
+	config := o.GetConfig()
+	if config.DeviceAuthorizationUrl != "" {
+		return config.DeviceAuthorizationUrl, nil
+	}
+
+	tokenUrl, err := url.Parse(config.TokenUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid token URL: %w", err)
+	}
+
+	for _, path := range authServerMetadataPaths {
+		metadataUrl := url.URL{Scheme: tokenUrl.Scheme, Host: tokenUrl.Host, Path: path}
+		endpoint, err := o.fetchDeviceAuthorizationEndpoint(ctx, metadataUrl.String())
+		if err != nil {
+			logger().Debugw("unable to read auth server metadata", "url", metadataUrl.String(), "error", err)
+			continue
+		}
+		if endpoint != "" {
+			return endpoint, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"device authorization is not available in the current environment, the auth server doesn't advertise it. Set %s to its endpoint",
+		deviceAuthorizationUrlEnvVar,
+	)
+}
+
+func (o *Auth) fetchDeviceAuthorizationEndpoint(ctx context.Context, metadataUrl string) (string, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := o.httpClient.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bad response from auth server, status %d", resp.StatusCode)
+	}
+
+	var metadata struct {
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", err
+	}
+	return metadata.DeviceAuthorizationEndpoint, nil
+}
+
+/** Starts the device authorization flow (RFC 8628). The returned user code must be
+ * shown to the user, who completes the login in any browser, then `PollDeviceToken`
+ * waits for it to finish. */
+func (o *Auth) RequestDeviceAuthorization(ctx context.Context, scopes core.Scopes) (*DeviceAuthorization, error) {
+	deviceAuthorizationUrl, err := o.deviceAuthorizationUrl(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config := o.GetConfig()
+	data := url.Values{}
+	data.Set("client_id", config.ClientId)
+	data.Set("scope", string(scopes.AsScopesString()))
+
+	resp, err := o.postForm(ctx, deviceAuthorizationUrl, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not request device authorization: %s", decodeOAuthError(resp))
+	}
+
+	var result DeviceAuthorization
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.DeviceCode == "" || result.UserCode == "" {
+		return nil, fmt.Errorf("bad device authorization response, missing device or user code")
+	}
+
+	return &result, nil
+}
+
+/** Polls the token endpoint until the user completes the device authorization,
+ * storing the resulting tokens. It respects the interval requested by the server,
+ * including 'slow_down' responses, and stops when the device code expires. */
+func (o *Auth) PollDeviceToken(ctx context.Context, device *DeviceAuthorization) error {
+	config := o.GetConfig()
+	data := url.Values{}
+	data.Set("client_id", config.ClientId)
+	data.Set("grant_type", deviceCodeGrantType)
+	data.Set("device_code", device.DeviceCode)
+
+	interval := defaultDevicePollInterval
+	if device.Interval > 0 {
+		interval = time.Duration(device.Interval) * time.Second
+	}
+
+	if device.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, time.Duration(device.ExpiresIn)*time.Second, ErrDeviceCodeExpired)
+		defer cancel()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(interval):
+		}
+
+		resp, err := o.postForm(ctx, config.TokenUrl, data)
+		if err != nil {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			return err
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			var result LoginResult
+			err = json.NewDecoder(resp.Body).Decode(&result)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			o.clientCredentials = nil
+			return o.SetTokens(&result)
+		}
+
+		oauthErr := decodeOAuthError(resp)
+		resp.Body.Close()
+
+		switch oauthErr.Error {
+		case "authorization_pending":
+			logger().Debugw("device authorization pending", "interval", interval)
+		case "slow_down":
+			interval += devicePollIntervalSlowDown
+			logger().Debugw("device authorization asked to slow down", "interval", interval)
+		case "access_denied":
+			return ErrDeviceAccessDenied
+		case "expired_token":
+			return ErrDeviceCodeExpired
+		default:
+			return fmt.Errorf("could not request device token: %s", oauthErr)
+		}
+	}
+}
+
+// Service accounts get no refresh token, the grant is requested again once the access token expires
+type clientCredentials struct {
+	ClientId     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Scopes       string `json:"scopes,omitempty"`
+}
+
+// Credentials read from the plain storage lack the secret, see writeConfigFile
+func (c *clientCredentials) withSecret() (*clientCredentials, error) {
+	if c.ClientSecret != "" {
+		return c, nil
+	}
+
+	secret := os.Getenv(clientSecretEnvVar)
+	if secret == "" {
+		return nil, fmt.Errorf(
+			"the client secret isn't kept by the %s auth storage, set %s or login again. Use 'auth migrate-storage' to keep it encrypted",
+			PlainStorage, clientSecretEnvVar,
+		)
+	}
+
+	withSecret := *c
+	withSecret.ClientSecret = secret
+	return &withSecret, nil
+}
+
+/** Requests an access token for a service account using the OAuth client
+ * credentials grant, storing the resulting tokens and the credentials, so
+ * new tokens are requested with them when the access token expires. */
+func (o *Auth) RequestAuthTokenWithClientCredentials(ctx context.Context, clientId, clientSecret string, scopes core.Scopes) error {
+	if clientId == "" || clientSecret == "" {
+		return fmt.Errorf("client id and client secret are required for the client credentials login")
+	}
+
+	credentials := &clientCredentials{ClientId: clientId, ClientSecret: clientSecret}
+	if len(scopes) > 0 {
+		credentials.Scopes = string(scopes.AsScopesString())
+	}
+	return o.requestClientCredentialsToken(ctx, credentials)
+}
+
+func (o *Auth) requestClientCredentialsToken(ctx context.Context, credentials *clientCredentials) error {
+	config := o.GetConfig()
+	data := url.Values{}
+	data.Set("grant_type", clientCredentialsGrantType)
+	data.Set("client_id", credentials.ClientId)
+	data.Set("client_secret", credentials.ClientSecret)
+	if credentials.Scopes != "" {
+		data.Set("scope", credentials.Scopes)
+	}
+
+	logger().Infow("Will send request for client credentials", "clientId", credentials.ClientId)
+	resp, err := o.postForm(ctx, config.TokenUrl, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not request client credentials token: %s", decodeOAuthError(resp))
+	}
+
+	var result LoginResult
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	o.clientCredentials = credentials
+	return o.SetTokens(&result)
+}
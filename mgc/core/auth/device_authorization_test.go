@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+)
+
+type mockResponse struct {
+	statusCode int
+	body       string
+}
+
+// sequenceTransport replies with each response in order, recording the requests' form values
+type sequenceTransport struct {
+	responses []mockResponse
+	forms     []url.Values
+	urls      []string
+}
+
+func (o *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	form := url.Values{}
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		form, _ = url.ParseQuery(string(data))
+	}
+	o.forms = append(o.forms, form)
+	o.urls = append(o.urls, req.URL.String())
+
+	resp := o.responses[0]
+	if len(o.responses) > 1 {
+		o.responses = o.responses[1:]
+	}
+	return &http.Response{StatusCode: resp.statusCode, Body: io.NopCloser(bytes.NewBufferString(resp.body)), Request: req}, nil
+}
+
+func newDeviceTestAuth(t *testing.T, transport http.RoundTripper) *Auth {
+	return newDeviceTestAuthWithUrl(t, transport, "device-url")
+}
+
+func newDeviceTestAuthWithUrl(t *testing.T, transport http.RoundTripper, deviceAuthorizationUrl string) *Auth {
+	t.Setenv("MGC_ACCESS_TOKEN", "")
+	t.Setenv(storageEnvVar, "")
+
+	oldInterval, oldSlowDown := defaultDevicePollInterval, devicePollIntervalSlowDown
+	defaultDevicePollInterval, devicePollIntervalSlowDown = time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		defaultDevicePollInterval, devicePollIntervalSlowDown = oldInterval, oldSlowDown
+	})
+
+	configMap := map[string]Config{"default": dummyConfigMap["temp"]}
+	c := configMap["default"]
+	c.TokenUrl = "https://id.test/oauth/token"
+	c.DeviceAuthorizationUrl = deviceAuthorizationUrl
+	configMap["default"] = c
+
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	return New(configMap, &http.Client{Transport: transport}, m, config.New(m))
+}
+
+func TestDeviceAuthorizationLogin(t *testing.T) {
+	transport := &sequenceTransport{responses: []mockResponse{
+		{http.StatusOK, `{"device_code":"dev-code","user_code":"ABCD-EFGH","verification_uri":"https://id/device","expires_in":600}`},
+		{http.StatusBadRequest, `{"error":"authorization_pending"}`},
+		{http.StatusBadRequest, `{"error":"slow_down"}`},
+		{http.StatusOK, `{"access_token":"device-access","refresh_token":"device-refresh"}`},
+	}}
+	auth := newDeviceTestAuth(t, transport)
+
+	device, err := auth.RequestDeviceAuthorization(context.Background(), core.Scopes{"openid"})
+	if err != nil {
+		t.Fatalf("unable to request device authorization: %v", err)
+	}
+	if device.UserCode != "ABCD-EFGH" || device.URL() != "https://id/device" {
+		t.Errorf("unexpected device authorization %+v", device)
+	}
+	if scope := transport.forms[0].Get("scope"); scope != "openid" {
+		t.Errorf("expected scope 'openid', got %q", scope)
+	}
+
+	if err = auth.PollDeviceToken(context.Background(), device); err != nil {
+		t.Fatalf("unable to poll device token: %v", err)
+	}
+	if len(transport.forms) != 4 {
+		t.Errorf("expected 3 token requests, got %d", len(transport.forms)-1)
+	}
+	if grantType := transport.forms[1].Get("grant_type"); grantType != deviceCodeGrantType {
+		t.Errorf("expected device code grant type, got %q", grantType)
+	}
+	if auth.accessToken != "device-access" || auth.refreshToken != "device-refresh" {
+		t.Errorf("expected device tokens to be set, got %q %q", auth.accessToken, auth.refreshToken)
+	}
+}
+
+func TestPollDeviceTokenErrors(t *testing.T) {
+	testCases := []struct {
+		body     string
+		expected error
+	}{
+		{`{"error":"access_denied"}`, ErrDeviceAccessDenied},
+		{`{"error":"expired_token"}`, ErrDeviceCodeExpired},
+	}
+
+	for _, tc := range testCases {
+		auth := newDeviceTestAuth(t, &sequenceTransport{responses: []mockResponse{{http.StatusBadRequest, tc.body}}})
+		err := auth.PollDeviceToken(context.Background(), &DeviceAuthorization{DeviceCode: "dev-code"})
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.body, tc.expected, err)
+		}
+	}
+
+	// Pending forever, until the device code expires
+	auth := newDeviceTestAuth(t, &sequenceTransport{responses: []mockResponse{{http.StatusBadRequest, `{"error":"authorization_pending"}`}}})
+	err := auth.PollDeviceToken(context.Background(), &DeviceAuthorization{DeviceCode: "dev-code", ExpiresIn: 1})
+	if !errors.Is(err, ErrDeviceCodeExpired) {
+		t.Errorf("expected expired error, got %v", err)
+	}
+}
+
+func TestRequestAuthTokenWithClientCredentials(t *testing.T) {
+	transport := &sequenceTransport{responses: []mockResponse{
+		{http.StatusOK, `{"access_token":"service-access"}`},
+	}}
+	auth := newDeviceTestAuth(t, transport)
+
+	if err := auth.RequestAuthTokenWithClientCredentials(context.Background(), "", "secret", nil); err == nil {
+		t.Errorf("expected error without client id")
+	}
+
+	if err := auth.RequestAuthTokenWithClientCredentials(context.Background(), "sa-id", "sa-secret", nil); err != nil {
+		t.Fatalf("unable to login with client credentials: %v", err)
+	}
+	form := transport.forms[0]
+	if form.Get("grant_type") != "client_credentials" || form.Get("client_id") != "sa-id" || form.Get("client_secret") != "sa-secret" {
+		t.Errorf("unexpected client credentials request %v", form)
+	}
+	if auth.accessToken != "service-access" {
+		t.Errorf("expected service account token to be set, got %q", auth.accessToken)
+	}
+}
+
+func TestDeviceAuthorizationUrlDiscovery(t *testing.T) {
+	device := `{"device_code":"dev-code","user_code":"ABCD-EFGH","verification_uri":"https://id/device"}`
+	transport := &sequenceTransport{responses: []mockResponse{
+		{http.StatusNotFound, ``},
+		{http.StatusOK, `{"issuer":"https://id.test","device_authorization_endpoint":"https://id.test/oauth/device"}`},
+		{http.StatusOK, device},
+	}}
+	auth := newDeviceTestAuthWithUrl(t, transport, "")
+	t.Setenv(deviceAuthorizationUrlEnvVar, "")
+
+	if _, err := auth.RequestDeviceAuthorization(context.Background(), core.Scopes{"openid"}); err != nil {
+		t.Fatalf("unable to request device authorization: %v", err)
+	}
+	expected := []string{"https://id.test/.well-known/oauth-authorization-server", "https://id.test/.well-known/openid-configuration", "https://id.test/oauth/device"}
+	if !slices.Equal(transport.urls, expected) {
+		t.Errorf("expected requests to %v, got %v", expected, transport.urls)
+	}
+
+	transport = &sequenceTransport{responses: []mockResponse{{http.StatusOK, `{"issuer":"https://id.test"}`}}}
+	auth = newDeviceTestAuthWithUrl(t, transport, "")
+	if _, err := auth.RequestDeviceAuthorization(context.Background(), core.Scopes{"openid"}); err == nil || !strings.Contains(err.Error(), deviceAuthorizationUrlEnvVar) {
+		t.Errorf("expected error when the auth server doesn't advertise device authorization, got %v", err)
+	}
+
+	transport = &sequenceTransport{responses: []mockResponse{{http.StatusOK, device}}}
+	auth = newDeviceTestAuthWithUrl(t, transport, "")
+	t.Setenv(deviceAuthorizationUrlEnvVar, "https://other.test/device")
+	if _, err := auth.RequestDeviceAuthorization(context.Background(), core.Scopes{"openid"}); err != nil {
+		t.Fatalf("unable to request device authorization: %v", err)
+	}
+	if !slices.Equal(transport.urls, []string{"https://other.test/device"}) {
+		t.Errorf("expected %s to be used, got %v", deviceAuthorizationUrlEnvVar, transport.urls)
+	}
+}
+
+func TestClientCredentialsRenewal(t *testing.T) {
+	transport := &sequenceTransport{responses: []mockResponse{
+		{http.StatusOK, `{"access_token":"service-access"}`},
+		{http.StatusOK, `{"access_token":"service-access-2"}`},
+	}}
+	auth := newDeviceTestAuth(t, transport)
+
+	if err := auth.RequestAuthTokenWithClientCredentials(context.Background(), "sa-id", "sa-secret", nil); err != nil {
+		t.Fatalf("unable to login with client credentials: %v", err)
+	}
+
+	stored, err := auth.readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ClientCredentials == nil || stored.ClientCredentials.ClientId != "sa-id" {
+		t.Fatalf("expected client credentials to be stored, got %+v", stored.ClientCredentials)
+	}
+	if stored.ClientCredentials.ClientSecret != "" {
+		t.Errorf("expected the client secret to be left out of the plain storage")
+	}
+
+	// There's no refresh token, the grant is requested again
+	token, err := auth.RefreshAccessToken(context.Background())
+	if err != nil {
+		t.Fatalf("unable to renew client credentials token: %v", err)
+	}
+	if token != "service-access-2" {
+		t.Errorf("expected renewed token, got %q", token)
+	}
+	if form := transport.forms[1]; form.Get("grant_type") != "client_credentials" || form.Get("client_secret") != "sa-secret" {
+		t.Errorf("unexpected renewal request %v", form)
+	}
+
+	if err = auth.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = auth.RefreshAccessToken(context.Background()); err == nil {
+		t.Errorf("expected no renewal after logout")
+	}
+}
+
+func TestRefreshAccessTokenWhenPlanning(t *testing.T) {
+	transport := &sequenceTransport{responses: []mockResponse{
+		{http.StatusOK, `{"access_token":"service-access"}`},
+	}}
+	auth := newDeviceTestAuth(t, transport)
+
+	if err := auth.RequestAuthTokenWithClientCredentials(context.Background(), "sa-id", "sa-secret", nil); err != nil {
+		t.Fatalf("unable to login with client credentials: %v", err)
+	}
+	stored, _ := auth.readConfigFile()
+
+	ctx := core.NewDryRunContext(context.Background(), core.NewDryRunPlan(core.DryRunFormatPlan))
+	if _, err := auth.RefreshAccessToken(ctx); err == nil {
+		t.Errorf("expected no renewal when planning requests")
+	}
+	if len(transport.forms) != 1 {
+		t.Errorf("expected no request when planning, got %v", transport.urls)
+	}
+	if current, _ := auth.readConfigFile(); !reflect.DeepEqual(stored, current) {
+		t.Errorf("expected auth data to be kept when planning, got %+v", current)
+	}
+}
+
+func TestClientCredentialsSecretStorage(t *testing.T) {
+	transport := &sequenceTransport{responses: []mockResponse{
+		{http.StatusOK, `{"access_token":"service-access"}`},
+		{http.StatusOK, `{"access_token":"service-access-2"}`},
+		{http.StatusOK, `{"access_token":"service-access-3"}`},
+	}}
+	auth := newDeviceTestAuth(t, transport)
+	t.Setenv(clientSecretEnvVar, "")
+
+	if err := auth.RequestAuthTokenWithClientCredentials(context.Background(), "sa-id", "sa-secret", nil); err != nil {
+		t.Fatalf("unable to login with client credentials: %v", err)
+	}
+
+	// Another invocation only has what the plain storage kept
+	auth.InitTokensFromFile()
+	if _, err := auth.RefreshAccessToken(context.Background()); err == nil {
+		t.Fatalf("expected renewal to fail without the client secret")
+	}
+
+	t.Setenv(clientSecretEnvVar, "env-secret")
+	if _, err := auth.RefreshAccessToken(context.Background()); err != nil {
+		t.Fatalf("unable to renew client credentials token: %v", err)
+	}
+	if form := transport.forms[1]; form.Get("client_secret") != "env-secret" {
+		t.Errorf("expected the client secret from %s, got %v", clientSecretEnvVar, form)
+	}
+
+	t.Setenv(passphraseEnvVar, "secret passphrase")
+	if _, _, err := auth.MigrateStorage(auth.profileManager.Current(), EncryptedFileStorage); err != nil {
+		t.Fatalf("unable to migrate storage: %v", err)
+	}
+	if err := auth.RequestAuthTokenWithClientCredentials(context.Background(), "sa-id", "sa-secret", nil); err != nil {
+		t.Fatalf("unable to login with client credentials: %v", err)
+	}
+	stored, err := auth.readConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ClientCredentials == nil || stored.ClientCredentials.ClientSecret != "sa-secret" {
+		t.Errorf("expected the client secret to be kept by the encrypted storage, got %+v", stored.ClientCredentials)
+	}
+}
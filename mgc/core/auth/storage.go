@@ -0,0 +1,362 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	"github.com/invopop/yaml"
+)
+
+const (
+	PlainStorage         = "plain"
+	EncryptedFileStorage = "encrypted-file"
+	KeyringStorage       = "keyring"
+
+	encryptedAuthFilename = "auth.yaml.enc"
+	keyringAuthFilename   = "auth.keyring.yaml"
+
+	passphraseEnvVar = "MGC_AUTH_PASSPHRASE"
+	storageEnvVar    = "MGC_AUTH_STORAGE"
+)
+
+var StorageNames = []string{PlainStorage, EncryptedFileStorage, KeyringStorage}
+
+// PassphrasePrompt is used by the encrypted file storage to ask for the passphrase when
+// MGC_AUTH_PASSPHRASE is not set. It's nil unless an interactive frontend (ie: CLI) sets it
+var PassphrasePrompt func(message string) (string, error)
+
+// SecretStorage persists the auth data (tokens and keys) of a profile
+type SecretStorage interface {
+	Name() string
+	// Returns an error satisfying errors.Is(err, os.ErrNotExist) if there is no data stored
+	Read(p *profile_manager.Profile) ([]byte, error)
+	Write(p *profile_manager.Profile, data []byte) error
+	Delete(p *profile_manager.Profile) error
+}
+
+func newSecretStorage(name string) (SecretStorage, error) {
+	switch name {
+	case PlainStorage, "":
+		return plainStorage{}, nil
+	case EncryptedFileStorage:
+		return &encryptedFileStorage{}, nil
+	case KeyringStorage:
+		return keyringStorage{}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth storage %q, must be one of %s", name, strings.Join(StorageNames, ", "))
+	}
+}
+
+// IsCredentialFile reports whether the profile file, as named by Profile.Read(), is written by
+// one of the storages, ie: it must be left out when exporting workspaces without credentials
+func IsCredentialFile(name string) bool {
+	switch name {
+	case authFilename, encryptedAuthFilename, keyringAuthFilename:
+		return true
+	}
+	return false
+}
+
+// IsPortableFile reports whether the profile file still makes sense when moved to another
+// profile. The keyring marker doesn't, as its data is bound to the original profile directory
+func IsPortableFile(name string) bool {
+	return name != keyringAuthFilename
+}
+
+func profileFileExists(p *profile_manager.Profile, name string) bool {
+	_, err := p.Read(name)
+	return err == nil
+}
+
+// The storage in use by a profile is detected by its files, so each workspace keeps the
+// storage it was migrated to. Profiles without any data use 'defaultName'
+func detectStorageName(p *profile_manager.Profile, defaultName string) string {
+	switch {
+	case profileFileExists(p, encryptedAuthFilename):
+		return EncryptedFileStorage
+	case profileFileExists(p, keyringAuthFilename):
+		return KeyringStorage
+	case profileFileExists(p, authFilename):
+		return PlainStorage
+	default:
+		return defaultName
+	}
+}
+
+type plainStorage struct{}
+
+func (plainStorage) Name() string {
+	return PlainStorage
+}
+
+func (plainStorage) Read(p *profile_manager.Profile) ([]byte, error) {
+	return p.Read(authFilename)
+}
+
+func (plainStorage) Write(p *profile_manager.Profile, data []byte) error {
+	return p.Write(authFilename, data)
+}
+
+func (plainStorage) Delete(p *profile_manager.Profile) error {
+	return p.Delete(authFilename)
+}
+
+const (
+	encryptedFileVersion = 1
+	pbkdf2Iterations     = 600_000
+	encryptionKeySize    = 32 // AES-256
+	saltSize             = 16
+)
+
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// encryptedFileStorage encrypts the data with AES-256-GCM, using a key derived from the
+// passphrase with PBKDF2-SHA256. Derived keys are cached, so the passphrase is asked once
+type encryptedFileStorage struct {
+	mutex      sync.Mutex
+	passphrase string
+	keys       map[string][]byte
+}
+
+func (*encryptedFileStorage) Name() string {
+	return EncryptedFileStorage
+}
+
+func (s *encryptedFileStorage) getPassphrase(p *profile_manager.Profile) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+
+	if passphrase := os.Getenv(passphraseEnvVar); passphrase != "" {
+		s.passphrase = passphrase
+		return passphrase, nil
+	}
+
+	if PassphrasePrompt == nil {
+		return "", fmt.Errorf("credentials of workspace %q are encrypted, set the passphrase in %s", p.Name, passphraseEnvVar)
+	}
+
+	passphrase, err := PassphrasePrompt(fmt.Sprintf("Passphrase of workspace %q credentials:", p.Name))
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("empty passphrase")
+	}
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+func (s *encryptedFileStorage) deriveKey(p *profile_manager.Profile, salt []byte, iterations int) ([]byte, error) {
+	if key, ok := s.keys[string(salt)]; ok {
+		return key, nil
+	}
+
+	passphrase, err := s.getPassphrase(p)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, encryptionKeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.keys == nil {
+		s.keys = map[string][]byte{}
+	}
+	s.keys[string(salt)] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *encryptedFileStorage) readEncryptedFile(p *profile_manager.Profile) (*encryptedFile, error) {
+	data, err := p.Read(encryptedAuthFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &encryptedFile{}
+	if err = yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("bad format encrypted auth file: %w", err)
+	}
+	if file.Version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported encrypted auth file version %d", file.Version)
+	}
+	return file, nil
+}
+
+func (s *encryptedFileStorage) Read(p *profile_manager.Profile) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := s.readEncryptedFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.deriveKey(p, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		// Don't keep asking with a wrong passphrase
+		delete(s.keys, string(file.Salt))
+		s.passphrase = ""
+		return nil, fmt.Errorf("unable to decrypt credentials of workspace %q, wrong passphrase?", p.Name)
+	}
+	return data, nil
+}
+
+func (s *encryptedFileStorage) Write(p *profile_manager.Profile, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Keep the salt of the existing file, so its cached key may be reused
+	var salt []byte
+	if file, err := s.readEncryptedFile(p); err == nil {
+		if _, ok := s.keys[string(file.Salt)]; ok && file.Iterations == pbkdf2Iterations {
+			salt = file.Salt
+		}
+	}
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+	}
+
+	key, err := s.deriveKey(p, salt, pbkdf2Iterations)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	encoded, err := yaml.Marshal(&encryptedFile{
+		Version:    encryptedFileVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: pbkdf2Iterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, data, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	return p.Write(encryptedAuthFilename, encoded)
+}
+
+func (s *encryptedFileStorage) Delete(p *profile_manager.Profile) error {
+	return p.Delete(encryptedAuthFilename)
+}
+
+// Variable so tests may replace it
+var secretToolCommand = func(stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("secret-tool", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(out) == 0 && stderr.Len() == 0 {
+			// 'lookup' exits with failure and no output if the secret doesn't exist
+			return nil, os.ErrNotExist
+		}
+		return nil, fmt.Errorf("secret-tool %s failed: %w %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// keyringStorage stores the data in the freedesktop Secret Service (ie: GNOME Keyring, KWallet)
+// using libsecret's 'secret-tool'. The profile only keeps a file marking the data is in the keyring
+type keyringStorage struct{}
+
+type keyringMarker struct {
+	Service string `json:"service"`
+	Account string `json:"account"`
+}
+
+const keyringService = "mgc"
+
+func (keyringStorage) Name() string {
+	return KeyringStorage
+}
+
+// Profiles are identified by their directory, as different MGC homes may have profiles with the same name
+func keyringAttributes(p *profile_manager.Profile) []string {
+	return []string{"service", keyringService, "account", p.Dir()}
+}
+
+func (keyringStorage) Read(p *profile_manager.Profile) ([]byte, error) {
+	if !profileFileExists(p, keyringAuthFilename) {
+		return nil, os.ErrNotExist
+	}
+	return secretToolCommand(nil, append([]string{"lookup"}, keyringAttributes(p)...)...)
+}
+
+func (keyringStorage) Write(p *profile_manager.Profile, data []byte) error {
+	label := fmt.Sprintf("Magalu Cloud CLI credentials (%s)", p.Name)
+	args := append([]string{"store", "--label", label}, keyringAttributes(p)...)
+	if _, err := secretToolCommand(data, args...); err != nil {
+		return err
+	}
+
+	marker, err := yaml.Marshal(keyringMarker{Service: keyringService, Account: p.Dir()})
+	if err != nil {
+		return err
+	}
+	return p.Write(keyringAuthFilename, marker)
+}
+
+func (keyringStorage) Delete(p *profile_manager.Profile) error {
+	if _, err := secretToolCommand(nil, append([]string{"clear"}, keyringAttributes(p)...)...); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return p.Delete(keyringAuthFilename)
+}
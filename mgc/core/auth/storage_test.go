@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+)
+
+func TestEncryptedFileStorage(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	p := m.Current()
+	t.Setenv(passphraseEnvVar, "secret passphrase")
+
+	storage := &encryptedFileStorage{}
+	if err := storage.Write(p, dummyConfigResultYaml); err != nil {
+		t.Fatalf("unable to write: %v", err)
+	}
+
+	encrypted, err := p.Read(encryptedAuthFilename)
+	if err != nil {
+		t.Fatalf("encrypted file not written: %v", err)
+	}
+	if bytes.Contains(encrypted, []byte("refresh-token")) {
+		t.Errorf("encrypted file contains plain text credentials")
+	}
+
+	// A new storage doesn't have the cached key, so it derives it again from the passphrase
+	data, err := (&encryptedFileStorage{}).Read(p)
+	if err != nil {
+		t.Fatalf("unable to read: %v", err)
+	}
+	if !bytes.Equal(data, dummyConfigResultYaml) {
+		t.Errorf("expected %q, got %q", dummyConfigResultYaml, data)
+	}
+
+	t.Setenv(passphraseEnvVar, "wrong passphrase")
+	if _, err = (&encryptedFileStorage{}).Read(p); err == nil {
+		t.Errorf("expected error reading with wrong passphrase")
+	}
+}
+
+func TestEncryptedFileStorageWithoutPassphrase(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	t.Setenv(passphraseEnvVar, "")
+
+	if err := (&encryptedFileStorage{}).Write(m.Current(), dummyConfigResultYaml); err == nil {
+		t.Errorf("expected error without passphrase")
+	}
+}
+
+func TestKeyringStorage(t *testing.T) {
+	secrets := map[string][]byte{}
+	original := secretToolCommand
+	defer func() { secretToolCommand = original }()
+	secretToolCommand = func(stdin []byte, args ...string) ([]byte, error) {
+		account := args[len(args)-1]
+		switch args[0] {
+		case "store":
+			secrets[account] = stdin
+		case "lookup":
+			if data, ok := secrets[account]; ok {
+				return data, nil
+			}
+			return nil, os.ErrNotExist
+		case "clear":
+			delete(secrets, account)
+		}
+		return nil, nil
+	}
+
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	p := m.Current()
+	storage := keyringStorage{}
+
+	if _, err := storage.Read(p); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+	if err := storage.Write(p, dummyConfigResultYaml); err != nil {
+		t.Fatalf("unable to write: %v", err)
+	}
+	if detected := detectStorageName(p, PlainStorage); detected != KeyringStorage {
+		t.Errorf("expected keyring storage to be detected, got %q", detected)
+	}
+	data, err := storage.Read(p)
+	if err != nil || !bytes.Equal(data, dummyConfigResultYaml) {
+		t.Errorf("expected %q, got %q (error: %v)", dummyConfigResultYaml, data, err)
+	}
+	if err = storage.Delete(p); err != nil {
+		t.Fatalf("unable to delete: %v", err)
+	}
+	if len(secrets) != 0 {
+		t.Errorf("expected secret to be cleared, got %v", secrets)
+	}
+}
+
+func TestMigrateStorage(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	p := m.Current()
+	t.Setenv(passphraseEnvVar, "secret passphrase")
+	t.Setenv(storageEnvVar, "")
+	t.Setenv("MGC_ACCESS_TOKEN", "")
+
+	if err := p.Write(authFilename, dummyConfigResultYaml); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := New(dummyConfigMap, &http.Client{}, m, config.New(m))
+
+	from, migrated, err := auth.MigrateStorage(p, EncryptedFileStorage)
+	if err != nil || !migrated || from != PlainStorage {
+		t.Fatalf("expected migration from plain, got from=%q migrated=%v error=%v", from, migrated, err)
+	}
+	if _, err = p.Read(authFilename); err == nil {
+		t.Errorf("plain auth file should be removed after migration")
+	}
+	if name := auth.StorageName(p); name != EncryptedFileStorage {
+		t.Errorf("expected encrypted-file storage, got %q", name)
+	}
+
+	// Updates keep using the migrated storage
+	if err = auth.SetAccessKey("key-id", "key-secret"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := New(dummyConfigMap, &http.Client{}, m, config.New(m))
+	if id, secret := reloaded.AccessKeyPair(); id != "key-id" || secret != "key-secret" {
+		t.Errorf("expected access key pair to be read from encrypted storage, got %q %q", id, secret)
+	}
+
+	if _, migrated, err = auth.MigrateStorage(p, EncryptedFileStorage); err != nil || migrated {
+		t.Errorf("expected no migration to the same storage, got migrated=%v error=%v", migrated, err)
+	}
+}
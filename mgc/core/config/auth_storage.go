@@ -0,0 +1,12 @@
+package config
+
+import mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+
+// Must match the storages supported by the auth package
+func authStorageSchema() *mgcSchemaPkg.Schema {
+	s := mgcSchemaPkg.NewStringSchema()
+	s.Description = "Where new credentials are stored: plain file, file encrypted with a passphrase or the system keyring. " +
+		"Use 'auth migrate-storage' to move existing credentials"
+	s.Enum = []any{"plain", "encrypted-file", "keyring"}
+	return s
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/invopop/jsonschema"
+)
+
+const CommandDefaultsKey = "commandDefaults"
+
+// CommandDefaults are used by a command when the values are not given in the command line.
+// Keys are parameter, config or flag names. Values may be given as in the command line
+// (ie: "table=id,name") or as the JSON value itself
+type CommandDefaults struct {
+	Parameters map[string]any `json:"parameters,omitempty" jsonschema:"description=Default parameters of the command"`
+	Configs    map[string]any `json:"configs,omitempty" jsonschema:"description=Default configs of the command"`
+	Flags      map[string]any `json:"flags,omitempty" jsonschema:"description=Default CLI flags\\, such as 'output' or 'cli.watch'"`
+}
+
+func commandDefaultsSchema() (*schema.Schema, error) {
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	s, err := schema.ToCoreSchema(reflector.Reflect(map[string]CommandDefaults{}))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Description = "Defaults of each command, keyed by the command path without 'mgc', such as 'virtual-machine instances list'. " +
+		"Values given in the command line take precedence. Use '--cli.no-defaults' to ignore them"
+	return s, nil
+}
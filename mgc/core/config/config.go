@@ -77,13 +77,33 @@ func (c *Config) BuiltInConfigs() (map[string]*core.Schema, error) {
 		return nil, fmt.Errorf("unable to get logger config schema: %w", err)
 	}
 
+	retryConfigSchema, err := retrySchema()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get retry config schema: %w", err)
+	}
+
+	rateLimitConfigSchema, err := rateLimitSchema()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get rate limit config schema: %w", err)
+	}
+
+	commandDefaultsConfigSchema, err := commandDefaultsSchema()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get command defaults config schema: %w", err)
+	}
+
 	logfilterSchema := logfilterSchema()
 	defaultOutputSchema := defaultOutputSchema()
+	authStorageSchema := authStorageSchema()
 
 	configMap := map[string]*core.Schema{
-		"logging":       loggerConfigSchema,
-		"logfilter":     logfilterSchema,
-		"defaultOutput": defaultOutputSchema,
+		"logging":          loggerConfigSchema,
+		"logfilter":        logfilterSchema,
+		"defaultOutput":    defaultOutputSchema,
+		"retry":            retryConfigSchema,
+		"rateLimit":        rateLimitConfigSchema,
+		"authStorage":      authStorageSchema,
+		CommandDefaultsKey: commandDefaultsConfigSchema,
 	}
 
 	return configMap, nil
@@ -0,0 +1,19 @@
+package config
+
+import (
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+	"github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/invopop/jsonschema"
+)
+
+func rateLimitSchema() (*schema.Schema, error) {
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	s, err := schema.ToCoreSchema(reflector.Reflect(mgcHttpPkg.RateLimitConfig{}))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Description = "Client-side limit of requests per second, shared by all commands running in the same process. " +
+		"Hosts without a limit are not limited"
+	return s, nil
+}
@@ -0,0 +1,18 @@
+package config
+
+import (
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+	"github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/invopop/jsonschema"
+)
+
+func retrySchema() (*schema.Schema, error) {
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	s, err := schema.ToCoreSchema(reflector.Reflect(mgcHttpPkg.RetryPolicy{}))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Description = "Retry policy of failed HTTP requests. Unset fields keep their default values"
+	return s, nil
+}
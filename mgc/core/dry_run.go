@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// DryRunFormat is how the planned actions are reported to the user
+type DryRunFormat string
+
+const (
+	DryRunFormatPlan DryRunFormat = "plan"
+	DryRunFormatCurl DryRunFormat = "curl"
+)
+
+var DryRunFormats = []DryRunFormat{DryRunFormatPlan, DryRunFormatCurl}
+
+// DryRunRequest is an HTTP request that would be sent. Sensitive headers are redacted
+type DryRunRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty" jsonschema:"oneof_type=object;array;string"`
+	Curl    string            `json:"curl"`
+}
+
+// DryRunAction is something an executor would do, such as sending a request, uploading or deleting a file
+type DryRunAction struct {
+	Action      string         `json:"action"`
+	Source      string         `json:"src,omitempty"`
+	Destination string         `json:"dst,omitempty"`
+	Request     *DryRunRequest `json:"request,omitempty"`
+}
+
+// DryRunPlan collects the actions of executors running with a dry-run context.
+// It's safe to be used by multiple goroutines, as transfers usually run in parallel
+type DryRunPlan struct {
+	Format  DryRunFormat
+	mutex   sync.Mutex
+	actions []DryRunAction
+}
+
+func NewDryRunPlan(format DryRunFormat) *DryRunPlan {
+	return &DryRunPlan{Format: format}
+}
+
+func (p *DryRunPlan) Add(action DryRunAction) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.actions = append(p.actions, action)
+}
+
+func (p *DryRunPlan) Actions() []DryRunAction {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return slices.Clone(p.actions)
+}
+
+type dryRunPlanKey struct{}
+
+// NewDryRunContext marks the context so executors supporting it (see DryRunExecutor) add
+// their actions to the plan instead of executing them
+func NewDryRunContext(parent context.Context, plan *DryRunPlan) context.Context {
+	return context.WithValue(parent, dryRunPlanKey{}, plan)
+}
+
+// DryRunPlanFromContext returns nil if the context is not in dry-run mode
+func DryRunPlanFromContext(ctx context.Context) *DryRunPlan {
+	plan, _ := ctx.Value(dryRunPlanKey{}).(*DryRunPlan)
+	return plan
+}
+
+// DryRunExecutor is implemented by executors that honor NewDryRunContext().
+// Executors that don't implement it MUST NOT be executed in dry-run mode, as they would execute their actions
+type DryRunExecutor interface {
+	Executor
+	SupportsDryRun() bool
+}
+
+func NewDryRunExecutor(exec Executor) DryRunExecutor {
+	return &dryRunExecutor{exec}
+}
+
+type dryRunExecutor struct {
+	Executor
+}
+
+func (o *dryRunExecutor) Execute(ctx context.Context, parameters Parameters, configs Configs) (result Result, err error) {
+	result, err = o.Executor.Execute(ctx, parameters, configs)
+	return ExecutorWrapResult(o, result, err)
+}
+
+func (o *dryRunExecutor) Unwrap() Executor {
+	return o.Executor
+}
+
+func (o *dryRunExecutor) SupportsDryRun() bool {
+	return true
+}
+
+var _ Executor = (*dryRunExecutor)(nil)
+var _ ExecutorWrapper = (*dryRunExecutor)(nil)
+var _ DryRunExecutor = (*dryRunExecutor)(nil)
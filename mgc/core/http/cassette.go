@@ -0,0 +1,568 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/invopop/yaml"
+)
+
+// Cassettes let scripts using the CLI be tested offline: requests and responses are recorded
+// to a file with MGC_HTTP_RECORD=path, then replayed from it with MGC_HTTP_REPLAY=path.
+//
+// With MGC_HTTP_REPLAY_STRICT=1, requests not found in the cassette fail, otherwise
+// they are sent to the network (and recorded if MGC_HTTP_RECORD is also set).
+//
+// Recording appends to an existing cassette, so a script calling the CLI several times records
+// all of its invocations. Remove the cassette to record it again from scratch.
+const (
+	recordEnvVar       = "MGC_HTTP_RECORD"
+	replayEnvVar       = "MGC_HTTP_REPLAY"
+	replayStrictEnvVar = "MGC_HTTP_REPLAY_STRICT"
+)
+
+const cassetteVersion = 1
+
+const redactedValue = "[REDACTED]"
+
+// Larger response bodies, or binary ones such as object downloads, are not written to cassettes.
+// They're streamed to the client as they are, and their interactions can't be replayed
+const maxCassetteBodySize = 1024 * 1024
+
+// Fields of JSON bodies that are never written to cassettes
+var sensitiveBodyFields = []string{"access_token", "refresh_token", "id_token", "api_key", "client_secret"}
+
+type CassetteRequest struct {
+	Method string `json:"method"`
+	// URL is informative only, requests are matched on Method, Path, Query and BodyHash
+	URL      string            `json:"url"`
+	Path     string            `json:"path"`
+	Query    string            `json:"query,omitempty"`
+	BodyHash string            `json:"bodyHash,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int                 `json:"statusCode"`
+	Status     string              `json:"status"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`
+	// "base64" if Body isn't valid UTF-8 text
+	BodyEncoding string `json:"bodyEncoding,omitempty"`
+	// Set if the body was too large or binary to be recorded
+	BodyOmitted bool `json:"bodyOmitted,omitempty"`
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type Cassette struct {
+	Version      int                   `json:"version"`
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+type UnmatchedCassetteRequestError struct {
+	Cassette string
+	Method   string
+	URL      string
+}
+
+func (e *UnmatchedCassetteRequestError) Error() string {
+	return fmt.Sprintf("no interaction in cassette %q matches %s %s", e.Cassette, e.Method, e.URL)
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cassette: %w", err)
+	}
+	return decodeCassette(path, data)
+}
+
+func decodeCassette(path string, data []byte) (*Cassette, error) {
+	c := &Cassette{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("unable to decode cassette %q: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %q, expected %d", c.Version, path, cassetteVersion)
+	}
+	return c, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("unable to encode cassette: %w", err)
+	}
+	if err = createCassetteDir(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func createCassetteDir(path string) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("unable to create cassette directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendCassetteInteraction adds the interaction to the cassette at path, creating it if needed.
+// The file is locked while it's loaded and saved, as other processes may be recording to it
+func appendCassetteInteraction(path string, interaction CassetteInteraction) error {
+	if err := createCassetteDir(path); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open cassette: %w", err)
+	}
+	defer f.Close()
+
+	if err = lockFile(f); err != nil {
+		return fmt.Errorf("unable to lock cassette: %w", err)
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("unable to read cassette: %w", err)
+	}
+
+	cassette := &Cassette{Version: cassetteVersion}
+	if len(data) > 0 {
+		if cassette, err = decodeCassette(path, data); err != nil {
+			return err
+		}
+	}
+	cassette.Interactions = append(cassette.Interactions, interaction)
+
+	if data, err = yaml.Marshal(cassette); err != nil {
+		return fmt.Errorf("unable to encode cassette: %w", err)
+	}
+	if err = f.Truncate(0); err != nil {
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+	if _, err = f.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+	return nil
+}
+
+func cassetteRequestKey(method, path, query, bodyHash string) string {
+	return strings.Join([]string{method, path, query, bodyHash}, " ")
+}
+
+func (r CassetteRequest) key() string {
+	return cassetteRequestKey(r.Method, r.Path, r.Query, r.BodyHash)
+}
+
+// Reads the request body, leaving it intact to be sent. Returns the SHA-256 of the body, empty if there's none
+func requestBodyHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	var body io.ReadCloser
+	var err error
+	if req.GetBody != nil {
+		body, err = req.GetBody()
+	} else {
+		var data []byte
+		data, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		body = io.NopCloser(bytes.NewReader(data))
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read request body: %w", err)
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read request body: %w", err)
+	}
+	if n == 0 {
+		return "", nil
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Query parameters are sorted, so their order doesn't affect matching
+func newCassetteRequest(req *http.Request) (CassetteRequest, error) {
+	bodyHash, err := requestBodyHash(req)
+	if err != nil {
+		return CassetteRequest{}, err
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for key, values := range req.Header {
+		value := strings.Join(values, ", ")
+		if isHeaderSensitive(key) {
+			value = redactedValue
+		}
+		headers[key] = value
+	}
+
+	return CassetteRequest{
+		Method:   req.Method,
+		URL:      redactURL(req.URL),
+		Path:     req.URL.Path,
+		Query:    req.URL.Query().Encode(),
+		BodyHash: bodyHash,
+		Headers:  headers,
+	}, nil
+}
+
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	return redacted.String()
+}
+
+func redactBody(body []byte) []byte {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	if !redactJSON(value) {
+		return body
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactJSON(value any) (changed bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if isBodyFieldSensitive(key) {
+				if _, isString := child.(string); isString {
+					v[key] = redactedValue
+					changed = true
+					continue
+				}
+			}
+			changed = redactJSON(child) || changed
+		}
+	case []any:
+		for _, child := range v {
+			changed = redactJSON(child) || changed
+		}
+	}
+	return
+}
+
+// Responses with redacted fields, such as new auth tokens, must not be replayed: the client
+// would store the redacted values in place of the user's real credentials
+func hasRedactedField(value any) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if isBodyFieldSensitive(key) && child == redactedValue {
+				return true
+			}
+			if hasRedactedField(child) {
+				return true
+			}
+		}
+	case []any:
+		for _, child := range v {
+			if hasRedactedField(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r CassetteResponse) isReplayable() bool {
+	if r.BodyOmitted {
+		return false
+	}
+	if r.BodyEncoding != "" {
+		return true
+	}
+	var value any
+	if err := json.Unmarshal([]byte(r.Body), &value); err != nil {
+		return true
+	}
+	return !hasRedactedField(value)
+}
+
+func isBodyFieldSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range sensitiveBodyFields {
+		if key == field {
+			return true
+		}
+	}
+	return false
+}
+
+// readCassetteBody reads the response body if it's small enough to be recorded. Otherwise, the
+// response keeps streaming its body and 'omitted' is true
+func readCassetteBody(resp *http.Response) (data []byte, omitted bool, err error) {
+	if resp.Header.Get("Content-Type") == "application/octet-stream" || resp.ContentLength > maxCassetteBodySize {
+		return nil, true, nil
+	}
+
+	data, err = io.ReadAll(io.LimitReader(resp.Body, maxCassetteBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, false, fmt.Errorf("unable to read response body: %w", err)
+	}
+	if len(data) > maxCassetteBodySize {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return nil, true, nil
+	}
+
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return data, false, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newCassetteResponse(resp *http.Response) (CassetteResponse, error) {
+	data, omitted, err := readCassetteBody(resp)
+	if err != nil {
+		return CassetteResponse{}, err
+	}
+
+	headers := make(map[string][]string, len(resp.Header))
+	for key, values := range resp.Header {
+		if key == "Set-Cookie" {
+			values = []string{redactedValue}
+		}
+		headers[key] = values
+	}
+
+	r := CassetteResponse{
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		Headers:     headers,
+		BodyOmitted: omitted,
+	}
+	if omitted {
+		return r, nil
+	}
+
+	// Compressed bodies are kept as they are, the client decompresses them
+	if resp.Header.Get("Content-Encoding") == "" {
+		data = redactBody(data)
+	}
+	if utf8.Valid(data) {
+		r.Body = string(data)
+	} else {
+		r.Body = base64.StdEncoding.EncodeToString(data)
+		r.BodyEncoding = "base64"
+	}
+	return r, nil
+}
+
+func (r CassetteResponse) toResponse(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.BodyEncoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(r.Body); err != nil {
+			return nil, fmt.Errorf("invalid base64 body in cassette: %w", err)
+		}
+	}
+
+	header := make(http.Header, len(r.Headers))
+	for key, values := range r.Headers {
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	status := r.Status
+	if status == "" {
+		status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+
+	return &http.Response{
+		Status:        status,
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// ClientRecorder sends the requests with Transport and appends every interaction to the cassette at Path
+type ClientRecorder struct {
+	Transport http.RoundTripper
+	Path      string
+
+	mutex sync.Mutex
+}
+
+func NewClientRecorder(transport http.RoundTripper, path string) *ClientRecorder {
+	return &ClientRecorder{
+		Transport: transport,
+		Path:      path,
+	}
+}
+
+func (t *ClientRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	cassetteReq, err := newCassetteRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	cassetteResp, err := newCassetteResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Saved on every interaction, as the process may exit at any time
+	if err = appendCassetteInteraction(t.Path, CassetteInteraction{Request: cassetteReq, Response: cassetteResp}); err != nil {
+		logger().Warnw("unable to save HTTP cassette", "path", t.Path, "error", err)
+	}
+	return resp, nil
+}
+
+// ClientReplayer answers requests with the interactions of the cassette at Path. Interactions with
+// the same request are replayed in the recorded order, the last one is repeated once they're exhausted.
+// Interactions whose body was omitted or redacted (ie: auth token refreshes) are never replayed.
+//
+// Unmatched requests fail if Strict, otherwise they're sent with Transport
+type ClientReplayer struct {
+	Transport http.RoundTripper
+	Path      string
+	Strict    bool
+
+	loadOnce sync.Once
+	loadErr  error
+
+	mutex        sync.Mutex
+	interactions map[string][]CassetteResponse
+	replayed     map[string]int
+}
+
+func NewClientReplayer(transport http.RoundTripper, path string, strict bool) *ClientReplayer {
+	return &ClientReplayer{
+		Transport: transport,
+		Path:      path,
+		Strict:    strict,
+	}
+}
+
+func (t *ClientReplayer) load() error {
+	t.loadOnce.Do(func() {
+		cassette, err := LoadCassette(t.Path)
+		if err != nil {
+			t.loadErr = err
+			return
+		}
+
+		t.interactions = map[string][]CassetteResponse{}
+		t.replayed = map[string]int{}
+		for _, interaction := range cassette.Interactions {
+			if !interaction.Response.isReplayable() {
+				logger().Debugw("Ignoring cassette interaction with omitted or redacted body", "method", interaction.Request.Method, "url", interaction.Request.URL)
+				continue
+			}
+			key := interaction.Request.key()
+			t.interactions[key] = append(t.interactions[key], interaction.Response)
+		}
+	})
+	return t.loadErr
+}
+
+func (t *ClientReplayer) next(key string) (CassetteResponse, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	responses := t.interactions[key]
+	if len(responses) == 0 {
+		return CassetteResponse{}, false
+	}
+
+	i := min(t.replayed[key], len(responses)-1)
+	t.replayed[key]++
+	return responses[i], true
+}
+
+func (t *ClientReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+
+	bodyHash, err := requestBodyHash(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := cassetteRequestKey(req.Method, req.URL.Path, req.URL.Query().Encode(), bodyHash)
+	if resp, ok := t.next(key); ok {
+		logger().Debugw("Replayed HTTP request from cassette", "method", req.Method, "url", req.URL.String())
+		return resp.toResponse(req)
+	}
+
+	if t.Strict {
+		return nil, &UnmatchedCassetteRequestError{Cassette: t.Path, Method: req.Method, URL: req.URL.String()}
+	}
+
+	logger().Warnw("HTTP request not found in cassette, sending it", "method", req.Method, "url", req.URL.String())
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// Wraps the transport with a ClientRecorder and/or ClientReplayer according to
+// MGC_HTTP_RECORD, MGC_HTTP_REPLAY and MGC_HTTP_REPLAY_STRICT. Returns the transport unchanged if none is set
+func NewCassetteTransportFromEnv(transport http.RoundTripper) http.RoundTripper {
+	if path := os.Getenv(recordEnvVar); path != "" {
+		transport = NewClientRecorder(transport, path)
+	}
+	if path := os.Getenv(replayEnvVar); path != "" {
+		transport = NewClientReplayer(transport, path, os.Getenv(replayStrictEnvVar) == "1")
+	}
+	return transport
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type cassetteTransportTestCase struct {
+	calls int
+}
+
+func (t *cassetteTransportTestCase) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	body := `{"id":"vm-1"}`
+	if req.URL.Path == "/oauth/token" {
+		body = `{"access_token":"secret-token","refresh_token":"secret-refresh"}`
+	} else if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = `{"echo":"` + string(data) + `"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func doCassetteRequest(t *testing.T, transport http.RoundTripper, method, url, body string) (*http.Response, string, error) {
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req, _ := http.NewRequest(method, url, reqBody)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading body: %v", err)
+	}
+	return resp, string(data), nil
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	network := &cassetteTransportTestCase{}
+
+	recorder := NewClientRecorder(network, path)
+	_, body, err := doCassetteRequest(t, recorder, http.MethodGet, "https://api.test/vms?b=2&a=1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkCassetteBody(t, `{"id":"vm-1"}`, body)
+	_, body, err = doCassetteRequest(t, recorder, http.MethodPost, "https://api.test/oauth/token", "refresh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(body, "secret-token") {
+		t.Errorf("recorder must not change the response seen by the client, got %s", body)
+	}
+	if _, _, err = doCassetteRequest(t, recorder, http.MethodPost, "https://api.test/vms", "one"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading cassette: %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") || strings.Contains(string(data), "secret-refresh") {
+		t.Errorf("cassette must not contain tokens:\n%s", data)
+	}
+
+	replayer := NewClientReplayer(network, path, true)
+	calls := network.calls
+
+	resp, body, err := doCassetteRequest(t, replayer, http.MethodGet, "https://other.host/vms?a=1&b=2", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"id":"vm-1"`) {
+		t.Errorf("unexpected replayed response %d: %s", resp.StatusCode, body)
+	}
+
+	_, body, err = doCassetteRequest(t, replayer, http.MethodPost, "https://api.test/vms", "one")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkCassetteBody(t, `{"echo":"one"}`, body)
+
+	_, _, err = doCassetteRequest(t, replayer, http.MethodPost, "https://api.test/vms", "two")
+	unmatchedErr := &UnmatchedCassetteRequestError{}
+	if !errors.As(err, &unmatchedErr) {
+		t.Errorf("expected UnmatchedCassetteRequestError for a different body, got %v", err)
+	}
+
+	// The redacted tokens would replace the real ones of the user
+	_, _, err = doCassetteRequest(t, replayer, http.MethodPost, "https://api.test/oauth/token", "refresh")
+	if !errors.As(err, &unmatchedErr) {
+		t.Errorf("expected token refresh to not be replayed, got %v", err)
+	}
+
+	if network.calls != calls {
+		t.Errorf("strict replay must not send requests, got %d calls", network.calls-calls)
+	}
+
+	replayer = NewClientReplayer(network, path, false)
+	_, body, err = doCassetteRequest(t, replayer, http.MethodPost, "https://api.test/vms", "two")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkCassetteBody(t, `{"echo":"two"}`, body)
+	if network.calls != calls+1 {
+		t.Errorf("non strict replay must send unmatched requests")
+	}
+
+	_, body, err = doCassetteRequest(t, replayer, http.MethodPost, "https://api.test/oauth/token", "refresh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(body, `"access_token":"secret-token"`) {
+		t.Errorf("expected token refresh to be sent to the network, got %s", body)
+	}
+}
+
+func TestCassetteRecordAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	network := &cassetteTransportTestCase{}
+
+	// Each CLI invocation of a script has its own recorder
+	for _, body := range []string{"one", "two"} {
+		if _, _, err := doCassetteRequest(t, NewClientRecorder(network, path), http.MethodPost, "https://api.test/vms", body); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cassette.Interactions) != 2 {
+		t.Fatalf("expected both invocations to be recorded, got %d interactions", len(cassette.Interactions))
+	}
+
+	replayer := NewClientReplayer(nil, path, true)
+	for _, body := range []string{"one", "two"} {
+		_, got, err := doCassetteRequest(t, replayer, http.MethodPost, "https://api.test/vms", body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkCassetteBody(t, `{"echo":"`+body+`"}`, got)
+	}
+}
+
+type cassetteBodyTransport struct {
+	header http.Header
+	body   string
+}
+
+func (t *cassetteBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Status:        "200 OK",
+		Header:        t.header,
+		Body:          io.NopCloser(strings.NewReader(t.body)),
+		ContentLength: -1,
+	}, nil
+}
+
+func TestCassetteOmittedBodies(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		transport *cassetteBodyTransport
+	}{
+		{name: "large", transport: &cassetteBodyTransport{header: http.Header{}, body: strings.Repeat("a", maxCassetteBodySize+10)}},
+		{name: "binary", transport: &cassetteBodyTransport{header: http.Header{"Content-Type": []string{"application/octet-stream"}}, body: "object content"}},
+	} {
+		path := filepath.Join(t.TempDir(), "cassette.yaml")
+
+		_, body, err := doCassetteRequest(t, NewClientRecorder(tc.transport, path), http.MethodGet, "https://bucket.test/object", "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if body != tc.transport.body {
+			t.Errorf("%s: recorder must stream the whole body, got %d bytes", tc.name, len(body))
+		}
+
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if resp := cassette.Interactions[0].Response; !resp.BodyOmitted || resp.Body != "" {
+			t.Errorf("%s: expected body to be omitted from the cassette, got %d bytes", tc.name, len(resp.Body))
+		}
+
+		_, _, err = doCassetteRequest(t, NewClientReplayer(nil, path, true), http.MethodGet, "https://bucket.test/object", "")
+		unmatchedErr := &UnmatchedCassetteRequestError{}
+		if !errors.As(err, &unmatchedErr) {
+			t.Errorf("%s: expected interaction without body to not be replayed, got %v", tc.name, err)
+		}
+	}
+}
+
+func checkCassetteBody(t *testing.T, expected, got string) {
+	if expected != got {
+		t.Errorf("expected body %s, got %s", expected, got)
+	}
+}
+
+func TestClientReplayerOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	cassette := Cassette{Version: cassetteVersion}
+	for _, status := range []string{"creating", "running"} {
+		cassette.Interactions = append(cassette.Interactions, CassetteInteraction{
+			Request:  CassetteRequest{Method: http.MethodGet, Path: "/vms/1"},
+			Response: CassetteResponse{StatusCode: http.StatusOK, Body: status},
+		})
+	}
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	replayer := NewClientReplayer(nil, path, true)
+	for _, expected := range []string{"creating", "running", "running"} {
+		_, body, err := doCassetteRequest(t, replayer, http.MethodGet, "https://api.test/vms/1", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		checkCassetteBody(t, expected, body)
+	}
+}
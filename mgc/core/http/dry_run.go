@@ -0,0 +1,47 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+)
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// NewDryRunRequest describes the request that would be sent, with the same redaction of
+// sensitive headers as the logs. 'body' is the decoded request body, 'rawBody' is what would be sent
+func NewDryRunRequest(req *http.Request, body any, rawBody []byte) *core.DryRunRequest {
+	logSensitive := shouldLogSensitive()
+	headers := make(map[string]string, len(req.Header))
+	keys := make([]string, 0, len(req.Header))
+	for key, values := range req.Header {
+		value := strings.Join(values, ", ")
+		if !logSensitive && isHeaderSensitive(key) {
+			value = fmt.Sprintf("[REDACTED %d CHARS]", len(value))
+		}
+		headers[key] = value
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	curl := []string{"curl", "-X", req.Method, shellQuote(req.URL.String())}
+	for _, key := range keys {
+		curl = append(curl, "-H", shellQuote(key+": "+headers[key]))
+	}
+	if len(rawBody) > 0 {
+		curl = append(curl, "--data-binary", shellQuote(string(rawBody)))
+	}
+
+	return &core.DryRunRequest{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: headers,
+		Body:    body,
+		Curl:    strings.Join(curl, " "),
+	}
+}
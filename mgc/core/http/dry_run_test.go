@@ -0,0 +1,29 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNewDryRunRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.example.com/v0/it's?a=1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Content-Type", "application/json")
+
+	dryRun := NewDryRunRequest(req, map[string]any{"name": "vm"}, []byte(`{"name":"vm"}`))
+
+	if dryRun.Method != http.MethodPost {
+		t.Errorf("expected method POST, got %q", dryRun.Method)
+	}
+	if dryRun.Headers["Authorization"] != "[REDACTED 19 CHARS]" {
+		t.Errorf("expected redacted Authorization, got %q", dryRun.Headers["Authorization"])
+	}
+	expectedCurl := `curl -X POST 'https://api.example.com/v0/it'\''s?a=1' -H 'Authorization: [REDACTED 19 CHARS]' ` +
+		`-H 'Content-Type: application/json' --data-binary '{"name":"vm"}'`
+	if dryRun.Curl != expectedCurl {
+		t.Errorf("expected curl:\n%s\ngot:\n%s", expectedCurl, dryRun.Curl)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package http
+
+import (
+	"os"
+	"syscall"
+)
+
+// Blocks until the exclusive lock of the file is acquired, it's released when the file is closed
+func lockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
@@ -0,0 +1,12 @@
+package http
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Blocks until the exclusive lock of the file is acquired, it's released when the file is closed
+func lockFile(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &windows.Overlapped{})
+}
@@ -31,7 +31,7 @@ type Client struct {
 
 func NewClient(transport http.RoundTripper) *Client {
 	return &Client{http.Client{
-		Transport: transport,
+		Transport: NewClientTracer(transport),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Don't follow redirects, return an error to use the last response.
 			return http.ErrUseLastResponse
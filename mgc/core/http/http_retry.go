@@ -2,23 +2,72 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"syscall"
 	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// RetryPolicy defines when and how failed requests are retried by ClientRetryer
+type RetryPolicy struct {
+	MaxAttempts        int           `json:"maxAttempts,omitempty" jsonschema:"description=Maximum number of attempts of each request\\, including the first one,minimum=1"`
+	BaseBackoff        time.Duration `json:"baseBackoff,omitempty" jsonschema:"type=string,description=Wait before the first retry\\, doubled on every following one. Example: 100ms"`
+	MaxBackoff         time.Duration `json:"maxBackoff,omitempty" jsonschema:"type=string,description=Maximum wait between retries. Example: 20s"`
+	Jitter             bool          `json:"jitter,omitempty" jsonschema:"description=Randomize the wait between retries to avoid many clients retrying at once"`
+	StatusCodes        []int         `json:"statusCodes,omitempty" jsonschema:"description=HTTP status codes considered transient and retried"`
+	RetryNonIdempotent bool          `json:"retryNonIdempotent,omitempty" jsonschema:"description=Also retry non-idempotent requests (ie: POST)\\, which may repeat actions already accepted by the server"`
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseBackoff: 100 * time.Millisecond,
+		MaxBackoff:  20 * time.Second,
+		Jitter:      true,
+		StatusCodes: []int{
+			http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+var idempotentKey contextKey = "github.com/MagaluCloud/magalu/mgc/core/Idempotent"
+
+// NewIdempotentContext marks the requests done with the returned context as safe to be
+// retried, regardless of their method. Use it for operations known to be idempotent
+func NewIdempotentContext(parent context.Context) context.Context {
+	return context.WithValue(parent, idempotentKey, true)
+}
+
+func isIdempotentContext(ctx context.Context) bool {
+	idempotent, _ := ctx.Value(idempotentKey).(bool)
+	return idempotent
+}
+
 type ClientRetryer struct {
 	Transport http.RoundTripper
-	attempts  int
+	// Called for every request, so policy changes (ie: config) take effect without recreating the transport
+	Policy func() RetryPolicy
 }
 
 func NewDefaultClientRetryer(transport http.RoundTripper) *ClientRetryer {
 	return &ClientRetryer{
 		Transport: transport,
-		attempts:  5,
+		Policy:    DefaultRetryPolicy,
 	}
 }
 
@@ -28,7 +77,21 @@ func NewClientRetryerWithAttempts(transport http.RoundTripper, attempts int) *Cl
 	}
 	return &ClientRetryer{
 		Transport: transport,
-		attempts:  attempts,
+		Policy: func() RetryPolicy {
+			policy := DefaultRetryPolicy()
+			policy.MaxAttempts = attempts
+			return policy
+		},
+	}
+}
+
+func NewClientRetryerWithPolicy(transport http.RoundTripper, policy func() RetryPolicy) *ClientRetryer {
+	if policy == nil {
+		return NewDefaultClientRetryer(transport)
+	}
+	return &ClientRetryer{
+		Transport: transport,
+		Policy:    policy,
 	}
 }
 
@@ -62,45 +125,139 @@ func (r *ClientRetryer) cloneRequest(req *http.Request) *http.Request {
 	return clonedRequest
 }
 
+// Repeating these methods has the same effect on the server as doing them once
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+func isIdempotentRequest(req *http.Request) bool {
+	return slices.Contains(idempotentMethods, req.Method) ||
+		req.Header.Get("Idempotency-Key") != "" ||
+		isIdempotentContext(req.Context())
+}
+
+func isRetryableError(err error) bool {
+	if os.IsTimeout(err) {
+		return true
+	}
+
+	var sysErr *os.SyscallError
+	if errors.As(err, &sysErr) {
+		return sysErr.Err == syscall.ECONNRESET
+	}
+	return false
+}
+
+// A 429 response means the request was rejected before being processed, so it's safe to be
+// retried even if the request is not idempotent
+func (p RetryPolicy) shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		return isRetryableError(err) && (p.RetryNonIdempotent || isIdempotentRequest(req))
+	}
+
+	if !slices.Contains(p.StatusCodes, res.StatusCode) {
+		return false
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests || p.RetryNonIdempotent || isIdempotentRequest(req)
+}
+
+// Retry-After may be either a number of seconds or an HTTP date
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if wait, ok := parseRetryAfter(resp); ok {
+		// Don't let the server hold the client for longer than configured
+		if p.MaxBackoff > 0 {
+			wait = min(wait, p.MaxBackoff)
+		}
+		return wait
+	}
+
+	wait := DefaultBackoff(p.BaseBackoff, p.MaxBackoff, attempt, nil)
+	if p.Jitter && wait > 0 {
+		// "Equal jitter": keep half of the wait and randomize the rest
+		wait = wait/2 + rand.N(wait/2+1)
+	}
+	return wait
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+func drainAndClose(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+}
+
 func (r *ClientRetryer) RoundTrip(req *http.Request) (*http.Response, error) {
-	waitBeforeRetry := 100 * time.Millisecond
+	policy := DefaultRetryPolicy()
+	if r.Policy != nil {
+		policy = r.Policy()
+	}
+	attempts := max(policy.MaxAttempts, 1)
+
 	var res *http.Response
 	var err error
 	if req.Body != nil {
 		defer req.Body.Close()
 	}
-	for i := 0; i < r.attempts; i++ {
+	// Set in the span of the request, if it's traced by ClientTracer
+	span := trace.SpanFromContext(req.Context())
+	for i := 0; i < attempts; i++ {
 		reqCopy := r.cloneRequest(req)
 		res, err = r.Transport.RoundTrip(reqCopy)
 
-		if err != nil {
-			var sysErr *os.SyscallError
-
-			if os.IsTimeout(err) {
-				logger().Infow("\n\n\nRequest timeout, retrying...\n\n\n", "attempt", i+1, "")
-				time.Sleep(waitBeforeRetry)
-				waitBeforeRetry = waitBeforeRetry * 2
-				continue
-			}
-
-			if errors.As(err, &sysErr) {
-				if sysErr.Err == syscall.ECONNRESET {
-					logger().Infow("\n\n\nConn reset by peer! THIS IS A SERVER PROBLEM!!!\n\n\n", "attempt", i+1, "")
-					time.Sleep(waitBeforeRetry)
-					waitBeforeRetry = waitBeforeRetry * 2
-					continue
-				}
-			}
+		if i == attempts-1 || !policy.shouldRetry(req, res, err) {
+			span.SetAttributes(tracing.AttrRetryCount.Int(i))
 			return res, err
 		}
-		if res.StatusCode >= 500 {
-			logger().Infow("\n\n\nServer responded with fail, retrying...\n\n\n", "attempt", i+1, "status code", res.StatusCode, "")
-			time.Sleep(waitBeforeRetry)
-			waitBeforeRetry = waitBeforeRetry * 2
-			continue
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", i+1)))
+
+		wait := policy.backoff(i, res)
+		if err != nil {
+			logger().Infow("Request failed, retrying", "attempt", i+1, "method", req.Method, "url", req.URL.String(), "wait", wait, "error", err)
+		} else {
+			logger().Infow("Server responded with transient failure, retrying", "attempt", i+1, "method", req.Method, "url", req.URL.String(), "wait", wait, "status code", res.StatusCode)
+			drainAndClose(res)
 		}
 
-		return res, err
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
 	}
 
 	return res, err
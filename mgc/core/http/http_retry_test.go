@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type statusTransport struct {
+	statuses []int
+	headers  http.Header
+	calls    int
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status := t.statuses[min(t.calls, len(t.statuses)-1)]
+	t.calls++
+	return &http.Response{StatusCode: status, Header: t.headers, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func noWaitPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseBackoff = 0
+	policy.MaxBackoff = 0
+	return policy
+}
+
+func TestClientRetryer(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		ctx           context.Context
+		policy        func(p *RetryPolicy)
+		statuses      []int
+		expectedCalls int
+		expectedCode  int
+	}{
+		{
+			name:          "GET retries transient failure",
+			method:        http.MethodGet,
+			statuses:      []int{502, 503, 200},
+			expectedCalls: 3,
+			expectedCode:  200,
+		},
+		{
+			name:          "GET gives up after max attempts",
+			method:        http.MethodGet,
+			policy:        func(p *RetryPolicy) { p.MaxAttempts = 2 },
+			statuses:      []int{500},
+			expectedCalls: 2,
+			expectedCode:  500,
+		},
+		{
+			name:          "status not in policy is not retried",
+			method:        http.MethodGet,
+			policy:        func(p *RetryPolicy) { p.StatusCodes = []int{503} },
+			statuses:      []int{500, 200},
+			expectedCalls: 1,
+			expectedCode:  500,
+		},
+		{
+			name:          "POST is not retried",
+			method:        http.MethodPost,
+			statuses:      []int{502, 200},
+			expectedCalls: 1,
+			expectedCode:  502,
+		},
+		{
+			name:          "POST is retried on 429",
+			method:        http.MethodPost,
+			statuses:      []int{429, 200},
+			expectedCalls: 2,
+			expectedCode:  200,
+		},
+		{
+			name:          "POST is retried if allowed by policy",
+			method:        http.MethodPost,
+			policy:        func(p *RetryPolicy) { p.RetryNonIdempotent = true },
+			statuses:      []int{502, 200},
+			expectedCalls: 2,
+			expectedCode:  200,
+		},
+		{
+			name:          "POST is retried with idempotent context",
+			method:        http.MethodPost,
+			ctx:           NewIdempotentContext(context.Background()),
+			statuses:      []int{502, 200},
+			expectedCalls: 2,
+			expectedCode:  200,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := noWaitPolicy()
+			if tc.policy != nil {
+				tc.policy(&policy)
+			}
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			transport := &statusTransport{statuses: tc.statuses}
+			retryer := NewClientRetryerWithPolicy(transport, func() RetryPolicy { return policy })
+
+			req, _ := http.NewRequestWithContext(ctx, tc.method, "http://localhost/", nil)
+			res, err := retryer.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if transport.calls != tc.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tc.expectedCalls, transport.calls)
+			}
+			if res.StatusCode != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.BaseBackoff = 100 * time.Millisecond
+	policy.MaxBackoff = 5 * time.Second
+
+	for attempt := 0; attempt < 6; attempt++ {
+		expected := DefaultBackoff(policy.BaseBackoff, policy.MaxBackoff, attempt, nil)
+		wait := policy.backoff(attempt, nil)
+		if wait < expected/2 || wait > expected {
+			t.Errorf("attempt %d: expected jittered wait between %v and %v, got %v", attempt, expected/2, expected, wait)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	if wait := policy.backoff(0, resp); wait != 3*time.Second {
+		t.Errorf("expected Retry-After of 3s to be respected, got %v", wait)
+	}
+
+	resp.Header.Set("Retry-After", "3600")
+	if wait := policy.backoff(0, resp); wait != policy.MaxBackoff {
+		t.Errorf("expected Retry-After to be capped at %v, got %v", policy.MaxBackoff, wait)
+	}
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if wait := policy.backoff(0, resp); wait != 0 {
+		t.Errorf("expected past Retry-After date to not wait, got %v", wait)
+	}
+}
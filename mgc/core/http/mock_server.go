@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// Sends every request to the server at MGC_MOCK_SERVER_URL instead, keeping the path and query.
+// Used with 'mgc dev mock-server' to test scripts without accessing Magalu Cloud
+const mockServerEnvVar = "MGC_MOCK_SERVER_URL"
+
+type ClientMockServerRedirector struct {
+	Transport http.RoundTripper
+	Server    *url.URL
+}
+
+func NewClientMockServerRedirector(transport http.RoundTripper, server *url.URL) *ClientMockServerRedirector {
+	return &ClientMockServerRedirector{
+		Transport: transport,
+		Server:    server,
+	}
+}
+
+func (t *ClientMockServerRedirector) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.Server.Scheme
+	req.URL.Host = t.Server.Host
+	req.Host = t.Server.Host
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
+
+// Wraps the transport with a ClientMockServerRedirector if MGC_MOCK_SERVER_URL is set
+func NewMockServerTransportFromEnv(transport http.RoundTripper) http.RoundTripper {
+	value := os.Getenv(mockServerEnvVar)
+	if value == "" {
+		return transport
+	}
+
+	server, err := url.Parse(value)
+	if err != nil || server.Host == "" {
+		logger().Warnw("ignored invalid mock server URL", "env", mockServerEnvVar, "value", value, "error", err)
+		return transport
+	}
+	return NewClientMockServerRedirector(transport, server)
+}
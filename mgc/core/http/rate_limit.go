@@ -0,0 +1,145 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty" jsonschema:"description=Maximum sustained requests per second. Zero means unlimited,minimum=0"`
+	Burst             int     `json:"burst,omitempty" jsonschema:"description=Maximum requests sent at once before being limited by requestsPerSecond. Defaults to 1,minimum=0"`
+}
+
+// RateLimitConfig limits the requests sent by the client. Every host has its own budget, using
+// the 'Default' limit unless a more specific one is given in 'Hosts'. Its keys are either a host
+// (ie: "api.magalu.cloud") or a host followed by a path prefix to limit a single product
+// (ie: "api.magalu.cloud/br-se1/compute"). The longest matching key is used.
+type RateLimitConfig struct {
+	Default RateLimit            `json:"default,omitempty" jsonschema:"description=Limit of each host without a specific one"`
+	Hosts   map[string]RateLimit `json:"hosts,omitempty" jsonschema:"description=Limits for specific hosts or host/path prefixes (products)\\, overriding the default one"`
+}
+
+func (c RateLimitConfig) limitFor(req *http.Request) (key string, limit RateLimit) {
+	target := strings.ToLower(req.URL.Host + req.URL.Path)
+	key, limit = req.URL.Host, c.Default
+	matched := ""
+	for prefix, hostLimit := range c.Hosts {
+		if len(prefix) > len(matched) && strings.HasPrefix(target, strings.ToLower(strings.TrimSuffix(prefix, "/"))) {
+			matched = prefix
+			key, limit = prefix, hostLimit
+		}
+	}
+	return
+}
+
+// tokenBucket holds up to 'burst' tokens, refilled at 'rate' tokens per second.
+// Each request takes one token, waiting for it if none is available
+type tokenBucket struct {
+	mutex  sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	b := &tokenBucket{last: time.Now()}
+	b.setLimit(limit)
+	b.tokens = b.burst
+	return b
+}
+
+// Must be called with the mutex locked, or before the bucket is shared
+func (b *tokenBucket) setLimit(limit RateLimit) {
+	b.rate = limit.RequestsPerSecond
+	b.burst = float64(max(limit.Burst, 1))
+}
+
+func (b *tokenBucket) reserve(limit RateLimit) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.setLimit(limit)
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	// Tokens may go negative, queueing the following requests behind this one
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// RateLimiter is shared by all clients of an SDK instance, so concurrent executors
+// (and their parallel workers) draw from the same request budget
+type RateLimiter struct {
+	config  func() RateLimitConfig
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func NewRateLimiter(config func() RateLimitConfig) *RateLimiter {
+	return &RateLimiter{config: config, buckets: map[string]*tokenBucket{}}
+}
+
+func (l *RateLimiter) bucket(key string, limit RateLimit) *tokenBucket {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = newTokenBucket(limit)
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// Wait blocks until the request may be sent according to the configured limits, or the context is done
+func (l *RateLimiter) Wait(ctx context.Context, req *http.Request) error {
+	if l == nil || l.config == nil {
+		return nil
+	}
+
+	key, limit := l.config().limitFor(req)
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	wait := l.bucket(key, limit).reserve(limit)
+	if wait <= 0 {
+		return nil
+	}
+
+	logger().Debugw("Rate limited request", "key", key, "wait", wait)
+	return sleepContext(ctx, wait)
+}
+
+type ClientRateLimiter struct {
+	Transport http.RoundTripper
+	Limiter   *RateLimiter
+}
+
+func NewClientRateLimiter(transport http.RoundTripper, limiter *RateLimiter) *ClientRateLimiter {
+	return &ClientRateLimiter{
+		Transport: transport,
+		Limiter:   limiter,
+	}
+}
+
+func (t *ClientRateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Limiter.Wait(req.Context(), req); err != nil {
+		return nil, err
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitConfigLimitFor(t *testing.T) {
+	config := RateLimitConfig{
+		Default: RateLimit{RequestsPerSecond: 10},
+		Hosts: map[string]RateLimit{
+			"api.magalu.cloud":                 {RequestsPerSecond: 5},
+			"api.magalu.cloud/br-se1/compute/": {RequestsPerSecond: 1},
+		},
+	}
+
+	testCases := []struct {
+		url         string
+		expectedKey string
+		expectedRPS float64
+	}{
+		{"https://other.host/some/path", "other.host", 10},
+		{"https://api.magalu.cloud/br-se1/network/v0/vpcs", "api.magalu.cloud", 5},
+		{"https://api.magalu.cloud/br-se1/compute/v1/instances", "api.magalu.cloud/br-se1/compute/", 1},
+		{"https://API.magalu.cloud/br-se1/Compute/v1/instances", "api.magalu.cloud/br-se1/compute/", 1},
+	}
+
+	for _, tc := range testCases {
+		req, _ := http.NewRequest(http.MethodGet, tc.url, nil)
+		key, limit := config.limitFor(req)
+		if key != tc.expectedKey || limit.RequestsPerSecond != tc.expectedRPS {
+			t.Errorf("%s: expected %q with %v rps, got %q with %v rps", tc.url, tc.expectedKey, tc.expectedRPS, key, limit.RequestsPerSecond)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limit := RateLimit{RequestsPerSecond: 10, Burst: 2}
+	b := newTokenBucket(limit)
+
+	for i := 0; i < 2; i++ {
+		if wait := b.reserve(limit); wait != 0 {
+			t.Errorf("request %d within burst should not wait, got %v", i, wait)
+		}
+	}
+
+	// Each following request is queued 100ms after the previous one
+	first := b.reserve(limit)
+	second := b.reserve(limit)
+	if first <= 0 || first > 100*time.Millisecond {
+		t.Errorf("expected first exceeding request to wait up to 100ms, got %v", first)
+	}
+	if second-first < 90*time.Millisecond {
+		t.Errorf("expected second exceeding request to wait 100ms more than the first, got %v and %v", first, second)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	limiter := NewRateLimiter(func() RateLimitConfig { return RateLimitConfig{} })
+	req, _ := http.NewRequest(http.MethodGet, "https://api.magalu.cloud/", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(ctx, req); err != nil {
+			t.Fatalf("unlimited host should never wait, got %v", err)
+		}
+	}
+}
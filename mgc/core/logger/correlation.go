@@ -0,0 +1,13 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Identifies the current invocation, shared by all of its log events and HTTP requests (sent as X-Request-Id),
+// so client logs can be joined with the server-side ones. Safe to be called by concurrent requests
+var CorrelationID = sync.OnceValue(func() string {
+	return uuid.New().String()
+})
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 )
 
 type ProcessStatus int
@@ -31,6 +33,7 @@ func processChannel[I any, O any](
 	inputChan <-chan I,
 	outputChan chan<- O,
 	processor Processor[I, O],
+	stage *stage,
 ) (finishedInput bool) {
 	logger := FromContext(ctx)
 
@@ -42,7 +45,11 @@ func processChannel[I any, O any](
 		default:
 		}
 
-		output, status := processor(ctx, input)
+		var output O
+		var status ProcessStatus
+		stage.process(ctx, func(ctx context.Context) {
+			output, status = processor(ctx, input)
+		})
 		switch status {
 		case ProcessSkip:
 			logger.Debugw("skip", "input", input, "output", output)
@@ -127,13 +134,16 @@ func Process[I any, O any](
 		"outputChan", fmt.Sprintf("%#v", outputChan),
 	)
 	ctx = NewContext(ctx, logger)
+	ctx, stage := startStage(ctx, "Process", false)
 
 	generator := func() {
+		finishedInput := false
 		defer func() {
 			logger.Info("closing output channel")
 			close(ch)
+			stage.end(finishedInput)
 		}()
-		finishedInput := processChannel[I, O](ctx, inputChan, ch, processor)
+		finishedInput = processChannel[I, O](ctx, inputChan, ch, processor, stage)
 		finalizeProcess(ctx, ch, finalize, finishedInput)
 	}
 
@@ -223,6 +233,7 @@ func ParallelProcess[I any, O any](
 		"outputChan", fmt.Sprintf("%#v", ch),
 	)
 	ctx = NewContext(ctx, logger)
+	ctx, stage := startStage(ctx, "ParallelProcess", true, tracing.AttrPipelineWorkers.Int(maxParallelProcessors))
 
 	finishedInput := true
 
@@ -232,7 +243,7 @@ func ParallelProcess[I any, O any](
 	for i := 0; i < maxParallelProcessors; i++ {
 		workerCtx := NewContext(ctx, innerLogger.With("worker", i))
 		go func() {
-			fi := processChannel[I, O](workerCtx, inputChan, ch, processor)
+			fi := processChannel[I, O](workerCtx, inputChan, ch, processor, stage)
 			if !fi {
 				finishedInput = false
 			}
@@ -244,6 +255,7 @@ func ParallelProcess[I any, O any](
 		defer func() {
 			logger.Info("closing output channel")
 			close(ch)
+			stage.end(finishedInput)
 		}()
 		wg.Wait()
 		finalizeProcess(ctx, ch, finalize, finishedInput)
@@ -0,0 +1,61 @@
+package pipeline
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextStageKey string
+
+var ctxStageKey contextStageKey = "github.com/MagaluCloud/magalu/mgc/core/pipeline/stage"
+
+// Name the stage created with the returned context, such as "upload parts". It's used in its tracing spans,
+// which default to the function creating the stage, ie: "ParallelProcess"
+func WithStageName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ctxStageKey, name)
+}
+
+// A span covering the whole stage, from its creation until its output channel is closed.
+// If itemSpans, each processed item also gets its own span
+type stage struct {
+	name      string
+	span      trace.Span
+	itemSpans bool
+	items     atomic.Int64
+}
+
+func startStage(ctx context.Context, fallbackName string, itemSpans bool, attrs ...attribute.KeyValue) (context.Context, *stage) {
+	name, _ := ctx.Value(ctxStageKey).(string)
+	if name == "" {
+		name = fallbackName
+	}
+	// The name is only for this stage, not the ones created by its processors
+	ctx = WithStageName(ctx, "")
+
+	ctx, span := tracing.Start(ctx, name, append(attrs, tracing.AttrPipelineStage.String(name))...)
+	return ctx, &stage{name: name, span: span, itemSpans: itemSpans}
+}
+
+func (s *stage) process(ctx context.Context, process func(ctx context.Context)) {
+	s.items.Add(1)
+	if !s.itemSpans {
+		process(ctx)
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, s.name+" item")
+	defer span.End()
+	process(ctx)
+}
+
+func (s *stage) end(finishedInput bool) {
+	s.span.SetAttributes(
+		tracing.AttrPipelineItems.Int64(s.items.Load()),
+		tracing.AttrPipelineFinished.Bool(finishedInput),
+	)
+	s.span.End()
+}
@@ -0,0 +1,104 @@
+package profile_manager
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+const archiveVersion = 1
+
+// Archive holds all the files of a profile, so it may be moved to another MGC dir.
+// File names are relative to the profile directory and always use '/' as separator
+type Archive struct {
+	Version int               `json:"version"`
+	Name    string            `json:"name"`
+	Files   map[string][]byte `json:"files"`
+}
+
+// ArchiveFilter decides if the file should be included when exporting or cloning a profile
+type ArchiveFilter func(name string) bool
+
+var localFiles = map[string]bool{}
+
+// RegisterLocalFile marks a profile file as bound to this machine, such as caches or state of
+// pending operations, so it's never exported or cloned. It must be called during initialization
+func RegisterLocalFile(name string) {
+	localFiles[name] = true
+}
+
+func IsLocalFile(name string) bool {
+	return localFiles[name]
+}
+
+func (m *ProfileManager) exists(p *Profile) bool {
+	ok, err := afero.DirExists(m.fs, m.buildPath(p.Name))
+	return err == nil && ok
+}
+
+// Export reads all the files of the profile accepted by the filter, except for the local ones
+// (see RegisterLocalFile). A nil filter accepts all files
+func (m *ProfileManager) Export(p *Profile, filter ArchiveFilter) (*Archive, error) {
+	if !m.exists(p) {
+		return nil, errorProfileNotFound
+	}
+
+	a := &Archive{Version: archiveVersion, Name: p.Name, Files: map[string][]byte{}}
+	err := m.walk(p.Name, func(name string) error {
+		name = filepath.ToSlash(name)
+		if IsLocalFile(name) || (filter != nil && !filter(name)) {
+			return nil
+		}
+
+		data, err := p.Read(name)
+		if err != nil {
+			return err
+		}
+		a.Files[name] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Import creates a new profile with the archive files. If 'name' is empty, the archive name is used.
+// The profile is removed if any of the files can't be written, so it's never left half-imported
+func (m *ProfileManager) Import(name string, a *Archive) (p *Profile, err error) {
+	if a.Version != archiveVersion {
+		return nil, fmt.Errorf("%w: %d", errorUnsupportedArchiveVersion, a.Version)
+	}
+	if name == "" {
+		name = a.Name
+	}
+
+	if p, err = m.Create(name); err != nil {
+		return
+	}
+
+	for fileName, data := range a.Files {
+		if err = p.Write(fileName, data); err != nil {
+			_ = m.remove(p.Name)
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Clone creates a new profile named 'dstName' with the files of 'src' accepted by the filter
+func (m *ProfileManager) Clone(src *Profile, dstName string, filter ArchiveFilter) (*Profile, error) {
+	if src.Name == dstName {
+		return nil, errorCopyToSelf
+	}
+
+	a, err := m.Export(src, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Import(dstName, a)
+}
@@ -0,0 +1,114 @@
+package profile_manager
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/spf13/afero"
+)
+
+func newArchiveTestManager(t *testing.T) *ProfileManager {
+	fs := afero.NewMemMapFs()
+	m := &ProfileManager{"/mgc", fs}
+	err := utils.PrepareFs(fs, []utils.TestFsEntry{
+		{Path: "/mgc/src/cli.yaml", Mode: utils.FILE_PERMISSION, Data: []byte("region: br-se1\n")},
+		{Path: "/mgc/src/auth.yaml", Mode: utils.FILE_PERMISSION, Data: []byte("access_token: secret\n")},
+		{Path: "/mgc/src/nested/file", Mode: utils.FILE_PERMISSION, Data: []byte("nested")},
+		{Path: "/mgc/src/local-cache.yaml", Mode: utils.FILE_PERMISSION, Data: []byte("cached: true\n")},
+	})
+	if err != nil {
+		t.Fatalf("could not prepare provided FS: %s", err.Error())
+	}
+	return m
+}
+
+func TestProfileManagerExport(t *testing.T) {
+	RegisterLocalFile("local-cache.yaml")
+	t.Cleanup(func() { delete(localFiles, "local-cache.yaml") })
+
+	m := newArchiveTestManager(t)
+	src, _ := m.Get("src")
+
+	a, err := m.Export(src, func(name string) bool { return name != "auth.yaml" })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if a.Name != "src" || a.Version != archiveVersion {
+		t.Errorf("unexpected archive header: %q version %d", a.Name, a.Version)
+	}
+	expected := map[string]string{"cli.yaml": "region: br-se1\n", "nested/file": "nested"}
+	if len(a.Files) != len(expected) {
+		t.Errorf("expected %d files, got %d: %v", len(expected), len(a.Files), a.Files)
+	}
+	for name, data := range expected {
+		if string(a.Files[name]) != data {
+			t.Errorf("%s: expected %q, got %q", name, data, a.Files[name])
+		}
+	}
+
+	missing, _ := m.Get("missing")
+	if _, err = m.Export(missing, nil); !errors.Is(err, errorProfileNotFound) {
+		t.Errorf("expected errorProfileNotFound, got %#v", err)
+	}
+}
+
+func TestProfileManagerImport(t *testing.T) {
+	m := newArchiveTestManager(t)
+	a := &Archive{Version: archiveVersion, Name: "exported", Files: map[string][]byte{
+		"cli.yaml":      []byte("region: br-ne1\n"),
+		"../escape.txt": []byte("should stay inside"),
+	}}
+
+	p, err := m.Import("", a)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if p.Name != "exported" {
+		t.Errorf("expected name from archive, got %q", p.Name)
+	}
+	data, err := afero.ReadFile(m.fs, path.Join("/mgc", "exported", "cli.yaml"))
+	if err != nil || string(data) != "region: br-ne1\n" {
+		t.Errorf("unexpected cli.yaml %q: %v", data, err)
+	}
+	if _, err = afero.ReadFile(m.fs, "/mgc/escape.txt"); err == nil {
+		t.Errorf("archive file escaped the profile directory")
+	}
+
+	if _, err = m.Import("exported", a); !errors.Is(err, errorProfileAlreadyExists) {
+		t.Errorf("expected errorProfileAlreadyExists, got %#v", err)
+	}
+	if _, err = m.Import("other", &Archive{Version: 99}); !errors.Is(err, errorUnsupportedArchiveVersion) {
+		t.Errorf("expected errorUnsupportedArchiveVersion, got %#v", err)
+	}
+}
+
+func TestProfileManagerClone(t *testing.T) {
+	RegisterLocalFile("local-cache.yaml")
+	t.Cleanup(func() { delete(localFiles, "local-cache.yaml") })
+
+	m := newArchiveTestManager(t)
+	src, _ := m.Get("src")
+
+	dst, err := m.Clone(src, "dst", func(name string) bool { return name != "auth.yaml" })
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if data, err := dst.Read("cli.yaml"); err != nil || string(data) != "region: br-se1\n" {
+		t.Errorf("unexpected cli.yaml %q: %v", data, err)
+	}
+	if data, err := dst.Read("nested/file"); err != nil || string(data) != "nested" {
+		t.Errorf("unexpected nested/file %q: %v", data, err)
+	}
+	if _, err := dst.Read("auth.yaml"); err == nil {
+		t.Errorf("filtered file was cloned")
+	}
+	if _, err := dst.Read("local-cache.yaml"); err == nil {
+		t.Errorf("local file was cloned")
+	}
+
+	if _, err = m.Clone(src, "src", nil); !errors.Is(err, errorCopyToSelf) {
+		t.Errorf("expected errorCopyToSelf, got %#v", err)
+	}
+}
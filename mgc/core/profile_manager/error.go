@@ -10,3 +10,5 @@ var errorInvalidName = errors.New("name should only contain alphanumric characte
 var errorProfileAlreadyExists = errors.New("profile already exists")
 var errorDeleteCurrentNotAllowed = errors.New("cannot delete current profile")
 var errorCopyToSelf = errors.New("cannot copy to itself")
+var errorProfileNotFound = errors.New("profile does not exist")
+var errorUnsupportedArchiveVersion = errors.New("unsupported workspace archive version")
@@ -29,7 +29,7 @@ func NewContext(parentCtx context.Context, profile *ProfileManager) context.Cont
 }
 
 func FromContext(ctx context.Context) *ProfileManager {
-	a := ctx.Value(profileKey).(*ProfileManager)
+	a, _ := ctx.Value(profileKey).(*ProfileManager)
 	return a
 }
 
@@ -89,6 +89,9 @@ func (m *ProfileManager) walk(name string, cb func(path string) error) error {
 		if err != nil {
 			return err
 		}
+		if info.IsDir() {
+			return nil
+		}
 		path = path[prefixLen:]
 		return cb(path)
 	})
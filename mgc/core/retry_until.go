@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
 )
 
@@ -18,6 +19,7 @@ type RetryUntilConfig struct {
 	MaxRetries    int           `json:"maxRetries,omitempty"`
 	Interval      time.Duration `json:"interval,omitempty"`
 	JSONPathQuery string        `json:"jsonPathQuery,omitempty"`
+	JMESPathQuery string        `json:"jmesPathQuery,omitempty"`
 	TemplateQuery string        `json:"templateQuery,omitempty"`
 }
 
@@ -27,14 +29,16 @@ func (c *RetryUntilConfig) Build() (r *RetryUntil, err error) {
 	}
 
 	var check RetryUntilCheck
-	if c.JSONPathQuery != "" && c.TemplateQuery != "" {
-		err = errors.New("cannot specify both jsonPathQuery and templateQuery")
+	if countNonEmpty(c.JSONPathQuery, c.JMESPathQuery, c.TemplateQuery) > 1 {
+		err = errors.New("cannot specify more than one of jsonPathQuery, jmesPathQuery and templateQuery")
 	} else if c.JSONPathQuery != "" {
 		check, err = NewRetryUntilCheckFromJsonPath(c.JSONPathQuery)
+	} else if c.JMESPathQuery != "" {
+		check, err = NewRetryUntilCheckFromJmesPath(c.JMESPathQuery)
 	} else if c.TemplateQuery != "" {
 		check, err = NewRetryUntilCheckFromTemplate(c.TemplateQuery)
 	} else {
-		err = errors.New("need one of jsonPathQuery or templateQuery")
+		err = errors.New("need one of jsonPathQuery, jmesPathQuery or templateQuery")
 	}
 
 	if err != nil {
@@ -72,7 +76,15 @@ func (r *RetryUntil) Run(ctx context.Context, cb RetryUntilCb) (result Result, e
 		return cb()
 	}
 
+	_, span := tracing.Start(ctx, "retry until")
+	retries := 0
+	defer func() {
+		span.SetAttributes(tracing.AttrRetryCount.Int(retries))
+		tracing.End(span, err)
+	}()
+
 	for i := 0; i < r.MaxRetries; i++ {
+		retries = i
 		result, err = cb()
 		if err != nil {
 			return result, err
@@ -115,6 +127,19 @@ func NewRetryUntilCheckFromJsonPath(expression string) (check RetryUntilCheck, e
 	return
 }
 
+func NewRetryUntilCheckFromJmesPath(expression string) (check RetryUntilCheck, err error) {
+	jpChecker, err := utils.CreateJmesPathChecker(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	check = func(ctx context.Context, value Value) (finished bool, err error) {
+		return jpChecker(value)
+	}
+
+	return
+}
+
 func NewRetryUntilCheckFromTemplate(expression string) (check RetryUntilCheck, err error) {
 	tmplChecker, err := utils.CreateTemplateChecker(expression)
 	if err != nil {
@@ -127,3 +152,12 @@ func NewRetryUntilCheckFromTemplate(expression string) (check RetryUntilCheck, e
 
 	return
 }
+
+func countNonEmpty(values ...string) (count int) {
+	for _, v := range values {
+		if v != "" {
+			count++
+		}
+	}
+	return
+}
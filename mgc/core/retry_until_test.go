@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRetryUntilConfigBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    RetryUntilConfig
+		value     Value
+		finished  bool
+		wantError bool
+	}{
+		{
+			name:     "jsonpath",
+			config:   RetryUntilConfig{JSONPathQuery: `$.status == "running"`},
+			value:    map[string]any{"status": "running"},
+			finished: true,
+		},
+		{
+			name:     "jmespath filter",
+			config:   RetryUntilConfig{JMESPathQuery: `items[?status=='running']`},
+			value:    map[string]any{"items": []any{map[string]any{"status": "running"}}},
+			finished: true,
+		},
+		{
+			name:     "jmespath empty result",
+			config:   RetryUntilConfig{JMESPathQuery: `items[?status=='running']`},
+			value:    map[string]any{"items": []any{map[string]any{"status": "stopped"}}},
+			finished: false,
+		},
+		{
+			name:     "jmespath function",
+			config:   RetryUntilConfig{JMESPathQuery: `length(items) > ` + "`1`"},
+			value:    map[string]any{"items": []any{1.0, 2.0}},
+			finished: true,
+		},
+		{
+			name:      "multiple queries",
+			config:    RetryUntilConfig{JSONPathQuery: `$.status`, JMESPathQuery: `status`},
+			wantError: true,
+		},
+		{
+			name:      "no query",
+			config:    RetryUntilConfig{},
+			wantError: true,
+		},
+		{
+			name:      "invalid jmespath",
+			config:    RetryUntilConfig{JMESPathQuery: `items[?`},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.MaxRetries = 1
+			tc.config.Interval = time.Millisecond
+			r, err := tc.config.Build()
+			if tc.wantError {
+				if err == nil {
+					t.Fatalf("expected error, got nothing")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			finished, err := r.Check(context.Background(), tc.value)
+			if err != nil {
+				t.Fatalf("unexpected check error: %v", err)
+			}
+			if finished != tc.finished {
+				t.Errorf("expected finished %v, got %v", tc.finished, finished)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 )
 
 type LinksSpecFn func(e Executor) Links
@@ -125,8 +127,11 @@ func (*SimpleExecutor) EmptyResult() Result {
 	return NewSimpleResult(ResultSource{}, nil, nil)
 }
 
-func (e *SimpleExecutor) Execute(context context.Context, parameters Parameters, configs Configs) (result Result, err error) {
-	return e.execute(e, context, parameters, configs)
+func (e *SimpleExecutor) Execute(ctx context.Context, parameters Parameters, configs Configs) (result Result, err error) {
+	ctx, span := tracing.Start(ctx, "execute "+e.Name(), tracing.AttrExecutorName.String(e.Name()))
+	defer func() { tracing.End(span, err) }()
+
+	return e.execute(e, ctx, parameters, configs)
 }
 
 var _ Executor = (*SimpleExecutor)(nil)
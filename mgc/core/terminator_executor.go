@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 )
 
 type TerminatorExecutor interface {
@@ -42,6 +44,13 @@ func (o *executeTerminatorWithCheck) ExecuteUntilTermination(context context.Con
 }
 
 func (o *executeTerminatorWithCheck) executeUntilTermination(context context.Context, parameters Parameters, configs Configs) (result Result, err error) {
+	context, span := tracing.Start(context, "wait termination "+o.Name())
+	retries := 0
+	defer func() {
+		span.SetAttributes(tracing.AttrRetryCount.Int(retries))
+		tracing.End(span, err)
+	}()
+
 	var exec func() (Result, error)
 	if tExec, ok := ExecutorAs[TerminatorExecutor](o.Unwrap()); ok {
 		exec = func() (result Result, err error) {
@@ -55,6 +64,7 @@ func (o *executeTerminatorWithCheck) executeUntilTermination(context context.Con
 	}
 
 	for i := 0; i < o.maxRetries; i++ {
+		retries = i
 		result, err = exec()
 		if err != nil {
 			return result, err
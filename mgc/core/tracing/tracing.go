@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/MagaluCloud/magalu/mgc"
+
+// Attributes set by the MGC instrumentation, besides the OpenTelemetry semantic conventions
+const (
+	AttrCorrelationID    = attribute.Key("mgc.correlation.id")
+	AttrOperationID      = attribute.Key("mgc.operation.id")
+	AttrExecutorName     = attribute.Key("mgc.executor.name")
+	AttrRetryCount       = attribute.Key("mgc.retry.count")
+	AttrPipelineItems    = attribute.Key("mgc.pipeline.items")
+	AttrPipelineStage    = attribute.Key("mgc.pipeline.stage")
+	AttrPipelineWorkers  = attribute.Key("mgc.pipeline.workers")
+	AttrPipelineFinished = attribute.Key("mgc.pipeline.finished_input")
+)
+
+// The standard OpenTelemetry variables enabling the OTLP exporter, ie: OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4318
+var otlpEnvVars = []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"}
+
+// Spans are only recorded once Setup() enabled an exporter, otherwise the tracer does nothing
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Ends the span, recording the error if not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func isOTLPEnabled() bool {
+	for _, name := range otlpEnvVars {
+		if os.Getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.file.Close())
+}
+
+func newFileExporter(path string) (sdktrace.SpanExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create trace file: %w", err)
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileExporter{exporter, f}, nil
+}
+
+// Enables the exporters: spans are written as JSON, one per line, to traceFile if not empty, and sent with OTLP
+// if the OpenTelemetry environment variables are set. The returned function flushes the spans and must be called before exiting
+func Setup(ctx context.Context, traceFile string, version string) (shutdown func(context.Context) error, err error) {
+	shutdown = func(context.Context) error { return nil }
+
+	var options []sdktrace.TracerProviderOption
+	if traceFile != "" {
+		exporter, err := newFileExporter(traceFile)
+		if err != nil {
+			return shutdown, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	if isOTLPEnabled() {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return shutdown, fmt.Errorf("unable to create OTLP exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	if len(options) == 0 {
+		return shutdown, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName("mgc"),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return shutdown, err
+	}
+	options = append(options, sdktrace.WithResource(res))
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
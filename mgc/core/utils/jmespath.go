@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"github.com/jmespath/go-jmespath"
+)
+
+// Unlike jsonpath, JMESPath expressions may project, filter using functions, sort and aggregate.
+// See https://jmespath.org/specification.html
+func NewJmesPath(expression string) (*jmespath.JMESPath, error) {
+	return jmespath.Compile(expression)
+}
+
+func GetJmesPath(expression string, document any) (result any, err error) {
+	jp, err := NewJmesPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	return jp.Search(document)
+}
+
+// Follows JMESPath truthiness: false, null and empty lists, objects and strings are false, everything else is true
+func isJmesPathTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	case map[string]any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func CreateJmesPathChecker(expression string) (checker func(document any) (bool, error), err error) {
+	jp, err := NewJmesPath(expression)
+	if err != nil {
+		return nil, err
+	}
+	return func(value any) (bool, error) {
+		v, err := jp.Search(value)
+		if err != nil {
+			return false, err
+		}
+		return isJmesPathTruthy(v), nil
+	}, nil
+}
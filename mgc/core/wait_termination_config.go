@@ -14,8 +14,10 @@ type WaitTerminationConfig struct {
 	MaxRetries         int           `json:"maxRetries,omitempty"`
 	Interval           time.Duration `json:"interval,omitempty"`
 	JSONPathQuery      string        `json:"jsonPathQuery,omitempty"`
+	JMESPathQuery      string        `json:"jmesPathQuery,omitempty"`
 	TemplateQuery      string        `json:"templateQuery,omitempty"`
 	ErrorJSONPathQuery string        `json:"errorJsonPathQuery,omitempty"`
+	ErrorJMESPathQuery string        `json:"errorJmesPathQuery,omitempty"`
 	ErrorTemplateQuery string        `json:"errorTemplateQuery,omitempty"`
 }
 
@@ -32,23 +34,30 @@ func (c *WaitTerminationConfig) Build(exec Executor, getDocument func(result Res
 	}
 
 	var expChecker func(value any) (bool, error)
-	if c.JSONPathQuery != "" && c.TemplateQuery != "" {
-		err = errors.New("cannot specify both jsonPathQuery and templateQuery")
+	if countNonEmpty(c.JSONPathQuery, c.JMESPathQuery, c.TemplateQuery) > 1 {
+		err = errors.New("cannot specify more than one of jsonPathQuery, jmesPathQuery and templateQuery")
 	} else if c.JSONPathQuery != "" {
 		expChecker, err = utils.CreateJsonPathChecker(c.JSONPathQuery)
+	} else if c.JMESPathQuery != "" {
+		expChecker, err = utils.CreateJmesPathChecker(c.JMESPathQuery)
 	} else if c.TemplateQuery != "" {
 		expChecker, err = utils.CreateTemplateChecker(c.TemplateQuery)
 	} else {
-		err = errors.New("need one of jsonPathQuery or templateQuery")
+		err = errors.New("need one of jsonPathQuery, jmesPathQuery or templateQuery")
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	var errorChecker func(value any) (bool, error)
-	if c.ErrorJSONPathQuery != "" && c.TemplateQuery != "" {
-		err = errors.New("cannot specify both errorJsonPathQuery and errorTemplateQuery")
+	if countNonEmpty(c.ErrorJSONPathQuery, c.ErrorJMESPathQuery, c.ErrorTemplateQuery) > 1 {
+		err = errors.New("cannot specify more than one of errorJsonPathQuery, errorJmesPathQuery and errorTemplateQuery")
 	} else if c.ErrorJSONPathQuery != "" {
 		errorChecker, err = utils.CreateJsonPathChecker(c.ErrorJSONPathQuery)
+	} else if c.ErrorJMESPathQuery != "" {
+		errorChecker, err = utils.CreateJmesPathChecker(c.ErrorJMESPathQuery)
 	} else if c.ErrorTemplateQuery != "" {
-		errorChecker, err = utils.CreateTemplateChecker(c.ErrorJSONPathQuery)
+		errorChecker, err = utils.CreateTemplateChecker(c.ErrorTemplateQuery)
 	}
 
 	if err != nil {
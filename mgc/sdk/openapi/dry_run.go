@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+// All operations only build their request in dry-run mode, it's never sent
+func (o *operation) SupportsDryRun() bool {
+	return true
+}
+
+func (o *operation) planRequest(
+	plan *core.DryRunPlan,
+	source core.ResultSource,
+	req *http.Request,
+	requestBody core.Value,
+) (core.Result, error) {
+	var rawBody []byte
+	if req.Body != nil {
+		defer req.Body.Close()
+		var err error
+		if rawBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	dryRunRequest := mgcHttpPkg.NewDryRunRequest(req, requestBody, rawBody)
+	plan.Add(core.DryRunAction{Action: "request", Request: dryRunRequest})
+
+	schema, err := mgcSchemaPkg.SchemaFromType[core.DryRunRequest]()
+	if err != nil {
+		return nil, err
+	}
+	return core.NewSimpleResult(source, schema, dryRunRequest), nil
+}
+
+var _ core.DryRunExecutor = (*operation)(nil)
@@ -14,6 +14,7 @@ import (
 	mgcAuthPkg "github.com/MagaluCloud/magalu/mgc/core/auth"
 	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
 	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/tracing"
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
 	"github.com/MagaluCloud/magalu/mgc/sdk/openapi/transform"
 	"go.uber.org/zap"
@@ -45,6 +46,7 @@ type operation struct {
 	transformResult     func(value any) (any, error)
 	extensionPrefix     *string
 	outputFlag          string
+	pagination          *paginationSpec
 	server              *server
 	parameters          *parameters
 	requestBody         requestBody
@@ -86,9 +88,28 @@ func newOperation(
 	}
 	op.SimpleDescriptor.Spec.Scopes = collectAllScopes(op)
 
+	pagination, err := getPaginationExtension(extensionPrefix, desc.op.Extensions)
+	if err != nil {
+		logger.Warnw("ignored broken pagination", "error", err)
+	}
+	op.pagination = pagination
+
 	return op
 }
 
+// Parameters are given by their (possibly renamed) external names
+func (o *operation) externalParameterName(internalName string) string {
+	name := internalName
+	_, _ = o.forEachParameterName(func(externalName, internal, location string) (run bool, err error) {
+		if internal == internalName {
+			name = externalName
+			return false, nil
+		}
+		return true, nil
+	})
+	return name
+}
+
 func collectAllScopes(o *operation) (allScopes core.Scopes) {
 	_, _ = o.forEachSecurityRequirement(func(_ string, scopes []string) (run bool, err error) {
 		for _, scope := range scopes {
@@ -454,9 +475,12 @@ func (o *operation) buildRequestFromParams(
 	paramValues core.Parameters,
 	configs core.Configs,
 ) (req *http.Request, requestBody core.Value, err error) {
-	url, err := o.getRequestUrl(paramValues, configs)
-	if err != nil {
-		return
+	url := getPageUrl(ctx)
+	if url == "" {
+		url, err = o.getRequestUrl(paramValues, configs)
+		if err != nil {
+			return
+		}
 	}
 
 	mimeType, size, reader, requestBody, err := o.requestBody.create(paramValues)
@@ -581,7 +605,11 @@ func (o *operation) setSecurityHeader(ctx context.Context, paramValues core.Para
 		default:
 			accessToken, err := auth.AccessToken(ctx)
 			if err != nil {
-				return err
+				// Planned requests are shown with their credentials redacted, don't require a login
+				if core.DryRunPlanFromContext(ctx) == nil {
+					return err
+				}
+				accessToken = "<access-token>"
 			}
 			req.Header.Set("Authorization", "Bearer "+accessToken)
 		}
@@ -590,6 +618,12 @@ func (o *operation) setSecurityHeader(ctx context.Context, paramValues core.Para
 	return nil
 }
 
+// Operations with non-idempotent methods (ie: POST) may declare they're safe to be retried
+func (o *operation) isIdempotent() bool {
+	b, _ := getExtensionBool(o.extensionPrefix, "idempotent", o.operation.Extensions, false)
+	return b
+}
+
 // TODO: refactor this closer to the client that comes from a context
 func (o *operation) createHttpRequest(
 	ctx context.Context,
@@ -597,6 +631,10 @@ func (o *operation) createHttpRequest(
 	paramValues core.Parameters,
 	configs core.Configs,
 ) (req *http.Request, requestBody core.Value, err error) {
+	if o.isIdempotent() {
+		ctx = mgcHttpPkg.NewIdempotentContext(ctx)
+	}
+
 	req, requestBody, err = o.buildRequestFromParams(ctx, paramValues, configs)
 	if err != nil {
 		return
@@ -660,6 +698,19 @@ func (o *operation) Execute(
 	parameters core.Parameters,
 	configs core.Configs,
 ) (result core.Result, err error) {
+	ctx, span := tracing.Start(
+		ctx,
+		"operation "+o.operation.OperationID,
+		tracing.AttrOperationID.String(o.operation.OperationID),
+		tracing.AttrExecutorName.String(o.Name()),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	dryRunPlan := core.DryRunPlanFromContext(ctx)
+	if o.pagination != nil && GetAllPagesFlag(ctx) && dryRunPlan == nil {
+		return o.executeAllPages(ctx, parameters, configs)
+	}
+
 	isRawOuput := GetRawOutputFlag(ctx)
 	var spinnerInfo pterm.SpinnerPrinter
 	if !isRawOuput {
@@ -745,6 +796,10 @@ func (o *operation) Execute(
 		logger.Warnw("failed to create HTTP request", "error", err)
 		return nil, err
 	}
+	if dryRunPlan != nil {
+		logger.Debug("dry-run, HTTP request will not be executed")
+		return o.planRequest(dryRunPlan, source, req, requestBody)
+	}
 	logger.Debug("created HTTP request, now execute it...")
 	resp, err := client.Do(req)
 
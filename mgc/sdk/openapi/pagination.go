@@ -0,0 +1,394 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+const (
+	paginationOffset = "offset"
+	paginationToken  = "token"
+	paginationLink   = "link"
+)
+
+// paginationSpec is the value of the x-mgc-pagination extension, describing how to fetch the
+// following pages of a list operation. Parameter names are the OpenAPI ones, while 'items' and
+// 'nextToken' are dot-separated paths in the result (ie: "meta.page.next")
+type paginationSpec struct {
+	Strategy string `json:"strategy"`
+	// Path to the list in the result, empty if the result itself is the list
+	Items string `json:"items,omitempty"`
+
+	LimitParameter  string `json:"limitParameter,omitempty"`
+	OffsetParameter string `json:"offsetParameter,omitempty"`
+	PageSize        int    `json:"pageSize,omitempty"`
+
+	TokenParameter string `json:"tokenParameter,omitempty"`
+	NextToken      string `json:"nextToken,omitempty"`
+}
+
+func (p *paginationSpec) validate() error {
+	switch p.Strategy {
+	case paginationOffset:
+		if p.OffsetParameter == "" {
+			return fmt.Errorf("offset strategy requires 'offsetParameter'")
+		}
+	case paginationToken:
+		if p.TokenParameter == "" || p.NextToken == "" {
+			return fmt.Errorf("token strategy requires 'tokenParameter' and 'nextToken'")
+		}
+	case paginationLink:
+	default:
+		return fmt.Errorf("unknown strategy %q, must be one of %s, %s or %s", p.Strategy, paginationOffset, paginationToken, paginationLink)
+	}
+	return nil
+}
+
+func getPaginationExtension(extensionPrefix *string, extensions map[string]any) (*paginationSpec, error) {
+	ext, ok := getExtensionObject(extensionPrefix, "pagination", extensions, nil)
+	if !ok || ext == nil {
+		return nil, nil
+	}
+
+	p := &paginationSpec{}
+	if err := utils.DecodeValue(ext, p); err != nil {
+		return nil, fmt.Errorf("invalid pagination: %w", err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid pagination: %w", err)
+	}
+	return p, nil
+}
+
+func splitValuePath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+func lookupValuePath(value any, path string) (any, bool) {
+	for _, key := range splitValuePath(path) {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func (p *paginationSpec) itemsOf(value any) ([]any, error) {
+	v, ok := lookupValuePath(value, p.Items)
+	if !ok || v == nil {
+		return nil, nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("paginated result %q is not a list", p.Items)
+	}
+	return items, nil
+}
+
+// Returns a copy of 'value' with its list replaced by 'items'. Only the maps in the path are copied
+func (p *paginationSpec) withItems(value any, items []any) any {
+	keys := splitValuePath(p.Items)
+	if len(keys) == 0 {
+		return items
+	}
+
+	m, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	m = maps.Clone(m)
+	key := keys[0]
+	if len(keys) == 1 {
+		m[key] = items
+	} else {
+		m[key] = (&paginationSpec{Items: strings.Join(keys[1:], ".")}).withItems(m[key], items)
+	}
+	return m
+}
+
+var linkNextRe = regexp.MustCompile(`<([^>]*)>[^,]*;\s*rel="?next"?`)
+
+// Parses RFC 8288 Link headers, returning the 'next' URL resolved against the request one
+func nextLinkUrl(result core.Result) string {
+	httpResult, ok := core.ResultAs[mgcHttpPkg.HttpResult](result)
+	if !ok || httpResult.Response() == nil {
+		return ""
+	}
+
+	for _, header := range httpResult.Response().Header.Values("Link") {
+		matches := linkNextRe.FindStringSubmatch(header)
+		if matches == nil {
+			continue
+		}
+		next, err := url.Parse(matches[1])
+		if err != nil {
+			return ""
+		}
+		if req := httpResult.Request(); req != nil && req.URL != nil {
+			next = req.URL.ResolveReference(next)
+		}
+		return next.String()
+	}
+	return ""
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case json.Number:
+		i, err := v.Int64()
+		return int(i), err == nil
+	default:
+		return 0, false
+	}
+}
+
+type pageUrlKey struct{}
+
+// The link strategy requests the next page URL as given by the server
+func withPageUrl(ctx context.Context, pageUrl string) context.Context {
+	return context.WithValue(ctx, pageUrlKey{}, pageUrl)
+}
+
+func getPageUrl(ctx context.Context) string {
+	pageUrl, _ := ctx.Value(pageUrlKey{}).(string)
+	return pageUrl
+}
+
+type allPagesKey struct{}
+
+// When set, executing a paginated operation fetches every page and merges their items in a single result
+func WithAllPagesFlag(ctx context.Context, all bool) context.Context {
+	return context.WithValue(ctx, allPagesKey{}, all)
+}
+
+func GetAllPagesFlag(ctx context.Context) bool {
+	all, _ := ctx.Value(allPagesKey{}).(bool)
+	return all
+}
+
+type executeFunc func(ctx context.Context, parameters core.Parameters, configs core.Configs) (core.Result, error)
+
+// PageIterator executes a paginated operation once per page, in the style of bufio.Scanner:
+//
+//	pages, err := openapi.NewPageIterator(ctx, exec, parameters, configs)
+//	for pages.Next() {
+//		process(pages.Items())
+//	}
+//	err = pages.Err()
+type PageIterator struct {
+	ctx        context.Context
+	pagination *paginationSpec
+	execute    executeFunc
+	parameters core.Parameters
+	configs    core.Configs
+
+	offsetName string
+	tokenName  string
+	offset     int
+	limit      int
+	pageUrl    string
+	seen       map[string]bool
+
+	result core.ResultWithValue
+	items  []any
+	err    error
+	done   bool
+}
+
+// Returns an error if the executor is not an OpenAPI operation with the x-mgc-pagination extension
+func NewPageIterator(ctx context.Context, exec core.Executor, parameters core.Parameters, configs core.Configs) (*PageIterator, error) {
+	op, ok := core.ExecutorAs[*operation](exec)
+	if !ok || op.pagination == nil {
+		return nil, fmt.Errorf("%s doesn't support pagination", exec.Name())
+	}
+	return newPageIterator(ctx, op.getPagination(), op.externalParameterName, op.Execute, parameters, configs), nil
+}
+
+// Without an explicit page size, the default of the limit parameter is used, so the last page is
+// detected by having less items than requested, not needing another request returning none
+func (o *operation) getPagination() *paginationSpec {
+	p := *o.pagination
+	if p.PageSize > 0 || p.LimitParameter == "" {
+		return &p
+	}
+
+	if schema := o.ParametersSchema(); schema != nil {
+		if limitRef, ok := schema.Properties[o.externalParameterName(p.LimitParameter)]; ok && limitRef.Value != nil {
+			p.PageSize, _ = toInt(limitRef.Value.Default)
+		}
+	}
+	return &p
+}
+
+func newPageIterator(
+	ctx context.Context,
+	pagination *paginationSpec,
+	externalName func(string) string,
+	execute executeFunc,
+	parameters core.Parameters,
+	configs core.Configs,
+) *PageIterator {
+	it := &PageIterator{
+		ctx:        WithAllPagesFlag(ctx, false),
+		pagination: pagination,
+		execute:    execute,
+		parameters: maps.Clone(parameters),
+		configs:    configs,
+		seen:       map[string]bool{},
+	}
+	if it.parameters == nil {
+		it.parameters = core.Parameters{}
+	}
+
+	switch pagination.Strategy {
+	case paginationOffset:
+		it.offsetName = externalName(pagination.OffsetParameter)
+		it.offset, _ = toInt(it.parameters[it.offsetName])
+
+		if pagination.LimitParameter != "" {
+			limitName := externalName(pagination.LimitParameter)
+			if limit, ok := toInt(it.parameters[limitName]); ok && limit > 0 {
+				it.limit = limit
+			} else if pagination.PageSize > 0 {
+				it.limit = pagination.PageSize
+				it.parameters[limitName] = it.limit
+			}
+		}
+	case paginationToken:
+		it.tokenName = externalName(pagination.TokenParameter)
+	}
+
+	return it
+}
+
+// Fetches the next page, returning false when there are no more pages or on errors
+func (it *PageIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	ctx := it.ctx
+	if it.pageUrl != "" {
+		ctx = withPageUrl(ctx, it.pageUrl)
+	}
+
+	result, err := it.execute(ctx, it.parameters, it.configs)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	withValue, ok := core.ResultAs[core.ResultWithValue](result)
+	if !ok {
+		it.err = fmt.Errorf("paginated operation has no result value")
+		return false
+	}
+
+	items, err := it.pagination.itemsOf(withValue.Value())
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.result, it.items = withValue, items
+	it.prepareNext(result)
+	return true
+}
+
+func (it *PageIterator) prepareNext(result core.Result) {
+	switch it.pagination.Strategy {
+	case paginationOffset:
+		if len(it.items) == 0 || (it.limit > 0 && len(it.items) < it.limit) {
+			it.done = true
+			return
+		}
+		it.offset += len(it.items)
+		it.parameters[it.offsetName] = it.offset
+
+	case paginationToken:
+		next, _ := lookupValuePath(it.result.Value(), it.pagination.NextToken)
+		token, _ := next.(string)
+		if token == "" || it.seen[token] {
+			it.done = true
+			return
+		}
+		it.seen[token] = true
+		it.parameters[it.tokenName] = token
+
+	case paginationLink:
+		next := nextLinkUrl(result)
+		if next == "" || it.seen[next] {
+			it.done = true
+			return
+		}
+		it.seen[next] = true
+		it.pageUrl = next
+	}
+}
+
+// Result of the last page fetched by Next()
+func (it *PageIterator) Result() core.ResultWithValue {
+	return it.result
+}
+
+// Items of the last page fetched by Next()
+func (it *PageIterator) Items() []any {
+	return it.items
+}
+
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+// Fetches every page, merging their items in the first page result
+func (o *operation) executeAllPages(ctx context.Context, parameters core.Parameters, configs core.Configs) (core.Result, error) {
+	pagination := o.getPagination()
+	pages := newPageIterator(ctx, pagination, o.externalParameterName, o.Execute, parameters, configs)
+
+	var first core.ResultWithValue
+	items := []any{}
+	for pages.Next() {
+		if first == nil {
+			first = pages.Result()
+		}
+		items = append(items, pages.Items()...)
+		o.logger.Debugw("fetched page", "items", len(pages.Items()), "total", len(items))
+	}
+	if err := pages.Err(); err != nil {
+		return nil, err
+	}
+
+	source := core.ResultSource{
+		Executor:   o,
+		Context:    ctx,
+		Parameters: parameters,
+		Configs:    configs,
+	}
+	var result core.ResultWithValue = core.NewSimpleResult(source, first.Schema(), pagination.withItems(first.Value(), items))
+	if o.outputFlag != "" {
+		result = core.NewResultWithDefaultOutputOptions(result, o.outputFlag)
+	}
+	return result, nil
+}
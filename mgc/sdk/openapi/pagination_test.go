@@ -0,0 +1,141 @@
+package openapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+)
+
+func TestGetPaginationExtension(t *testing.T) {
+	prefix := "x-mgc"
+	p, err := getPaginationExtension(&prefix, map[string]any{
+		"x-mgc-pagination": map[string]any{"strategy": "offset", "items": "instances", "offsetParameter": "_offset", "pageSize": 10},
+	})
+	if err != nil || p == nil || p.Items != "instances" || p.PageSize != 10 {
+		t.Errorf("unexpected pagination %+v (error: %v)", p, err)
+	}
+
+	if _, err = getPaginationExtension(&prefix, map[string]any{"x-mgc-pagination": map[string]any{"strategy": "token"}}); err == nil {
+		t.Errorf("expected error for token strategy without parameters")
+	}
+
+	if p, err = getPaginationExtension(&prefix, map[string]any{}); p != nil || err != nil {
+		t.Errorf("expected no pagination, got %+v (error: %v)", p, err)
+	}
+}
+
+func TestPaginationWithItems(t *testing.T) {
+	p := &paginationSpec{Items: "meta.items"}
+	value := map[string]any{"meta": map[string]any{"items": []any{1}, "total": 3}, "other": true}
+
+	merged := p.withItems(value, []any{1, 2, 3})
+	expected := map[string]any{"meta": map[string]any{"items": []any{1, 2, 3}, "total": 3}, "other": true}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %v, got %v", expected, merged)
+	}
+	if items, _ := p.itemsOf(value); len(items) != 1 {
+		t.Errorf("original value should not be modified, got %v", value)
+	}
+
+	if merged = (&paginationSpec{}).withItems([]any{1}, []any{1, 2}); !reflect.DeepEqual(merged, []any{1, 2}) {
+		t.Errorf("expected the list itself to be replaced, got %v", merged)
+	}
+}
+
+func newPaginationTestIterator(p *paginationSpec, parameters core.Parameters, pages func(ctx context.Context, parameters core.Parameters) core.Result) (*PageIterator, *[]core.Parameters) {
+	calls := []core.Parameters{}
+	execute := func(ctx context.Context, parameters core.Parameters, configs core.Configs) (core.Result, error) {
+		calls = append(calls, parameters)
+		return pages(ctx, parameters), nil
+	}
+	externalName := func(name string) string { return "ext" + name }
+	return newPageIterator(context.Background(), p, externalName, execute, parameters, nil), &calls
+}
+
+func collectPages(t *testing.T, it *PageIterator) (items []any) {
+	for it.Next() {
+		items = append(items, it.Items()...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return items
+}
+
+func TestPageIteratorOffset(t *testing.T) {
+	all := []any{"a", "b", "c", "d", "e"}
+	p := &paginationSpec{Strategy: paginationOffset, Items: "items", LimitParameter: "_limit", OffsetParameter: "_offset", PageSize: 2}
+
+	it, calls := newPaginationTestIterator(p, core.Parameters{"other": "value"}, func(_ context.Context, parameters core.Parameters) core.Result {
+		offset, _ := toInt(parameters["ext_offset"])
+		limit, _ := toInt(parameters["ext_limit"])
+		page := all[min(offset, len(all)):min(offset+limit, len(all))]
+		return core.NewSimpleResult(core.ResultSource{}, nil, map[string]any{"items": page})
+	})
+
+	if items := collectPages(t, it); !reflect.DeepEqual(items, all) {
+		t.Errorf("expected %v, got %v", all, items)
+	}
+	if len(*calls) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(*calls))
+	}
+	if (*calls)[0]["other"] != "value" {
+		t.Errorf("expected other parameters to be kept, got %v", (*calls)[0])
+	}
+}
+
+func TestPageIteratorToken(t *testing.T) {
+	pages := map[string]map[string]any{
+		"":   {"items": []any{1, 2}, "next": "p2"},
+		"p2": {"items": []any{3}, "next": "p3"},
+		"p3": {"items": []any{}, "next": "p2"}, // broken server repeating tokens
+	}
+	p := &paginationSpec{Strategy: paginationToken, Items: "items", TokenParameter: "token", NextToken: "next"}
+
+	it, calls := newPaginationTestIterator(p, nil, func(_ context.Context, parameters core.Parameters) core.Result {
+		token, _ := parameters["exttoken"].(string)
+		return core.NewSimpleResult(core.ResultSource{}, nil, pages[token])
+	})
+
+	if items := collectPages(t, it); !reflect.DeepEqual(items, []any{1, 2, 3}) {
+		t.Errorf("expected [1 2 3], got %v", items)
+	}
+	if len(*calls) != 3 {
+		t.Errorf("expected 3 pages, got %d", len(*calls))
+	}
+}
+
+func TestPageIteratorLink(t *testing.T) {
+	p := &paginationSpec{Strategy: paginationLink}
+
+	it, _ := newPaginationTestIterator(p, nil, func(ctx context.Context, _ core.Parameters) core.Result {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.test/v1/items", nil)
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"application/json"}}, Request: req}
+		body := `["first"]`
+		if getPageUrl(ctx) == "" {
+			resp.Header.Set("Link", `<https://api.test/v1/items?page=0>; rel="prev", </v1/items?page=2>; rel="next"`)
+		} else {
+			req.URL, _ = req.URL.Parse(getPageUrl(ctx))
+			body = `["second"]`
+		}
+		resp.Body = io.NopCloser(strings.NewReader(body))
+		result, err := mgcHttpPkg.NewHttpResult(core.ResultSource{}, nil, req, nil, resp, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	})
+
+	if items := collectPages(t, it); !reflect.DeepEqual(items, []any{"first", "second"}) {
+		t.Errorf("expected [first second], got %v", items)
+	}
+	if it.pageUrl != "https://api.test/v1/items?page=2" {
+		t.Errorf("expected relative next link to be resolved, got %q", it.pageUrl)
+	}
+}
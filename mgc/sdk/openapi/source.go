@@ -1,11 +1,13 @@
 package openapi
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
 	"github.com/MagaluCloud/magalu/mgc/core/dataloader"
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/invopop/yaml"
 )
 
@@ -69,3 +71,35 @@ func NewSource(loader dataloader.Loader, extensionPrefix *string) *core.SimpleGr
 		},
 	)
 }
+
+// LoadDocuments parses the OpenAPI document of every module in the index, keyed by the module name
+func LoadDocuments(ctx context.Context, loader dataloader.Loader) (map[string]*openapi3.T, error) {
+	data, err := loader.Load(indexFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var index indexFileSpec
+	if err = yaml.Unmarshal(data, &index); err != nil {
+		return nil, err
+	}
+	if index.Version != indexVersion {
+		return nil, fmt.Errorf("unsupported %q version %q, expected %q", indexFileName, index.Version, indexVersion)
+	}
+
+	docs := make(map[string]*openapi3.T, len(index.Modules))
+	for _, module := range index.Modules {
+		mData, err := loader.Load(module.Path)
+		if err != nil {
+			return nil, &utils.ChainedError{Name: module.Path, Err: err}
+		}
+
+		oapiLoader := openapi3.Loader{Context: ctx, IsExternalRefsAllowed: false}
+		doc, err := oapiLoader.LoadFromData(mData)
+		if err != nil {
+			return nil, &utils.ChainedError{Name: module.Path, Err: err}
+		}
+		docs[module.Name] = doc
+	}
+	return docs, nil
+}
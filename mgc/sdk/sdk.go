@@ -36,6 +36,7 @@ type Sdk struct {
 	httpClient     *mgcHttpPkg.Client
 	config         *config.Config
 	refResolver    core.RefPathResolver
+	rateLimiter    *mgcHttpPkg.RateLimiter
 }
 
 type contextKey string
@@ -130,14 +131,52 @@ func (o *Sdk) Group() core.Grouper {
 	return o.group
 }
 
-func newHttpTransport(version string) http.RoundTripper {
+// Read on every request, so changes to the "retry" config are respected by the already created transports
+func (o *Sdk) retryPolicy() mgcHttpPkg.RetryPolicy {
+	return retryPolicyFromConfig(o.Config())
+}
+
+func retryPolicyFromConfig(c *config.Config) mgcHttpPkg.RetryPolicy {
+	policy := mgcHttpPkg.DefaultRetryPolicy()
+	// Slices are decoded over the existing elements, so the configured codes would be mixed with the default ones
+	policy.StatusCodes = nil
+	if err := c.Get("retry", &policy); err != nil {
+		return mgcHttpPkg.DefaultRetryPolicy()
+	}
+	if policy.StatusCodes == nil {
+		policy.StatusCodes = mgcHttpPkg.DefaultRetryPolicy().StatusCodes
+	}
+	return policy
+}
+
+func (o *Sdk) rateLimitConfig() (rateLimit mgcHttpPkg.RateLimitConfig) {
+	if err := o.Config().Get("rateLimit", &rateLimit); err != nil {
+		return mgcHttpPkg.RateLimitConfig{}
+	}
+	return
+}
+
+// All transports of the Sdk share the same limiter, so every request counts towards the same budget
+func (o *Sdk) RateLimiter() *mgcHttpPkg.RateLimiter {
+	if o.rateLimiter == nil {
+		o.rateLimiter = mgcHttpPkg.NewRateLimiter(o.rateLimitConfig)
+	}
+	return o.rateLimiter
+}
+
+func newHttpTransport(version string, retryPolicy func() mgcHttpPkg.RetryPolicy, rateLimiter *mgcHttpPkg.RateLimiter) http.RoundTripper {
 	userAgent := fmt.Sprintf("MgcCLI/%s (%s; %s)", version, runtime.GOOS, runtime.GOARCH)
 	// To avoid creating a transport with zero values, we leverage
 	// DefaultTransport (exemple: `Proxy: ProxyFromEnvironment`)
 	transport := mgcHttpPkg.DefaultTransport()
+	// Innermost, so every attempt of the retryer is recorded/replayed
+	transport = mgcHttpPkg.NewCassetteTransportFromEnv(transport)
+	transport = mgcHttpPkg.NewMockServerTransportFromEnv(transport)
 	transport = mgcHttpPkg.NewDefaultClientLogger(transport)
 	transport = newDefaultSdkTransport(transport, userAgent)
-	transport = mgcHttpPkg.NewDefaultClientRetryer(transport)
+	// Inside the retryer, so every attempt is limited
+	transport = mgcHttpPkg.NewClientRateLimiter(transport, rateLimiter)
+	transport = mgcHttpPkg.NewClientRetryerWithPolicy(transport, retryPolicy)
 	return transport
 }
 
@@ -154,7 +193,7 @@ func (o *Sdk) ProfileManager() *profile_manager.ProfileManager {
 
 func (o *Sdk) Auth() *auth.Auth {
 	if o.auth == nil {
-		client := &http.Client{Transport: newHttpTransport(o.version)}
+		client := &http.Client{Transport: newHttpTransport(o.version, o.retryPolicy, o.RateLimiter())}
 		o.auth = auth.New(authConfigMap, client, o.ProfileManager(), o.Config())
 	}
 
@@ -163,7 +202,7 @@ func (o *Sdk) Auth() *auth.Auth {
 
 func (o *Sdk) HttpClient() *mgcHttpPkg.Client {
 	if o.httpClient == nil {
-		transport := o.addHttpRefreshHandler(newHttpTransport(o.version))
+		transport := o.addHttpRefreshHandler(newHttpTransport(o.version, o.retryPolicy, o.RateLimiter()))
 		o.httpClient = mgcHttpPkg.NewClient(transport)
 	}
 	return o.httpClient
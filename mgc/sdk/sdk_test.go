@@ -0,0 +1,38 @@
+package sdk
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+)
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	c := config.New(m)
+
+	policy := retryPolicyFromConfig(c)
+	if !slices.Equal(policy.StatusCodes, mgcHttpPkg.DefaultRetryPolicy().StatusCodes) {
+		t.Errorf("expected default status codes without config, got %v", policy.StatusCodes)
+	}
+
+	err := c.Set("retry", map[string]any{"statusCodes": []int{http.StatusServiceUnavailable}, "maxAttempts": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy = retryPolicyFromConfig(c)
+	if !slices.Equal(policy.StatusCodes, []int{http.StatusServiceUnavailable}) {
+		t.Errorf("expected only the configured status codes, got %v", policy.StatusCodes)
+	}
+	if policy.MaxAttempts != 2 {
+		t.Errorf("expected configured max attempts, got %d", policy.MaxAttempts)
+	}
+	if policy.MaxBackoff != 20*time.Second || !policy.Jitter {
+		t.Errorf("expected defaults for fields not configured, got %+v", policy)
+	}
+}
@@ -3,7 +3,7 @@ package sdk
 import (
 	"net/http"
 
-	"github.com/google/uuid"
+	mgcLoggerPkg "github.com/MagaluCloud/magalu/mgc/core/logger"
 )
 
 var _ http.RoundTripper = (*DefaultSdkTransport)(nil)
@@ -22,7 +22,7 @@ func newDefaultSdkTransport(transport http.RoundTripper, userAgent string) *Defa
 
 func (t *DefaultSdkTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.Header.Set("User-Agent", t.UserAgent)
-	req.Header.Set("X-Request-Id", uuid.New().String())
+	req.Header.Set("X-Request-Id", mgcLoggerPkg.CorrelationID())
 
 	transport := t.Transport
 	if transport == nil {
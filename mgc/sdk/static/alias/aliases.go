@@ -0,0 +1,187 @@
+package alias
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	"github.com/invopop/yaml"
+)
+
+const aliasesFilename = "aliases.yaml"
+
+// Aliases maps the alias name to the command it expands to, ie: "virtual-machine instances list -o table".
+// The command may reference the arguments given after the alias name as $1, $2... or all of them as $@
+type Aliases map[string]string
+
+var errNoProfileManager = errors.New("couldn't get ProfileManager from context")
+
+func currentProfile(ctx context.Context) (*profile_manager.Profile, error) {
+	m := profile_manager.FromContext(ctx)
+	if m == nil {
+		return nil, errNoProfileManager
+	}
+	return m.Current(), nil
+}
+
+// Load returns the aliases of the given workspace, empty if none was set
+func Load(p *profile_manager.Profile) (Aliases, error) {
+	aliases := Aliases{}
+	data, err := p.Read(aliasesFilename)
+	if err != nil {
+		// no aliases file yet
+		return aliases, nil
+	}
+
+	if err = yaml.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid aliases file: %w", err)
+	}
+	return aliases, nil
+}
+
+func save(p *profile_manager.Profile, aliases Aliases) error {
+	if len(aliases) == 0 {
+		return p.Delete(aliasesFilename)
+	}
+
+	data, err := yaml.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	return p.Write(aliasesFilename, data)
+}
+
+// Splits the command in arguments as a shell would, handling single and double quotes and
+// backslash escapes, but without any variable or glob expansion
+func splitCommand(command string) (args []string, err error) {
+	var current strings.Builder
+	var quote rune
+	hasArg := false
+	escaped := false
+
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			hasArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			hasArg = true
+		case unicode.IsSpace(r):
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", command)
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// Replaces $1, $2... and $@ in the argument, returning the highest positional used, or -1 if $@ was used
+func substituteArg(arg string, positional []string) (result string, used int, err error) {
+	var b strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '$' || i+1 >= len(arg) {
+			b.WriteByte(arg[i])
+			continue
+		}
+
+		if arg[i+1] == '@' {
+			b.WriteString(strings.Join(positional, " "))
+			used = -1
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j < len(arg) && arg[j] >= '0' && arg[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(arg[i])
+			continue
+		}
+
+		n, _ := strconv.Atoi(arg[i+1 : j])
+		if n == 0 || n > len(positional) {
+			return "", 0, fmt.Errorf("missing argument $%d", n)
+		}
+		b.WriteString(positional[n-1])
+		if used >= 0 && n > used {
+			used = n
+		}
+		i = j - 1
+	}
+	return b.String(), used, nil
+}
+
+/*
+Expand replaces the alias in the first argument by its command. The following arguments
+replace $1, $2... in the command, or all of them at once when it's exactly "$@". Arguments not
+referenced are appended to the command, so flags may still be given after the alias.
+
+Arguments not starting with an alias are returned as given.
+*/
+func Expand(aliases Aliases, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	command, ok := aliases[args[0]]
+	if !ok {
+		return args, nil
+	}
+
+	cmdArgs, err := splitCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("alias %q: %w", args[0], err)
+	}
+
+	positional := args[1:]
+	expanded := make([]string, 0, len(cmdArgs)+len(positional))
+	maxUsed := 0
+	for _, arg := range cmdArgs {
+		if arg == "$@" {
+			expanded = append(expanded, positional...)
+			maxUsed = -1
+			continue
+		}
+
+		result, used, err := substituteArg(arg, positional)
+		if err != nil {
+			return nil, fmt.Errorf("alias %q: %w", args[0], err)
+		}
+		expanded = append(expanded, result)
+		if maxUsed >= 0 && (used < 0 || used > maxUsed) {
+			maxUsed = used
+		}
+	}
+
+	if maxUsed >= 0 {
+		expanded = append(expanded, positional[maxUsed:]...)
+	}
+	return expanded, nil
+}
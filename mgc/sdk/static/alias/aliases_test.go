@@ -0,0 +1,113 @@
+package alias
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+)
+
+func TestExpand(t *testing.T) {
+	aliases := Aliases{
+		"myvms":   "virtual-machine instances list -o 'table=ID:$.instances[*].id,NAME:$.instances[*].name'",
+		"vm":      "virtual-machine instances get --id $1",
+		"vm-pair": `virtual-machine instances get --id=$2 --name "my vm $1"`,
+		"all":     "virtual-machine instances delete $@ --no-confirm",
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		expected  []string
+		wantError bool
+	}{
+		{
+			name:     "not an alias",
+			args:     []string{"virtual-machine", "instances", "list"},
+			expected: []string{"virtual-machine", "instances", "list"},
+		},
+		{
+			name:     "quoted flag",
+			args:     []string{"myvms"},
+			expected: []string{"virtual-machine", "instances", "list", "-o", "table=ID:$.instances[*].id,NAME:$.instances[*].name"},
+		},
+		{
+			name:     "extra arguments are appended",
+			args:     []string{"myvms", "--region", "br-ne1"},
+			expected: []string{"virtual-machine", "instances", "list", "-o", "table=ID:$.instances[*].id,NAME:$.instances[*].name", "--region", "br-ne1"},
+		},
+		{
+			name:     "positional",
+			args:     []string{"vm", "abc", "-o", "json"},
+			expected: []string{"virtual-machine", "instances", "get", "--id", "abc", "-o", "json"},
+		},
+		{
+			name:     "positional inside arguments",
+			args:     []string{"vm-pair", "a", "b", "c"},
+			expected: []string{"virtual-machine", "instances", "get", "--id=b", "--name", "my vm a", "c"},
+		},
+		{
+			name:     "all arguments",
+			args:     []string{"all", "a", "b"},
+			expected: []string{"virtual-machine", "instances", "delete", "a", "b", "--no-confirm"},
+		},
+		{
+			name:      "missing positional",
+			args:      []string{"vm"},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Expand(aliases, tc.args)
+			if tc.wantError {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(tc.expected, got) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSplitCommandUnterminated(t *testing.T) {
+	for _, command := range []string{`list -o 'table`, `list "a`, `list \`} {
+		if _, err := splitCommand(command); err == nil {
+			t.Errorf("expected error for %q", command)
+		}
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	p := m.Current()
+
+	aliases, err := Load(p)
+	if err != nil || len(aliases) != 0 {
+		t.Fatalf("expected no aliases, got %v, %v", aliases, err)
+	}
+
+	aliases["myvms"] = "virtual-machine instances list"
+	if err = save(p, aliases); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := Load(p)
+	if err != nil || got["myvms"] != aliases["myvms"] {
+		t.Fatalf("expected %v, got %v, %v", aliases, got, err)
+	}
+
+	if err = save(p, Aliases{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = p.Read(aliasesFilename); err == nil {
+		t.Errorf("expected aliases file to be removed")
+	}
+}
@@ -0,0 +1,49 @@
+package alias
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+type deleteParams struct {
+	Name string `json:"name" jsonschema:"description=Alias name" mgc:"positional"`
+}
+
+var getDelete = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "delete",
+			Description: "Delete an alias of the current workspace",
+		},
+		deleteAlias,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Deleted alias {{.name}}\n"
+	})
+})
+
+func deleteAlias(ctx context.Context, params deleteParams, _ struct{}) (*aliasEntry, error) {
+	p, err := currentProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := Load(p)
+	if err != nil {
+		return nil, err
+	}
+
+	command, ok := aliases[params.Name]
+	if !ok {
+		return nil, fmt.Errorf("alias %q not found", params.Name)
+	}
+
+	delete(aliases, params.Name)
+	if err = save(p, aliases); err != nil {
+		return nil, err
+	}
+	return &aliasEntry{Name: params.Name, Command: command}, nil
+}
@@ -0,0 +1,25 @@
+package alias
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+var GetGroup = utils.NewLazyLoader(func() core.Grouper {
+	return core.NewStaticGroup(
+		core.DescriptorSpec{
+			Name: "alias",
+			Description: `Aliases are shortcuts to commands, including their flags, stored in the current workspace.
+Running "mgc <alias> [args...]" runs the command it expands to, with $1, $2... replaced by the given arguments`,
+			Summary: "Manage command aliases of the current workspace",
+			GroupID: "settings",
+		},
+		func() []core.Descriptor {
+			return []core.Descriptor{
+				getSet(),
+				getList(),
+				getDelete(),
+			}
+		},
+	)
+})
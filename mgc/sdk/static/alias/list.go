@@ -0,0 +1,44 @@
+package alias
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+var getList = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecuteSimple(
+		core.DescriptorSpec{
+			Name:        "list",
+			Description: "List the aliases of the current workspace",
+		},
+		list,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "table"
+	})
+})
+
+func list(ctx context.Context) ([]*aliasEntry, error) {
+	p, err := currentProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := Load(p)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*aliasEntry, 0, len(aliases))
+	for name, command := range aliases {
+		entries = append(entries, &aliasEntry{Name: name, Command: command})
+	}
+	slices.SortFunc(entries, func(a, b *aliasEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return entries, nil
+}
@@ -0,0 +1,88 @@
+package alias
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+type setParams struct {
+	Name    string `json:"name" jsonschema:"description=Alias name,example=myvms" mgc:"positional"`
+	Command string `json:"command" jsonschema:"description=Command the alias expands to\\, without 'mgc'. Quote it to include flags,example=virtual-machine instances list -o table" mgc:"positional"`
+}
+
+type aliasEntry struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+var getSet = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "set",
+			Summary: "Create or replace an alias",
+			Description: `Create or replace an alias in the current workspace. The command may reference the arguments
+given after the alias as $1, $2... or all of them as $@, the ones not referenced are appended to the command.
+
+Example:
+
+  mgc alias set myvms "virtual-machine instances list -o table=ID:$.instances[*].id,NAME:$.instances[*].name"
+  mgc alias set vm-get 'virtual-machine instances get --id $1'
+  mgc vm-get 9f8e7d6c-0000-0000-0000-000000000000 -o json`,
+		},
+		set,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Alias {{.name}} set to: {{.command}}\n"
+	})
+})
+
+func validateName(ctx context.Context, name string) error {
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' }) {
+		return fmt.Errorf("invalid alias name %q, it must be a single word", name)
+	}
+	if strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid alias name %q, it must not start with '-'", name)
+	}
+
+	// Aliases are only expanded in place of a command, so they can't hide existing ones
+	if root := core.GrouperFromContext(ctx); root != nil {
+		if _, err := root.GetChildByName(name); err == nil {
+			return fmt.Errorf("invalid alias name %q, there is already a command with this name", name)
+		}
+	}
+	return nil
+}
+
+func set(ctx context.Context, params setParams, _ struct{}) (*aliasEntry, error) {
+	if err := validateName(ctx, params.Name); err != nil {
+		return nil, err
+	}
+	args, err := splitCommand(params.Command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("alias command must not be empty")
+	}
+
+	p, err := currentProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := Load(p)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases[params.Name] = params.Command
+	if err = save(p, aliases); err != nil {
+		return nil, err
+	}
+
+	return &aliasEntry{Name: params.Name, Command: params.Command}, nil
+}
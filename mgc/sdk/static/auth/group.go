@@ -23,6 +23,7 @@ of HTTP requests using the MgcSDK. Authentication is done via Magalu Cloud accou
 				getLogin(),
 				getAccessToken(),
 				getLogout(),
+				getMigrateStorage(),
 				tenant.GetGroup(),
 				clients.GetGroup(),
 				api_key.GetGroup(),
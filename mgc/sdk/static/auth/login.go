@@ -34,6 +34,11 @@ type loginParameters struct {
 	Show     bool `json:"show,omitempty" jsonschema:"description=Show the access token after the login completes"`
 	QRcode   bool `json:"qrcode,omitempty" jsonschema:"description=Generate a qrcode for the login URL,default=false"`
 	Headless bool `json:"headless,omitempty" jsonschema:"description=Generate URL for the login at local environment,default=false"`
+	Device   bool `json:"device,omitempty" jsonschema:"description=Login with a code shown in the terminal\\, without a local callback server (ie: over SSH or in containers),default=false"`
+
+	ClientCredentials bool   `json:"client_credentials,omitempty" jsonschema:"description=Login as a service account using its client credentials,default=false"`
+	ClientId          string `json:"client_id,omitempty" jsonschema:"description=Client ID of the service account. Defaults to MGC_CLIENT_ID environment variable"`
+	ClientSecret      string `json:"client_secret,omitempty" jsonschema:"description=Client secret of the service account. Defaults to MGC_CLIENT_SECRET environment variable"`
 }
 
 type loginResult struct {
@@ -41,7 +46,11 @@ type loginResult struct {
 	SelectedTenant *auth.Tenant `json:"selected_tenant,omitempty"`
 }
 
-const serverShutdownTimeout = 500 * time.Millisecond
+const (
+	serverShutdownTimeout = 500 * time.Millisecond
+	clientIdEnvVar        = "MGC_CLIENT_ID"
+	clientSecretEnvVar    = "MGC_CLIENT_SECRET"
+)
 
 var (
 	//go:embed success.html
@@ -63,7 +72,17 @@ var getLogin = utils.NewLazyLoader[core.Executor](func() core.Executor {
 			Summary: "Authenticate with Magalu Cloud",
 			Description: `Log in to your Magalu Cloud account. When you login with this command,
 the current Tenant will always be set to the default one. To see more details
-about a successful login, use the '--show' flag when logging in`,
+about a successful login, use the '--show' flag when logging in.
+
+When a browser can't reach the local callback server (ie: over SSH or in
+containers), use '--device' to login with a code shown in the terminal. Its
+endpoint is read from the auth server metadata, MGC_DEVICE_AUTHORIZATION_URL
+overrides it.
+Service accounts login with '--client-credentials', reading the client ID and
+secret from the flags or from MGC_CLIENT_ID and MGC_CLIENT_SECRET. The
+credentials are stored with the tokens, so new ones are requested when they expire.
+The secret is only stored by the encrypted-file and keyring auth storages, with the plain one it's
+read from MGC_CLIENT_SECRET again (see 'auth migrate-storage')`,
 		},
 		login,
 	)
@@ -88,32 +107,29 @@ func login(ctx context.Context, parameters loginParameters, _ struct{}) (*loginR
 	if auth == nil {
 		return nil, fmt.Errorf("programming error: unable to retrieve authentication configuration")
 	}
-	isHeadless := parameters.QRcode || parameters.Headless
+	if parameters.Device && parameters.ClientCredentials {
+		return nil, core.UsageError{Err: fmt.Errorf("'device' and 'client_credentials' can't be used together")}
+	}
+	if parameters.ClientCredentials {
+		return clientCredentialsLogin(ctx, parameters, auth)
+	}
 
-	resultChan, cancel, err := startCallbackServer(ctx, auth, isHeadless)
+	scopes, err := loginScopes(ctx, auth)
 	if err != nil {
 		return nil, err
 	}
-	defer cancel()
 
-	// Always force built-in parameters
-	scopes := core.Scopes{}
-	for _, builtIn := range auth.BuiltInScopes() {
-		scopes.Add(builtIn)
+	if parameters.Device {
+		return deviceLogin(ctx, parameters, auth, scopes)
 	}
 
-	// Also add all available scopes by default when logging if no scope is explicitly passed in
-	allScopes, err := mgcAuthScope.ListAllAvailable(ctx)
+	isHeadless := parameters.QRcode || parameters.Headless
+
+	resultChan, cancel, err := startCallbackServer(ctx, auth, isHeadless)
 	if err != nil {
 		return nil, err
 	}
-
-	for _, scope := range allScopes {
-		scopes.Add(scope)
-	}
-
-	scopes.Add("evt:event-tr")
-	scopes.Add("pa:sa:manage")
+	defer cancel()
 
 	codeUrl, err := auth.CodeChallengeToURL(scopes)
 	if err != nil {
@@ -141,24 +157,107 @@ func login(ctx context.Context, parameters loginParameters, _ struct{}) (*loginR
 		return nil, result.err
 	}
 
-	currentTenant, err := auth.CurrentTenant(ctx)
+	checkScopesAfterLogin(auth, scopes)
+
+	return loginOutput(ctx, parameters, auth, result.value)
+}
+
+func loginScopes(ctx context.Context, auth *auth.Auth) (core.Scopes, error) {
+	// Always force built-in parameters
+	scopes := core.Scopes{}
+	for _, builtIn := range auth.BuiltInScopes() {
+		scopes.Add(builtIn)
+	}
+
+	// Also add all available scopes by default when logging if no scope is explicitly passed in
+	allScopes, err := mgcAuthScope.ListAllAvailable(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	checkScopesAfterLogin(auth, scopes)
+	for _, scope := range allScopes {
+		scopes.Add(scope)
+	}
+
+	scopes.Add("evt:event-tr")
+	scopes.Add("pa:sa:manage")
+
+	return scopes, nil
+}
+
+func loginOutput(ctx context.Context, parameters loginParameters, auth *auth.Auth, accessToken string) (*loginResult, error) {
+	currentTenant, err := auth.CurrentTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	loginLogger().Infow("sucessfully logged in")
 
 	output := &loginResult{AccessToken: "", SelectedTenant: currentTenant}
 
 	if parameters.Show {
-		output.AccessToken = result.value
+		output.AccessToken = accessToken
 	}
 
 	return output, nil
 }
 
+func deviceLogin(ctx context.Context, parameters loginParameters, auth *auth.Auth, scopes core.Scopes) (*loginResult, error) {
+	device, err := auth.RequestDeviceAuthorization(ctx, scopes)
+	if err != nil {
+		return nil, err
+	}
+	loginLogger().Infow("running device login", "verificationUri", device.VerificationUri)
+
+	if parameters.QRcode {
+		qrCode, err := qrcode.New(device.URL(), qrcode.Low)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println(qrCode.ToSmallString(false))
+	}
+
+	fmt.Printf("Open %s in any browser and enter the code: %s\n\n", device.VerificationUri, device.UserCode)
+	if device.VerificationUriComplete != "" && !parameters.QRcode {
+		fmt.Printf("Or open the URL with the code already filled in: %s\n\n", device.VerificationUriComplete)
+	}
+	fmt.Println("Waiting for the login to complete...")
+
+	if err = auth.PollDeviceToken(ctx, device); err != nil {
+		return nil, err
+	}
+
+	checkScopesAfterLogin(auth, scopes)
+
+	token, _ := auth.AccessToken(ctx) // this is guaranteed if PollDeviceToken succeeds
+	return loginOutput(ctx, parameters, auth, token)
+}
+
+func clientCredentialsLogin(ctx context.Context, parameters loginParameters, auth *auth.Auth) (*loginResult, error) {
+	clientId := parameters.ClientId
+	if clientId == "" {
+		clientId = os.Getenv(clientIdEnvVar)
+	}
+	clientSecret := parameters.ClientSecret
+	if clientSecret == "" {
+		clientSecret = os.Getenv(clientSecretEnvVar)
+	}
+	if clientId == "" || clientSecret == "" {
+		return nil, core.UsageError{Err: fmt.Errorf(
+			"client credentials login requires 'client_id' and 'client_secret', or %s and %s environment variables",
+			clientIdEnvVar, clientSecretEnvVar,
+		)}
+	}
+
+	// Service accounts are granted the scopes configured for their client
+	if err := auth.RequestAuthTokenWithClientCredentials(ctx, clientId, clientSecret, nil); err != nil {
+		return nil, err
+	}
+
+	token, _ := auth.AccessToken(ctx) // this is guaranteed if RequestAuthTokenWithClientCredentials succeeds
+	return loginOutput(ctx, parameters, auth, token)
+}
+
 func checkScopesAfterLogin(a *auth.Auth, desiredScopes core.Scopes) {
 	currentScopes, err := a.CurrentScopes()
 	if err != nil {
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcAuthPkg "github.com/MagaluCloud/magalu/mgc/core/auth"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+type migrateStorageParams struct {
+	To            string `json:"to" jsonschema:"description=Storage to move the credentials to,enum=plain,enum=encrypted-file,enum=keyring,required" mgc:"positional"`
+	AllWorkspaces bool   `json:"all_workspaces,omitempty" jsonschema:"description=Migrate the credentials of all workspaces instead of only the current one,default=false"`
+}
+
+type migrateStorageResult struct {
+	Workspace string `json:"workspace"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Migrated  bool   `json:"migrated"`
+}
+
+var getMigrateStorage = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "migrate-storage",
+			Summary: "Move stored credentials to another storage",
+			Description: `Move the stored credentials (tokens and keys) to another storage:
+
+- plain: plain text file in the workspace directory
+- encrypted-file: file encrypted with a passphrase, asked when needed or read from MGC_AUTH_PASSPHRASE
+- keyring: freedesktop Secret Service (ie: GNOME Keyring), through the 'secret-tool' command
+
+Each workspace keeps using the storage it was migrated to. New workspaces use the 'authStorage' config.`,
+		},
+		migrateStorage,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template={{range .}}{{if .migrated}}Moved credentials of workspace {{.workspace}} from {{.from}} to {{.to}}{{else}}Nothing to move in workspace {{.workspace}}{{end}}\n{{end}}"
+	})
+})
+
+func migrateStorage(ctx context.Context, params migrateStorageParams, _ struct{}) (results []migrateStorageResult, err error) {
+	auth := mgcAuthPkg.FromContext(ctx)
+	if auth == nil {
+		return nil, fmt.Errorf("unable to retrieve authentication configuration")
+	}
+
+	m := profile_manager.FromContext(ctx)
+	if m == nil {
+		return nil, fmt.Errorf("couldn't get ProfileManager from context")
+	}
+
+	profiles := []*profile_manager.Profile{m.Current()}
+	if params.AllWorkspaces {
+		profiles = m.List()
+	}
+
+	for _, p := range profiles {
+		from, migrated, err := auth.MigrateStorage(p, params.To)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, migrateStorageResult{Workspace: p.Name, From: from, To: params.To, Migrated: migrated})
+	}
+
+	return results, nil
+}
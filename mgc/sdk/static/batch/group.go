@@ -0,0 +1,22 @@
+package batch
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+var GetGroup = utils.NewLazyLoader(func() core.Grouper {
+	return core.NewStaticGroup(
+		core.DescriptorSpec{
+			Name:        "batch",
+			Summary:     "Run multiple commands at once",
+			Description: "Run multiple commands described in a plan file, referencing the results of the previous ones",
+			GroupID:     "other",
+		},
+		func() []core.Descriptor {
+			return []core.Descriptor{
+				getRun(),
+			}
+		},
+	)
+})
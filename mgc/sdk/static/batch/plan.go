@@ -0,0 +1,184 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+const (
+	onErrorStop     = "stop"
+	onErrorContinue = "continue"
+)
+
+// Each step runs a single command, or a group of steps in parallel
+type planStep struct {
+	Name string `json:"name,omitempty"`
+	// Executor path, as the CLI command without 'mgc' (ie: "virtual-machine instances list")
+	Command         string         `json:"command,omitempty"`
+	Parameters      map[string]any `json:"parameters,omitempty"`
+	Configs         map[string]any `json:"configs,omitempty"`
+	WaitTermination bool           `json:"waitTermination,omitempty"`
+	// JSONPath applied to the result, its value is stored as the step output instead of the whole result
+	Capture  string      `json:"capture,omitempty"`
+	OnError  string      `json:"onError,omitempty"`
+	Parallel []*planStep `json:"parallel,omitempty"`
+}
+
+type plan struct {
+	OnError string      `json:"onError,omitempty"`
+	Steps   []*planStep `json:"steps"`
+}
+
+func validateOnError(onError string) error {
+	switch onError {
+	case "", onErrorStop, onErrorContinue:
+		return nil
+	default:
+		return fmt.Errorf("invalid onError %q, must be %q or %q", onError, onErrorStop, onErrorContinue)
+	}
+}
+
+func (p *plan) validate() error {
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("plan has no steps")
+	}
+	if err := validateOnError(p.OnError); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	var validateStep func(step *planStep, path string, inParallel bool) error
+	validateStep = func(step *planStep, path string, inParallel bool) error {
+		if err := validateOnError(step.OnError); err != nil {
+			return fmt.Errorf("step %s: %w", path, err)
+		}
+		if step.Name != "" {
+			if names[step.Name] {
+				return fmt.Errorf("step %s: duplicated name %q", path, step.Name)
+			}
+			names[step.Name] = true
+		}
+
+		if len(step.Parallel) == 0 {
+			if step.Command == "" {
+				return fmt.Errorf("step %s: either 'command' or 'parallel' is required", path)
+			}
+			return nil
+		}
+
+		if step.Command != "" {
+			return fmt.Errorf("step %s: 'command' and 'parallel' can't be used together", path)
+		}
+		if inParallel {
+			return fmt.Errorf("step %s: parallel groups can't be nested", path)
+		}
+		for i, child := range step.Parallel {
+			if err := validateStep(child, fmt.Sprintf("%s.%d", path, i), true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i, step := range p.Steps {
+		if err := validateStep(step, fmt.Sprint(i), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *planStep) displayName(index string) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return index
+}
+
+func (s *planStep) onError(p *plan) string {
+	if s.OnError != "" {
+		return s.OnError
+	}
+	if p.OnError != "" {
+		return p.OnError
+	}
+	return onErrorStop
+}
+
+func splitCommand(command string) []string {
+	return strings.FieldsFunc(command, func(r rune) bool {
+		return r == ' ' || r == '/' || r == '\t'
+	})
+}
+
+func findExecutor(root core.Grouper, command string) (core.Executor, error) {
+	var current core.Descriptor = root
+	for _, name := range splitCommand(command) {
+		group, ok := current.(core.Grouper)
+		if !ok {
+			return nil, fmt.Errorf("command %q: %q is not a group", command, current.Name())
+		}
+		child, err := group.GetChildByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("command %q: %w", command, err)
+		}
+		current = child
+	}
+
+	exec, ok := current.(core.Executor)
+	if !ok {
+		return nil, fmt.Errorf("command %q is not executable", command)
+	}
+	return exec, nil
+}
+
+// Strings starting with '$' are JSONPath expressions and strings with '{{' are templates, both evaluated
+// against the document with the previous steps. JSONPath keeps the value type, templates result in strings
+func resolveReferences(value any, document map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "$") {
+			result, err := utils.GetJsonPath(v, document)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reference %q: %w", v, err)
+			}
+			return result, nil
+		}
+		if strings.Contains(v, "{{") {
+			tmpl, err := utils.NewTemplate(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid reference %q: %w", v, err)
+			}
+			return utils.ExecuteTemplateTrimmed(tmpl, document)
+		}
+		return v, nil
+
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			r, err := resolveReferences(item, document)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			r, err := resolveReferences(item, document)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,347 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/invopop/yaml"
+)
+
+const (
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+	statusSkipped   = "skipped"
+)
+
+// Same as the CLI flags: configs not set use their default only if required or one of these
+var configsAllowedToHaveDefault = []string{"env", "region"}
+
+type runParams struct {
+	Plan mgcSchemaPkg.FilePath `json:"plan" jsonschema:"description=Path of the YAML or JSON plan file,example=./plan.yaml" mgc:"positional"`
+}
+
+type stepResult struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty"`
+	Status  string `json:"status"`
+	Output  any    `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+var getRun = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "run",
+			Summary: "Run the commands of a plan file",
+			Description: `Run the commands listed in a YAML or JSON plan file, in order, without reloading the CLI for each one.
+
+Each step has a 'command' (ie: "virtual-machine instances get"), its 'parameters' and 'configs'. Configs not given
+use the current ones, as the CLI does. Steps may also set:
+
+- name: used to reference its results in the following steps
+- waitTermination: wait until the command finishes, as the '--cli.wait-termination' flag
+- capture: JSONPath applied to the result, stored as the step output instead of the whole result
+- onError: 'stop' (default) or 'continue' when the step fails, overriding the plan 'onError'
+
+Instead of a command, a step may have a 'parallel' list of steps, all run at the same time.
+
+When a step stops the plan, the error lists the status and output of every step, so the resources
+already created by the previous steps are known.
+
+Parameters and configs may reference previous steps with JSONPath (strings starting with '$') or
+templates (strings with '{{'), evaluated against {"steps": {"<name>": {"output", "parameters", "configs", "status", "error"}}}.
+
+Example:
+
+  onError: stop
+  steps:
+    - name: vm
+      command: virtual-machine instances create
+      parameters: {name: my-vm, machine_type: {name: BV1-1-10}, image: {name: cloud-ubuntu-24.04 LTS}, ssh_key_name: my-key}
+      capture: $.id
+    - parallel:
+        - command: virtual-machine instances get
+          parameters: {id: $.steps.vm.output}
+          waitTermination: true
+        - command: block-storage volumes create
+          parameters: {name: "{{.steps.vm.output}}-data", size: 10, type: {name: cloud_nvme1k}}`,
+		},
+		run,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template={{range .}}{{.status}}: {{.name}}{{if .command}} ({{.command}}){{end}}{{if .error}}\n  {{.error}}{{end}}\n{{end}}"
+	})
+})
+
+// The result is simplified to a generic value, as step outputs may be of any type
+func run(ctx context.Context, params runParams, _ struct{}) (core.Value, error) {
+	root := core.GrouperFromContext(ctx)
+	if root == nil {
+		return nil, fmt.Errorf("programming error: context did not contain SDK Grouper information")
+	}
+
+	data, err := os.ReadFile(string(params.Plan))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &plan{}
+	if err = yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid plan file: %w", err)
+	}
+	if err = p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan file: %w", err)
+	}
+
+	results, err := newRunner(root, config.FromContext(ctx)).run(ctx, p)
+	if err != nil {
+		return nil, &StoppedError{Results: results, Err: err}
+	}
+	return utils.SimplifyAny(results)
+}
+
+// StoppedError is returned when a failed step stops the plan. Results has every step, including
+// the ones that already succeeded, as executors don't return results together with errors
+type StoppedError struct {
+	Results []*stepResult
+	Err     error
+}
+
+func (e *StoppedError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	b.WriteString("\nsteps:")
+	for _, result := range e.Results {
+		fmt.Fprintf(&b, "\n  %s: %s", result.Status, result.Name)
+		if result.Command != "" {
+			fmt.Fprintf(&b, " (%s)", result.Command)
+		}
+		if result.Output != nil {
+			output, err := json.Marshal(result.Output)
+			if err == nil {
+				fmt.Fprintf(&b, "\n    output: %s", output)
+			}
+		}
+		if result.Error != "" {
+			fmt.Fprintf(&b, "\n    error: %s", result.Error)
+		}
+	}
+	return b.String()
+}
+
+func (e *StoppedError) Unwrap() error {
+	return e.Err
+}
+
+type runner struct {
+	root   core.Grouper
+	config *config.Config
+
+	mutex sync.Mutex
+	steps map[string]any
+}
+
+func newRunner(root core.Grouper, cfg *config.Config) *runner {
+	return &runner{root: root, config: cfg, steps: map[string]any{}}
+}
+
+// Copy of the document, so parallel steps may record their results while others read it.
+// Recorded entries are never modified, so a shallow copy is enough
+func (r *runner) document() map[string]any {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return map[string]any{"steps": maps.Clone(r.steps)}
+}
+
+func (r *runner) record(name string, entry map[string]any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.steps[name] = entry
+}
+
+func (r *runner) run(ctx context.Context, p *plan) (results []*stepResult, err error) {
+	stopped := false
+	for i, step := range p.Steps {
+		index := fmt.Sprint(i)
+
+		if len(step.Parallel) == 0 {
+			if stopped {
+				results = append(results, skippedResult(step, index))
+				continue
+			}
+			result := r.runStep(ctx, step, index)
+			results = append(results, result)
+			if result.Status == statusFailed && step.onError(p) == onErrorStop {
+				stopped = true
+				err = fmt.Errorf("step %s failed: %s", result.Name, result.Error)
+			}
+			continue
+		}
+
+		groupResults := make([]*stepResult, len(step.Parallel))
+		var wg sync.WaitGroup
+		for j, child := range step.Parallel {
+			childIndex := fmt.Sprintf("%s.%d", index, j)
+			if stopped {
+				groupResults[j] = skippedResult(child, childIndex)
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				groupResults[j] = r.runStep(ctx, child, childIndex)
+			}()
+		}
+		wg.Wait()
+
+		results = append(results, groupResults...)
+		for j, result := range groupResults {
+			if !stopped && result.Status == statusFailed && step.Parallel[j].onError(p) == onErrorStop {
+				stopped = true
+				err = fmt.Errorf("step %s failed: %s", result.Name, result.Error)
+			}
+		}
+	}
+
+	if err != nil {
+		logger().Debugw("batch stopped", "error", err, "results", results)
+	}
+	return results, err
+}
+
+func skippedResult(step *planStep, index string) *stepResult {
+	return &stepResult{Name: step.displayName(index), Command: step.Command, Status: statusSkipped}
+}
+
+func (r *runner) runStep(ctx context.Context, step *planStep, index string) (result *stepResult) {
+	result = &stepResult{Name: step.displayName(index), Command: step.Command}
+	entry := map[string]any{}
+	defer func() {
+		entry["status"] = result.Status
+		entry["error"] = result.Error
+		entry["output"] = result.Output
+		r.record(result.Name, entry)
+	}()
+
+	output, parameters, configs, err := r.execute(ctx, step)
+	entry["parameters"] = parameters
+	entry["configs"] = configs
+	if err != nil {
+		result.Status = statusFailed
+		result.Error = err.Error()
+		return
+	}
+
+	result.Status = statusSucceeded
+	result.Output = output
+	return
+}
+
+func (r *runner) execute(ctx context.Context, step *planStep) (output any, parameters core.Parameters, configs core.Configs, err error) {
+	exec, err := findExecutor(r.root, step.Command)
+	if err != nil {
+		return
+	}
+
+	document := r.document()
+	resolved, err := resolveReferences(map[string]any{"parameters": step.Parameters, "configs": step.Configs}, document)
+	if err != nil {
+		return
+	}
+	resolvedMap := resolved.(map[string]any)
+	parameters, _ = resolvedMap["parameters"].(map[string]any)
+	configs, _ = resolvedMap["configs"].(map[string]any)
+
+	parameters = r.fillParameters(exec, parameters)
+	configs = r.fillConfigs(exec, configs)
+
+	logger().Debugw("running step", "command", step.Command, "parameters", parameters, "configs", configs)
+
+	var result core.Result
+	if tExec, ok := core.ExecutorAs[core.TerminatorExecutor](exec); ok && step.WaitTermination {
+		result, err = tExec.ExecuteUntilTermination(ctx, parameters, configs)
+	} else {
+		result, err = exec.Execute(ctx, parameters, configs)
+	}
+	if err != nil {
+		return
+	}
+
+	withValue, ok := core.ResultAs[core.ResultWithValue](result)
+	if !ok {
+		if step.Capture != "" {
+			err = fmt.Errorf("unable to capture %q, the result has no value", step.Capture)
+		}
+		return
+	}
+
+	// Struct results (ie: static commands) become maps, so they may be used by JSONPath and templates
+	output, err = utils.SimplifyAny(withValue.Value())
+	if err != nil {
+		return
+	}
+	if step.Capture != "" {
+		output, err = utils.GetJsonPath(step.Capture, output)
+		if err != nil {
+			err = fmt.Errorf("unable to capture %q: %w", step.Capture, err)
+		}
+	}
+	return
+}
+
+// Required parameters not given use their default, as the CLI does
+func (r *runner) fillParameters(exec core.Executor, parameters core.Parameters) core.Parameters {
+	if parameters == nil {
+		parameters = core.Parameters{}
+	}
+
+	schema := exec.ParametersSchema()
+	for _, name := range schema.Required {
+		if _, ok := parameters[name]; ok {
+			continue
+		}
+		if propRef, ok := schema.Properties[name]; ok && propRef.Value != nil && propRef.Value.Default != nil {
+			parameters[name] = propRef.Value.Default
+		}
+	}
+	return parameters
+}
+
+// Configs not given use the current ones, or their default if required, as the CLI does
+func (r *runner) fillConfigs(exec core.Executor, configs core.Configs) core.Configs {
+	if configs == nil {
+		configs = core.Configs{}
+	}
+
+	schema := exec.ConfigsSchema()
+	for name, propRef := range schema.Properties {
+		if _, ok := configs[name]; ok {
+			continue
+		}
+
+		var value any
+		if r.config != nil {
+			if err := r.config.Get(name, &value); err == nil && value != nil {
+				configs[name] = value
+				continue
+			}
+		}
+
+		if propRef.Value != nil && propRef.Value.Default != nil &&
+			(slices.Contains(schema.Required, name) || slices.Contains(configsAllowedToHaveDefault, name)) {
+			configs[name] = propRef.Value.Default
+		}
+	}
+	return configs
+}
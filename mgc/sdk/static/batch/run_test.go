@@ -0,0 +1,159 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+type echoParams struct {
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty"`
+	Fail  bool   `json:"fail,omitempty"`
+}
+
+type echoResult struct {
+	Id    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+func newTestRoot(calls *atomic.Int32) core.Grouper {
+	echo := core.NewStaticExecute(
+		core.DescriptorSpec{Name: "echo", Description: "Echo the name"},
+		func(ctx context.Context, params echoParams, _ struct{}) (*echoResult, error) {
+			calls.Add(1)
+			if params.Fail {
+				return nil, fmt.Errorf("failed %s", params.Name)
+			}
+			return &echoResult{Id: "id-" + params.Name, Count: params.Count}, nil
+		},
+	)
+	test := core.NewStaticGroup(core.DescriptorSpec{Name: "test", Description: "Test commands"}, func() []core.Descriptor {
+		return []core.Descriptor{echo}
+	})
+	return core.NewStaticGroup(core.DescriptorSpec{Name: "root", Description: "Root"}, func() []core.Descriptor {
+		return []core.Descriptor{test}
+	})
+}
+
+func TestRunPlanReferences(t *testing.T) {
+	var calls atomic.Int32
+	p := &plan{Steps: []*planStep{
+		{Name: "first", Command: "test echo", Parameters: map[string]any{"name": "a", "count": 2}, Capture: "$.id"},
+		{Parallel: []*planStep{
+			{Name: "jsonpath", Command: "test/echo", Parameters: map[string]any{"name": "$.steps.first.output", "count": "$.steps.first.parameters.count"}},
+			{Name: "template", Command: "test echo", Parameters: map[string]any{"name": "{{.steps.first.output}}-b"}},
+		}},
+	}}
+	if err := p.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := newRunner(newTestRoot(&calls), nil).run(context.Background(), p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []*stepResult{
+		{Name: "first", Command: "test echo", Status: statusSucceeded, Output: "id-a"},
+		{Name: "jsonpath", Command: "test/echo", Status: statusSucceeded, Output: map[string]any{"id": "id-id-a", "count": 2}},
+		{Name: "template", Command: "test echo", Status: statusSucceeded, Output: map[string]any{"id": "id-id-a-b", "count": 0}},
+	}
+	// Compare as JSON, as the numeric types of simplified results don't matter
+	resultsJson, _ := json.Marshal(results)
+	expectedJson, _ := json.Marshal(expected)
+	if string(resultsJson) != string(expectedJson) {
+		t.Errorf("expected %s, got %s", expectedJson, resultsJson)
+	}
+}
+
+func TestRunPlanOnError(t *testing.T) {
+	steps := func(onError string) []*planStep {
+		return []*planStep{
+			{Name: "fails", Command: "test echo", Parameters: map[string]any{"name": "a", "fail": true}, OnError: onError},
+			{Name: "next", Command: "test echo", Parameters: map[string]any{"name": "b"}},
+			{Name: "unknown", Command: "test unknown"},
+		}
+	}
+
+	var calls atomic.Int32
+	results, err := newRunner(newTestRoot(&calls), nil).run(context.Background(), &plan{Steps: steps("")})
+	if err == nil {
+		t.Errorf("expected error stopping on the failed step")
+	}
+	if calls.Load() != 1 || results[1].Status != statusSkipped || results[2].Status != statusSkipped {
+		t.Errorf("expected following steps to be skipped, got %d calls and %+v %+v", calls.Load(), results[1], results[2])
+	}
+
+	calls.Store(0)
+	results, err = newRunner(newTestRoot(&calls), nil).run(context.Background(), &plan{OnError: onErrorStop, Steps: steps(onErrorContinue)})
+	if err == nil {
+		t.Errorf("expected error stopping on the unknown command")
+	}
+	if calls.Load() != 2 || results[0].Status != statusFailed || results[1].Status != statusSucceeded || results[2].Status != statusFailed {
+		t.Errorf("expected to continue after the first failure, got %d calls and %+v %+v %+v", calls.Load(), results[0], results[1], results[2])
+	}
+}
+
+func TestRunStoppedResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.yaml")
+	plan := `steps:
+  - name: created
+    command: test echo
+    parameters: {name: vm}
+    capture: $.id
+  - name: fails
+    command: test echo
+    parameters: {name: volume, fail: true}
+  - name: next
+    command: test echo
+    parameters: {name: other}
+`
+	if err := os.WriteFile(path, []byte(plan), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	root := newTestRoot(&calls)
+	ctx := core.NewGrouperContext(context.Background(), func() core.Grouper { return root })
+
+	_, err := run(ctx, runParams{Plan: mgcSchemaPkg.FilePath(path)}, struct{}{})
+	stoppedErr := &StoppedError{}
+	if !errors.As(err, &stoppedErr) {
+		t.Fatalf("expected StoppedError, got %v", err)
+	}
+
+	if len(stoppedErr.Results) != 3 || stoppedErr.Results[0].Output != "id-vm" || stoppedErr.Results[2].Status != statusSkipped {
+		t.Errorf("expected the results of all steps, got %+v", stoppedErr.Results)
+	}
+	for _, expected := range []string{"succeeded: created (test echo)", `output: "id-vm"`, "error: failed volume", "skipped: next"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error message to contain %q, got:\n%s", expected, err)
+		}
+	}
+}
+
+func TestPlanValidate(t *testing.T) {
+	invalid := []*plan{
+		{},
+		{OnError: "ignore", Steps: []*planStep{{Command: "test echo"}}},
+		{Steps: []*planStep{{Name: "a"}}},
+		{Steps: []*planStep{{Name: "a", Command: "test echo"}, {Name: "a", Command: "test echo"}}},
+		{Steps: []*planStep{{Command: "test echo", Parallel: []*planStep{{Command: "test echo"}}}}},
+		{Steps: []*planStep{{Parallel: []*planStep{{Parallel: []*planStep{{Command: "test echo"}}}}}}},
+	}
+	for i, p := range invalid {
+		if err := p.validate(); err == nil {
+			t.Errorf("plan %d: expected validation error", i)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package dev
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+var GetGroup = utils.NewLazyLoader(func() core.Grouper {
+	return core.NewStaticGroup(
+		core.DescriptorSpec{
+			Name:        "dev",
+			Summary:     "Tools to develop and test automation using the CLI",
+			Description: "Tools to develop and test scripts and automation using the CLI, without accessing Magalu Cloud",
+			GroupID:     "other",
+		},
+		func() []core.Descriptor {
+			return []core.Descriptor{
+				getMockServer(),
+			}
+		},
+	)
+})
@@ -0,0 +1,139 @@
+package dev
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Recursive schemas are cut at this depth
+const maxExampleDepth = 8
+
+var stringFormatExamples = map[string]string{
+	"date-time": "2024-01-01T00:00:00Z",
+	"date":      "2024-01-01",
+	"time":      "00:00:00",
+	"uuid":      "00000000-0000-4000-8000-000000000000",
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"url":       "https://example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"cidr":      "192.0.2.0/24",
+}
+
+// Builds a value valid for the schema, preferring the examples, defaults and enums given in the spec
+func exampleFromSchema(ref *openapi3.SchemaRef) any {
+	return cloneJSON(exampleFromSchemaDepth(ref, 0))
+}
+
+func exampleFromSchemaDepth(ref *openapi3.SchemaRef, depth int) any {
+	if ref == nil || ref.Value == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	s := ref.Value
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		return exampleFromAllOf(s.AllOf, depth)
+	case len(s.OneOf) > 0:
+		return exampleFromSchemaDepth(s.OneOf[0], depth)
+	case len(s.AnyOf) > 0:
+		return exampleFromSchemaDepth(s.AnyOf[0], depth)
+	}
+
+	switch {
+	case s.Type.Is(openapi3.TypeObject) || (s.Type == nil && len(s.Properties) > 0):
+		obj := make(map[string]any, len(s.Properties))
+		for name, prop := range s.Properties {
+			obj[name] = exampleFromSchemaDepth(prop, depth+1)
+		}
+		return obj
+
+	case s.Type.Is(openapi3.TypeArray):
+		items := make([]any, max(1, int(s.MinItems)))
+		for i := range items {
+			items[i] = exampleFromSchemaDepth(s.Items, depth+1)
+		}
+		return items
+
+	case s.Type.Is(openapi3.TypeString):
+		return exampleString(s)
+
+	case s.Type.Is(openapi3.TypeInteger):
+		return int64(exampleNumber(s))
+
+	case s.Type.Is(openapi3.TypeNumber):
+		return exampleNumber(s)
+
+	case s.Type.Is(openapi3.TypeBoolean):
+		return false
+	}
+
+	return nil
+}
+
+func exampleFromAllOf(refs openapi3.SchemaRefs, depth int) any {
+	merged := map[string]any{}
+	for _, ref := range refs {
+		value := exampleFromSchemaDepth(ref, depth)
+		m, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		maps.Copy(merged, m)
+	}
+	return merged
+}
+
+func exampleString(s *openapi3.Schema) string {
+	if example, ok := stringFormatExamples[s.Format]; ok {
+		return example
+	}
+
+	value := "string"
+	if minLength := int(s.MinLength); len(value) < minLength {
+		value += strings.Repeat("x", minLength-len(value))
+	}
+	if s.MaxLength != nil && uint64(len(value)) > *s.MaxLength {
+		value = value[:*s.MaxLength]
+	}
+	return value
+}
+
+func exampleNumber(s *openapi3.Schema) float64 {
+	switch {
+	case s.Min != nil && s.ExclusiveMin:
+		return *s.Min + 1
+	case s.Min != nil:
+		return *s.Min
+	case s.Max != nil && *s.Max < 0:
+		return *s.Max
+	}
+	return 0
+}
+
+// Examples from the spec are shared, so values stored or changed must be copies
+func cloneJSON(value any) any {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result any
+	if err = json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
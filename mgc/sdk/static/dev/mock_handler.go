@@ -0,0 +1,275 @@
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type mockServerOptions struct {
+	Latency     time.Duration
+	Jitter      time.Duration
+	ErrorRate   float64
+	ErrorStatus int
+	UpdateDelay time.Duration
+}
+
+// Same body as the Magalu Cloud APIs errors, so the CLI reports them the same way
+type mockError struct {
+	Message string `json:"message"`
+	Slug    string `json:"slug"`
+}
+
+type mockHandler struct {
+	routes  []*mockRoute
+	state   *mockState
+	options mockServerOptions
+}
+
+func newMockHandler(docs map[string]*openapi3.T, options mockServerOptions) *mockHandler {
+	return &mockHandler{
+		routes:  newMockRoutes(docs),
+		state:   newMockState(options.UpdateDelay),
+		options: options,
+	}
+}
+
+func writeMockJSON(w http.ResponseWriter, status int, value any) {
+	if value == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(mockError{Message: err.Error(), Slug: "mock_encoding_error"})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func decodeMockBody(r *http.Request) (any, error) {
+	if r.Body == nil || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return nil, nil
+	}
+
+	var body any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return body, nil
+}
+
+// First 2xx response of the operation and its JSON schema, nil if it has no body
+func successResponse(operation *openapi3.Operation) (int, *openapi3.SchemaRef) {
+	codes := []string{}
+	for code := range operation.Responses.Map() {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return http.StatusOK, nil
+	}
+	slices.Sort(codes)
+
+	status, err := strconv.Atoi(codes[0])
+	if err != nil {
+		status = http.StatusOK
+	}
+
+	response := operation.Responses.Value(codes[0])
+	if response == nil || response.Value == nil {
+		return status, nil
+	}
+	if mediaType := response.Value.Content.Get("application/json"); mediaType != nil {
+		return status, mediaType.Schema
+	}
+	return status, nil
+}
+
+// Replaces the list in the example with the items, the first array property if it's an object
+func withItems(example any, items []any) any {
+	switch e := example.(type) {
+	case []any:
+		return items
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(e)) {
+			if _, ok := e[key].([]any); ok {
+				e[key] = items
+				return e
+			}
+		}
+	}
+	return example
+}
+
+func (h *mockHandler) wait(ctx context.Context) error {
+	delay := h.options.Latency
+	if h.options.Jitter > 0 {
+		delay += rand.N(h.options.Jitter)
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+func (h *mockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.wait(r.Context()); err != nil {
+		return
+	}
+
+	if h.options.ErrorRate > 0 && rand.Float64() < h.options.ErrorRate {
+		logger().Infow("Injected error", "method", r.Method, "path", r.URL.Path, "status", h.options.ErrorStatus)
+		writeMockJSON(w, h.options.ErrorStatus, mockError{Message: "Error injected by the mock server", Slug: "mock_injected_error"})
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	route := matchMockRoute(h.routes, r.Method, path)
+	if route == nil {
+		logger().Infow("No operation matches the request", "method", r.Method, "path", r.URL.Path)
+		writeMockJSON(w, http.StatusNotFound, mockError{Message: fmt.Sprintf("No operation matches %s %s", r.Method, r.URL.Path), Slug: "not_found"})
+		return
+	}
+
+	body, err := decodeMockBody(r)
+	if err != nil {
+		writeMockJSON(w, http.StatusBadRequest, mockError{Message: fmt.Sprintf("Invalid JSON body: %s", err), Slug: "bad_request"})
+		return
+	}
+
+	status, value := h.handle(route, path, body)
+	logger().Infow("Mocked request", "method", r.Method, "path", r.URL.Path, "product", route.product, "operation", route.operation.OperationID, "status", status)
+	writeMockJSON(w, status, value)
+}
+
+func (h *mockHandler) handle(route *mockRoute, path string, body any) (int, any) {
+	status, schema := successResponse(route.operation)
+	example := exampleFromSchema(schema)
+
+	switch route.kind {
+	case mockRouteCreate:
+		return status, h.create(route, path, body, example)
+
+	case mockRouteList:
+		if !h.state.isKnown(path) {
+			return status, example
+		}
+		return status, withItems(example, h.state.list(path))
+	}
+
+	segments := strings.Split(path, "/")
+	collection := strings.Join(segments[:route.itemSegments-1], "/")
+	id := segments[route.itemSegments-1]
+
+	switch route.kind {
+	case mockRouteGet:
+		if item, ok := h.state.get(collection, id); ok {
+			return status, item
+		}
+	case mockRouteDelete:
+		if h.state.delete(collection, id) {
+			return status, example
+		}
+	case mockRouteUpdate:
+		if h.state.update(collection, id, itemFields(route, body)) {
+			return status, example
+		}
+	}
+
+	if h.state.isKnown(collection) {
+		return http.StatusNotFound, mockError{Message: fmt.Sprintf("%q not found", id), Slug: "not_found"}
+	}
+
+	// Not created through the mock server, answer with the example of the spec
+	if e, ok := example.(map[string]any); ok && route.kind == mockRouteGet {
+		if _, hasID := e["id"]; hasID {
+			e["id"] = id
+		}
+	}
+	return status, example
+}
+
+// Schema of the property of the object, looking into its allOf schemas as well
+func propertySchema(schema *openapi3.SchemaRef, name string) *openapi3.Schema {
+	if schema == nil || schema.Value == nil {
+		return nil
+	}
+	if prop := schema.Value.Properties[name]; prop != nil && prop.Value != nil {
+		return prop.Value
+	}
+	for _, ref := range schema.Value.AllOf {
+		if prop := propertySchema(ref, name); prop != nil {
+			return prop
+		}
+	}
+	return nil
+}
+
+// Fields of the request body that are valid item fields, so the items remain valid to their GET response schema
+func itemFields(route *mockRoute, body any) map[string]any {
+	fields, _ := body.(map[string]any)
+	if route.get == nil || len(fields) == 0 {
+		return fields
+	}
+
+	_, itemSchema := successResponse(route.get.operation)
+	if itemSchema == nil {
+		return fields
+	}
+
+	valid := make(map[string]any, len(fields))
+	for name, value := range fields {
+		if prop := propertySchema(itemSchema, name); prop != nil && prop.VisitJSON(value) == nil {
+			valid[name] = value
+		}
+	}
+	return valid
+}
+
+// Items are the example of their GET operation with the fields of the request body.
+// The response is the example of the create operation with the fields it shares with the item, such as the ID
+func (h *mockHandler) create(route *mockRoute, path string, body, example any) any {
+	item := map[string]any{}
+	if route.get != nil {
+		_, getSchema := successResponse(route.get.operation)
+		if e, ok := exampleFromSchema(getSchema).(map[string]any); ok {
+			item = e
+		}
+	}
+	maps.Copy(item, itemFields(route, body))
+
+	h.state.create(path, item)
+
+	response, ok := example.(map[string]any)
+	if !ok {
+		return example
+	}
+	for key := range response {
+		if value, ok := item[key]; ok {
+			response[key] = cloneJSON(value)
+		}
+	}
+	return response
+}
@@ -0,0 +1,238 @@
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const mockTestSpec = `
+openapi: 3.0.3
+info: {title: test, version: "1"}
+servers:
+  - url: https://{env}/{region}/compute
+    variables:
+      env: {default: api.magalu.cloud}
+      region: {default: br-se1}
+paths:
+  /v1/instances:
+    get:
+      operationId: list
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  instances:
+                    type: array
+                    items: {$ref: "#/components/schemas/Instance"}
+    post:
+      operationId: create
+      responses:
+        "202":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: string, format: uuid}
+  /v1/instances/{id}:
+    get:
+      operationId: get
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Instance"}
+    delete:
+      operationId: delete
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses:
+        "204": {description: ok}
+  /v1/instances/{id}/rename:
+    patch:
+      operationId: rename
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses:
+        "204": {description: ok}
+components:
+  schemas:
+    Instance:
+      type: object
+      properties:
+        id: {type: string, format: uuid}
+        name: {type: string, example: my-vm}
+        status: {type: string, enum: [creating, running]}
+        size: {type: integer, minimum: 10}
+`
+
+func newTestMockHandler(t *testing.T, options mockServerOptions) *mockHandler {
+	loader := openapi3.Loader{Context: context.Background()}
+	doc, err := loader.LoadFromData([]byte(mockTestSpec))
+	if err != nil {
+		t.Fatalf("unable to load spec: %v", err)
+	}
+	return newMockHandler(map[string]*openapi3.T{"virtual-machine": doc}, options)
+}
+
+func doMockRequest(t *testing.T, h http.Handler, method, path, body string) (int, map[string]any) {
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reqBody)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var result map[string]any
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code, result
+}
+
+func TestMockHandlerExamples(t *testing.T) {
+	h := newTestMockHandler(t, mockServerOptions{})
+
+	status, result := doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/instances/some-id", "")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	expected := map[string]any{"id": "some-id", "name": "my-vm", "status": "creating", "size": float64(10)}
+	for key, value := range expected {
+		if result[key] != value {
+			t.Errorf("expected %q to be %v, got %v", key, value, result[key])
+		}
+	}
+
+	status, _ = doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/unknown", "")
+	if status != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown paths, got %d", status)
+	}
+}
+
+func TestMockHandlerCRUD(t *testing.T) {
+	h := newTestMockHandler(t, mockServerOptions{})
+
+	status, created := doMockRequest(t, h, http.MethodPost, "/br-se1/compute/v1/instances", `{"name": "vm-1", "size": "invalid"}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", status)
+	}
+	id, _ := created["id"].(string)
+	if id == "" {
+		t.Fatalf("expected an ID, got %v", created)
+	}
+
+	_, item := doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/instances/"+id, "")
+	if item["name"] != "vm-1" || item["size"] != float64(10) {
+		t.Errorf("expected the valid fields of the request in the item, got %v", item)
+	}
+
+	_, list := doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/instances", "")
+	if instances, _ := list["instances"].([]any); len(instances) != 1 {
+		t.Errorf("expected 1 instance in the list, got %v", list)
+	}
+
+	status, _ = doMockRequest(t, h, http.MethodPatch, "/br-se1/compute/v1/instances/"+id+"/rename", `{"name": "vm-2"}`)
+	if status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	_, item = doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/instances/"+id, "")
+	if item["name"] != "vm-2" {
+		t.Errorf("expected updated name, got %v", item["name"])
+	}
+
+	status, _ = doMockRequest(t, h, http.MethodDelete, "/br-se1/compute/v1/instances/"+id, "")
+	if status != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	status, _ = doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/instances/"+id, "")
+	if status != http.StatusNotFound {
+		t.Errorf("expected 404 after delete, got %d", status)
+	}
+}
+
+func TestMockHandlerConcurrentRequests(t *testing.T) {
+	h := newTestMockHandler(t, mockServerOptions{})
+
+	_, created := doMockRequest(t, h, http.MethodPost, "/br-se1/compute/v1/instances", `{"name": "vm-1"}`)
+	id, _ := created["id"].(string)
+	if id == "" {
+		t.Fatalf("expected an ID, got %v", created)
+	}
+
+	requests := []struct{ method, path, body string }{
+		{http.MethodGet, "/br-se1/compute/v1/instances/" + id, ""},
+		{http.MethodGet, "/br-se1/compute/v1/instances", ""},
+		{http.MethodPatch, "/br-se1/compute/v1/instances/" + id + "/rename", `{"name": "vm-2"}`},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, r := range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var body io.Reader
+				if r.body != "" {
+					body = strings.NewReader(r.body)
+				}
+				req := httptest.NewRequest(r.method, r.path, body)
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, req)
+				if w.Code >= http.StatusBadRequest {
+					t.Errorf("%s %s: unexpected status %d", r.method, r.path, w.Code)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+func TestMockHandlerUpdateDelay(t *testing.T) {
+	h := newTestMockHandler(t, mockServerOptions{UpdateDelay: time.Minute})
+	now := time.Now()
+	h.state.now = func() time.Time { return now }
+
+	_, created := doMockRequest(t, h, http.MethodPost, "/br-se1/compute/v1/instances", `{"name": "vm-1"}`)
+	path := "/br-se1/compute/v1/instances/" + created["id"].(string)
+	doMockRequest(t, h, http.MethodPatch, path+"/rename", `{"name": "vm-2"}`)
+
+	if _, item := doMockRequest(t, h, http.MethodGet, path, ""); item["name"] != "vm-1" {
+		t.Errorf("expected the update not visible yet, got %v", item["name"])
+	}
+
+	now = now.Add(time.Minute)
+	if _, item := doMockRequest(t, h, http.MethodGet, path, ""); item["name"] != "vm-2" {
+		t.Errorf("expected the update visible after the delay, got %v", item["name"])
+	}
+}
+
+func TestMockHandlerErrorInjection(t *testing.T) {
+	h := newTestMockHandler(t, mockServerOptions{ErrorRate: 1, ErrorStatus: http.StatusTooManyRequests})
+
+	status, result := doMockRequest(t, h, http.MethodGet, "/br-se1/compute/v1/instances", "")
+	if status != http.StatusTooManyRequests || result["slug"] != "mock_injected_error" {
+		t.Errorf("expected injected error, got %d %v", status, result)
+	}
+}
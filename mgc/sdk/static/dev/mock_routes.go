@@ -0,0 +1,159 @@
+package dev
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type mockRouteKind int
+
+const (
+	// POST to a collection, ie: /v1/instances
+	mockRouteCreate mockRouteKind = iota
+	// GET a collection
+	mockRouteList
+	// GET an item, ie: /v1/instances/{id}
+	mockRouteGet
+	// DELETE an item
+	mockRouteDelete
+	// PUT or PATCH an item, or any method to an item action, ie: /v1/instances/{id}/rename
+	mockRouteUpdate
+)
+
+type mockRoute struct {
+	product   string
+	method    string
+	template  string
+	pattern   *regexp.Regexp
+	kind      mockRouteKind
+	operation *openapi3.Operation
+	// Path segments up to the last parameter, that identify an item. Zero if there's no parameter
+	itemSegments int
+	// Number of segments without parameters, routes with more of them are preferred
+	literals int
+	// Route to GET the items created or updated by this one, its response schema describes the items
+	get *mockRoute
+}
+
+var templateParamRegex = regexp.MustCompile(`\{[^}]+\}`)
+
+// Path of the first server of the document, without the scheme and host, ie: "https://{env}/{region}/compute" -> "/{region}/compute"
+func serverBasePath(doc *openapi3.T) string {
+	if len(doc.Servers) == 0 {
+		return ""
+	}
+
+	url := doc.Servers[0].URL
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+		if i = strings.Index(url, "/"); i >= 0 {
+			url = url[i:]
+		} else {
+			url = ""
+		}
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+func compileTemplate(template string) *regexp.Regexp {
+	pattern := strings.Builder{}
+	pattern.WriteByte('^')
+	last := 0
+	for _, loc := range templateParamRegex.FindAllStringIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		pattern.WriteString("([^/]+)")
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteByte('$')
+	return regexp.MustCompile(pattern.String())
+}
+
+func newMockRoute(product, method, template string, operation *openapi3.Operation) *mockRoute {
+	segments := strings.Split(template, "/")
+	route := &mockRoute{
+		product:   product,
+		method:    method,
+		template:  template,
+		pattern:   compileTemplate(template),
+		operation: operation,
+	}
+
+	for i, segment := range segments {
+		if templateParamRegex.MatchString(segment) {
+			route.itemSegments = i + 1
+		} else {
+			route.literals++
+		}
+	}
+
+	endsWithParam := route.itemSegments == len(segments)
+	switch {
+	case method == http.MethodPost && !endsWithParam:
+		route.kind = mockRouteCreate
+	case method == http.MethodGet && endsWithParam:
+		route.kind = mockRouteGet
+	case method == http.MethodGet:
+		route.kind = mockRouteList
+	case method == http.MethodDelete && endsWithParam:
+		route.kind = mockRouteDelete
+	default:
+		route.kind = mockRouteUpdate
+	}
+	return route
+}
+
+// Routes of all documents, the most specific ones first
+func newMockRoutes(docs map[string]*openapi3.T) []*mockRoute {
+	routes := []*mockRoute{}
+	for product, doc := range docs {
+		basePath := serverBasePath(doc)
+		for path, item := range doc.Paths.Map() {
+			template := strings.TrimSuffix(basePath+path, "/")
+			for method, operation := range item.Operations() {
+				routes = append(routes, newMockRoute(product, method, template, operation))
+			}
+		}
+	}
+
+	byTemplate := map[string]*mockRoute{}
+	for _, route := range routes {
+		if route.kind == mockRouteGet {
+			byTemplate[templateParamRegex.ReplaceAllString(route.template, "{}")] = route
+		}
+	}
+	for _, route := range routes {
+		var itemTemplate string
+		switch route.kind {
+		case mockRouteCreate:
+			itemTemplate = route.template + "/{}"
+		case mockRouteUpdate:
+			itemTemplate = strings.Join(strings.Split(route.template, "/")[:route.itemSegments], "/")
+		default:
+			continue
+		}
+		route.get = byTemplate[templateParamRegex.ReplaceAllString(itemTemplate, "{}")]
+	}
+
+	slices.SortFunc(routes, func(a, b *mockRoute) int {
+		if a.literals != b.literals {
+			return b.literals - a.literals
+		}
+		return strings.Compare(a.template+" "+a.method, b.template+" "+b.method)
+	})
+	return routes
+}
+
+func matchMockRoute(routes []*mockRoute, method, path string) *mockRoute {
+	path = strings.TrimSuffix(path, "/")
+	for _, route := range routes {
+		if route.method == method && route.pattern.MatchString(path) {
+			return route
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package dev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	defaultMockServerAddress     = "127.0.0.1:8080"
+	defaultMockServerErrorStatus = http.StatusServiceUnavailable
+	mockServerShutdownTimeout    = 5 * time.Second
+)
+
+type mockServerParams struct {
+	Address     string   `json:"address,omitempty" jsonschema:"description=Address the server listens on,default=127.0.0.1:8080"`
+	Products    []string `json:"products,omitempty" jsonschema:"description=Only serve these products\\, all of them if empty,example=virtual-machine"`
+	Latency     string   `json:"latency,omitempty" jsonschema:"description=Delay added to every response,example=200ms"`
+	Jitter      string   `json:"jitter,omitempty" jsonschema:"description=Random delay up to this duration added to the latency,example=100ms"`
+	ErrorRate   float64  `json:"error-rate,omitempty" jsonschema:"description=Fraction of the requests answered with an error\\, from 0 to 1,minimum=0,maximum=1,example=0.1"`
+	ErrorStatus int      `json:"error-status,omitempty" jsonschema:"description=HTTP status of the injected errors,default=503,minimum=400,maximum=599"`
+	UpdateDelay string   `json:"update-delay,omitempty" jsonschema:"description=Delay until changes to resources are visible\\, so commands waiting for them have to poll,example=5s"`
+}
+
+type mockServerResult struct {
+	Address  string   `json:"address"`
+	Products []string `json:"products"`
+}
+
+var getMockServer = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "mock-server",
+			Summary: "Serve a mock of the Magalu Cloud APIs",
+			Description: `Serve a mock of the Magalu Cloud APIs described by the OpenAPI specs embedded in the CLI, until interrupted.
+
+Responses are built from the examples and schemas of the specs. Resources created through the mock server are kept
+in memory: they're returned by the get and list operations, changed by the update operations and removed by the
+delete ones. Each product is served at the path of its server, ie: /br-se1/compute/v1/instances.
+
+To send the CLI requests to the mock server, set the MGC_MOCK_SERVER_URL environment variable and use any API key:
+
+  MGC_MOCK_SERVER_URL=http://127.0.0.1:8080 mgc virtual-machine instances list --api-key=mock
+
+Use 'latency', 'jitter', 'error-rate' and 'update-delay' to test how scripts behave on slow or failing requests,
+and while waiting for changes with '--cli.wait-termination' or '--cli.retry-until'.`,
+		},
+		mockServer,
+	)
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Mock server at {{.address}} stopped\n"
+	})
+})
+
+func parseOptionalDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, core.UsageError{Err: fmt.Errorf("invalid %s %q: %w", name, value, err)}
+	}
+	return d, nil
+}
+
+func newMockServerOptions(p mockServerParams) (options mockServerOptions, err error) {
+	if options.Latency, err = parseOptionalDuration("latency", p.Latency); err != nil {
+		return
+	}
+	if options.Jitter, err = parseOptionalDuration("jitter", p.Jitter); err != nil {
+		return
+	}
+	if options.UpdateDelay, err = parseOptionalDuration("update-delay", p.UpdateDelay); err != nil {
+		return
+	}
+	options.ErrorRate = p.ErrorRate
+	options.ErrorStatus = p.ErrorStatus
+	if options.ErrorStatus == 0 {
+		options.ErrorStatus = defaultMockServerErrorStatus
+	}
+	return
+}
+
+func mockServer(ctx context.Context, p mockServerParams, _ struct{}) (*mockServerResult, error) {
+	options, err := newMockServerOptions(p)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := openapi.LoadDocuments(ctx, openapi.GetEmbedLoader())
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the OpenAPI specs: %w", err)
+	}
+	if len(p.Products) > 0 {
+		for _, product := range p.Products {
+			if _, ok := docs[product]; !ok {
+				return nil, core.UsageError{Err: fmt.Errorf("unknown product %q, expected one of %v", product, slices.Sorted(maps.Keys(docs)))}
+			}
+		}
+		maps.DeleteFunc(docs, func(product string, _ *openapi3.T) bool { return !slices.Contains(p.Products, product) })
+	}
+
+	addr := p.Address
+	if addr == "" {
+		addr = defaultMockServerAddress
+	}
+	// Listen so we can fail early on bad address, before serving
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mockServerResult{Address: "http://" + listener.Addr().String(), Products: slices.Sorted(maps.Keys(docs))}
+	srv := &http.Server{Handler: newMockHandler(docs, options)}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), mockServerShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			srv.Close() // aggressively try to close it
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "Mock server listening at %s, press Ctrl+C to stop\n", result.Address)
+	if err = srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package dev
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type mockItem struct {
+	value map[string]any
+	// Updates only visible after visibleAt, so commands waiting for them have to poll
+	pending   map[string]any
+	visibleAt time.Time
+}
+
+type mockCollection struct {
+	items map[string]*mockItem
+	order []string
+}
+
+// In-memory state of the resources created through the mock server, keyed by the collection path
+type mockState struct {
+	mutex       sync.Mutex
+	collections map[string]*mockCollection
+	updateDelay time.Duration
+	lastID      int
+	now         func() time.Time
+}
+
+func newMockState(updateDelay time.Duration) *mockState {
+	return &mockState{
+		collections: map[string]*mockCollection{},
+		updateDelay: updateDelay,
+		now:         time.Now,
+	}
+}
+
+// IDs are deterministic, so tests can rely on them, and valid UUIDs
+func (s *mockState) newID() string {
+	s.lastID++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.lastID)
+}
+
+// A collection is known once something was created in it. Until then, responses are the spec examples
+func (s *mockState) isKnown(collection string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.collections[collection]
+	return ok
+}
+
+func (s *mockState) create(collection string, value map[string]any) (id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		c = &mockCollection{items: map[string]*mockItem{}}
+		s.collections[collection] = c
+	}
+
+	id = s.newID()
+	value["id"] = id
+	c.items[id] = &mockItem{value: value}
+	c.order = append(c.order, id)
+	return id
+}
+
+func (s *mockState) applyPending(item *mockItem) {
+	if item.pending != nil && !s.now().Before(item.visibleAt) {
+		maps.Copy(item.value, item.pending)
+		item.pending = nil
+	}
+}
+
+func (s *mockState) get(collection, id string) (map[string]any, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return nil, false
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+	s.applyPending(item)
+	// Copied under the lock, as update changes the stored map in place
+	value, _ := cloneJSON(item.value).(map[string]any)
+	return value, true
+}
+
+func (s *mockState) list(collection string) []any {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return nil
+	}
+	result := make([]any, 0, len(c.order))
+	for _, id := range c.order {
+		item := c.items[id]
+		s.applyPending(item)
+		result = append(result, cloneJSON(item.value))
+	}
+	return result
+}
+
+func (s *mockState) update(collection, id string, changes map[string]any) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return false
+	}
+	item, ok := c.items[id]
+	if !ok {
+		return false
+	}
+
+	s.applyPending(item)
+	if s.updateDelay <= 0 {
+		maps.Copy(item.value, changes)
+		return true
+	}
+	if item.pending == nil {
+		item.pending = map[string]any{}
+	}
+	maps.Copy(item.pending, changes)
+	item.visibleAt = s.now().Add(s.updateDelay)
+	return true
+}
+
+func (s *mockState) delete(collection, id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return false
+	}
+	if _, ok = c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	c.order = slices.DeleteFunc(c.order, func(other string) bool { return other == id })
+	return true
+}
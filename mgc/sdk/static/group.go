@@ -3,8 +3,11 @@ package static
 import (
 	"github.com/MagaluCloud/magalu/mgc/core"
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/alias"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/auth"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/batch"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/config"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/dev"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/profile"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/workspace"
@@ -19,7 +22,10 @@ var GetGroup = utils.NewLazyLoader(func() core.Grouper {
 				config.GetGroup(),
 				object_storage.GetGroup(),
 				workspace.GetGroup(),
+				alias.GetGroup(),
 				profile.GetGroup(),
+				batch.GetGroup(),
+				dev.GetGroup(),
 			}
 		},
 	)
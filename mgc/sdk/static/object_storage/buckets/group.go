@@ -6,6 +6,7 @@ import (
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/acl"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/cors"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/label"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/lifecycle"
 	object_lock "github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/object-lock"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/policy"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/buckets/versioning"
@@ -30,6 +31,7 @@ var GetGroup = utils.NewLazyLoader[core.Grouper](func() core.Grouper {
 				label.GetGroup(),       // object-storage buckets label
 				object_lock.GetGroup(), // object-storage buckets object-lock
 				cors.GetGroup(),        // object-storage buckets cors
+				lifecycle.GetGroup(),   // object-storage buckets lifecycle
 			}
 		},
 	)
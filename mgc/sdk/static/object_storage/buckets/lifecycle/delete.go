@@ -0,0 +1,68 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type deleteBucketLifecycleParams struct {
+	Bucket common.BucketName `json:"dst" jsonschema:"description=Name of the bucket to delete the lifecycle rules from,example=my-bucket" mgc:"positional"`
+}
+
+var getDelete = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "delete",
+			Description: "Delete all lifecycle rules of the specified bucket",
+		},
+		deleteLifecycle,
+	)
+
+	exec = core.NewExecuteFormat(exec, func(exec core.Executor, result core.Result) string {
+		return fmt.Sprintf("Successfully deleted lifecycle rules of bucket %q", result.Source().Parameters["dst"])
+	})
+
+	return exec
+})
+
+func deleteLifecycle(ctx context.Context, params deleteBucketLifecycleParams, cfg common.Config) (result core.Value, err error) {
+	req, err := newDeleteBucketLifecycleRequest(ctx, params, cfg)
+	if err != nil {
+		return
+	}
+
+	resp, err := common.SendRequest(ctx, req, cfg)
+	if err != nil {
+		return
+	}
+
+	err = common.ExtractErr(resp, req)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func newDeleteBucketLifecycleRequest(ctx context.Context, p deleteBucketLifecycleParams, cfg common.Config) (*http.Request, error) {
+	url, err := common.BuildBucketHostURL(cfg, p.Bucket)
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	query := url.Query()
+	query.Add("lifecycle", "")
+	url.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
@@ -0,0 +1,63 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type GetBucketLifecycleParams struct {
+	Bucket common.BucketName `json:"dst" jsonschema:"description=Specifies the bucket whose lifecycle rules are being requested" mgc:"positional"`
+}
+
+var getGet = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "get",
+			Description: "Get the lifecycle rules for the specified bucket",
+		},
+		getLifecycle,
+	)
+	exec = core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "json"
+	})
+	return exec
+})
+
+func getLifecycle(ctx context.Context, params GetBucketLifecycleParams, cfg common.Config) (result map[string]any, err error) {
+	req, err := newGetLifecycleRequest(ctx, cfg, params.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := common.SendRequest(ctx, req, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycleConfig, err := common.UnwrapResponse[LifecycleConfiguration](res, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result = map[string]any{
+		"Rules": lifecycleConfig.Rules,
+	}
+	return
+}
+
+func newGetLifecycleRequest(ctx context.Context, cfg common.Config, bucketName common.BucketName) (*http.Request, error) {
+	url, err := common.BuildBucketHostURL(cfg, bucketName)
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	query := url.Query()
+	query.Add("lifecycle", "")
+	url.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+}
@@ -0,0 +1,22 @@
+package lifecycle
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+var GetGroup = utils.NewLazyLoader(func() core.Grouper {
+	return core.NewStaticGroup(
+		core.DescriptorSpec{
+			Name:        "lifecycle",
+			Description: "Lifecycle rules expiring objects, noncurrent versions and incomplete multipart uploads",
+		},
+		func() []core.Descriptor {
+			return []core.Descriptor{
+				getGet(),    // object-storage buckets lifecycle get
+				getSet(),    // object-storage buckets lifecycle set
+				getDelete(), // object-storage buckets lifecycle delete
+			}
+		},
+	)
+})
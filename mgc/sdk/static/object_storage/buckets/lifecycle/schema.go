@@ -0,0 +1,121 @@
+package lifecycle
+
+import (
+	"fmt"
+	"time"
+
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func newPositiveIntegerSchema() *mgcSchemaPkg.Schema {
+	s := mgcSchemaPkg.NewIntegerSchema()
+	s.Min = openapi3.Float64Ptr(1)
+	return s
+}
+
+// Validates the lifecycle configuration given to "set", before it's converted to XML
+var getLifecycleConfigurationSchema = utils.NewLazyLoader(func() *mgcSchemaPkg.Schema {
+	status := mgcSchemaPkg.NewStringSchema()
+	status.Enum = []any{"Enabled", "Disabled"}
+
+	tag := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"Key":   mgcSchemaPkg.NewStringSchema(),
+		"Value": mgcSchemaPkg.NewStringSchema(),
+	}, []string{"Key", "Value"})
+
+	filter := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"Prefix": mgcSchemaPkg.NewStringSchema(),
+		"Tags":   mgcSchemaPkg.NewArraySchema(tag),
+	}, nil)
+
+	date := mgcSchemaPkg.NewStringSchema()
+	date.Description = "Date in the format YYYY-MM-DD or an RFC 3339 midnight UTC timestamp"
+
+	expiration := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"Days":                      newPositiveIntegerSchema(),
+		"Date":                      date,
+		"ExpiredObjectDeleteMarker": mgcSchemaPkg.NewBooleanSchema(),
+	}, nil)
+
+	noncurrentExpiration := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"NoncurrentDays":          newPositiveIntegerSchema(),
+		"NewerNoncurrentVersions": newPositiveIntegerSchema(),
+	}, []string{"NoncurrentDays"})
+
+	abortMultipart := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"DaysAfterInitiation": newPositiveIntegerSchema(),
+	}, []string{"DaysAfterInitiation"})
+
+	id := mgcSchemaPkg.NewStringSchema()
+	id.MaxLength = openapi3.Uint64Ptr(255)
+
+	rule := mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"ID":                             id,
+		"Status":                         status,
+		"Filter":                         filter,
+		"Expiration":                     expiration,
+		"NoncurrentVersionExpiration":    noncurrentExpiration,
+		"AbortIncompleteMultipartUpload": abortMultipart,
+	}, []string{"Status"})
+	// At least one action
+	rule.AnyOf = mgcSchemaPkg.SchemaRefs{
+		{Value: &openapi3.Schema{Required: []string{"Expiration"}}},
+		{Value: &openapi3.Schema{Required: []string{"NoncurrentVersionExpiration"}}},
+		{Value: &openapi3.Schema{Required: []string{"AbortIncompleteMultipartUpload"}}},
+	}
+
+	rules := mgcSchemaPkg.NewArraySchema(rule)
+	rules.MinItems = 1
+	rules.MaxItems = openapi3.Uint64Ptr(1000)
+
+	return mgcSchemaPkg.NewObjectSchema(map[string]*mgcSchemaPkg.Schema{
+		"Rules": rules,
+	}, []string{"Rules"})
+})
+
+// Checks what the schema can't express and normalizes the dates to the midnight UTC timestamps S3 expects
+func validateLifecycleRules(rules []LifecycleRule) error {
+	for i := range rules {
+		expiration := rules[i].Expiration
+		if expiration == nil {
+			continue
+		}
+
+		set := 0
+		for _, isSet := range []bool{expiration.Days > 0, expiration.Date != "", expiration.ExpiredObjectDeleteMarker} {
+			if isSet {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("rule %d: expiration must have exactly one of Days, Date or ExpiredObjectDeleteMarker", i)
+		}
+
+		if expiration.Date != "" {
+			date, err := parseExpirationDate(expiration.Date)
+			if err != nil {
+				return fmt.Errorf("rule %d: %w", i, err)
+			}
+			expiration.Date = date.Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+func parseExpirationDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, value)
+	}
+	if err != nil {
+		return date, fmt.Errorf("invalid expiration date %q, expected YYYY-MM-DD", value)
+	}
+
+	date = date.UTC()
+	if date != date.Truncate(24*time.Hour) {
+		return date, fmt.Errorf("expiration date %q must be at midnight UTC", value)
+	}
+	return date, nil
+}
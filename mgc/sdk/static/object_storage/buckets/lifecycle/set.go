@@ -0,0 +1,120 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type setBucketLifecycleParams struct {
+	Bucket    common.BucketName `json:"dst" jsonschema:"description=Name of the bucket to set the lifecycle rules for,example=my-bucket" mgc:"positional"`
+	Lifecycle map[string]any    `json:"lifecycle" jsonschema:"description=Lifecycle config as JSON or YAML file or inline JSON,example=@./lifecycle.yaml or '{\"Rules\": [{\"ID\": \"expire-logs\"\\, \"Status\": \"Enabled\"\\, \"Filter\": {\"Prefix\": \"logs/\"}\\, \"Expiration\": {\"Days\": 30}}]}'" mgc:"positional"`
+}
+
+var getSet = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "set",
+			Summary: "Set the lifecycle rules for the specified bucket",
+			Description: `Set the lifecycle rules for the specified bucket, replacing the existing ones.
+Each rule has a Status (Enabled or Disabled), an optional Filter by Prefix and Tags and at least one action:
+Expiration (Days, Date or ExpiredObjectDeleteMarker), NoncurrentVersionExpiration (NoncurrentDays)
+or AbortIncompleteMultipartUpload (DaysAfterInitiation)`,
+		},
+		setLifecycle,
+	)
+
+	exec = core.NewExecuteFormat(exec, func(exec core.Executor, result core.Result) string {
+		return fmt.Sprintf("Successfully set lifecycle rules for bucket %q", result.Source().Parameters["dst"])
+	})
+
+	return exec
+})
+
+func setLifecycle(ctx context.Context, params setBucketLifecycleParams, cfg common.Config) (result core.Value, err error) {
+	req, err := newSetBucketLifecycleRequest(ctx, params, cfg)
+	if err != nil {
+		return
+	}
+
+	resp, err := common.SendRequest(ctx, req, cfg)
+	if err != nil {
+		return
+	}
+
+	err = common.ExtractErr(resp, req)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func parseLifecycleConfiguration(value map[string]any) (lifecycleConfig LifecycleConfiguration, err error) {
+	if err = getLifecycleConfigurationSchema().VisitJSON(value); err != nil {
+		return lifecycleConfig, core.UsageError{Err: fmt.Errorf("invalid lifecycle configuration: %w", err)}
+	}
+
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return lifecycleConfig, fmt.Errorf("failed to marshal lifecycle input: %w", err)
+	}
+
+	if err = json.Unmarshal(jsonBytes, &lifecycleConfig); err != nil {
+		return lifecycleConfig, core.UsageError{Err: fmt.Errorf("invalid lifecycle JSON: %w", err)}
+	}
+
+	if err = validateLifecycleRules(lifecycleConfig.Rules); err != nil {
+		return lifecycleConfig, core.UsageError{Err: err}
+	}
+
+	lifecycleConfig.XMLns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	return lifecycleConfig, nil
+}
+
+func newSetBucketLifecycleRequest(ctx context.Context, p setBucketLifecycleParams, cfg common.Config) (*http.Request, error) {
+	url, err := common.BuildBucketHostURL(cfg, p.Bucket)
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	lifecycleConfig, err := parseLifecycleConfiguration(p.Lifecycle)
+	if err != nil {
+		return nil, err
+	}
+
+	xmlBytes, err := xml.Marshal(lifecycleConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to XML: %w", err)
+	}
+	xmlWithHeader := append([]byte(xml.Header), xmlBytes...)
+
+	query := url.Query()
+	query.Add("lifecycle", "")
+	url.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/xml")
+
+	getBody := func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(xmlWithHeader)), nil
+	}
+
+	req.Body, _ = getBody()
+	req.GetBody = getBody
+	req.ContentLength = int64(len(xmlWithHeader))
+
+	return req, nil
+}
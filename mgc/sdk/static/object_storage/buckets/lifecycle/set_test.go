@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+)
+
+func TestParseLifecycleConfiguration(t *testing.T) {
+	config, err := parseLifecycleConfiguration(map[string]any{
+		"Rules": []any{
+			map[string]any{
+				"ID":         "expire-logs",
+				"Status":     "Enabled",
+				"Filter":     map[string]any{"Prefix": "logs/"},
+				"Expiration": map[string]any{"Date": "2030-01-01"},
+			},
+			map[string]any{
+				"Status":                      "Enabled",
+				"Filter":                      map[string]any{"Tags": []any{map[string]any{"Key": "tmp", "Value": "true"}}},
+				"NoncurrentVersionExpiration": map[string]any{"NoncurrentDays": float64(7)},
+			},
+			map[string]any{
+				"Status":                         "Disabled",
+				"Filter":                         map[string]any{"Prefix": "uploads/", "Tags": []any{map[string]any{"Key": "tmp", "Value": "true"}}},
+				"AbortIncompleteMultipartUpload": map[string]any{"DaysAfterInitiation": float64(1)},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := xml.Marshal(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body := string(data)
+	for _, expected := range []string{
+		`<Filter><Prefix>logs/</Prefix></Filter><Expiration><Date>2030-01-01T00:00:00Z</Date></Expiration>`,
+		`<Filter><Tag><Key>tmp</Key><Value>true</Value></Tag></Filter><NoncurrentVersionExpiration><NoncurrentDays>7</NoncurrentDays></NoncurrentVersionExpiration>`,
+		`<Filter><And><Prefix>uploads/</Prefix><Tag><Key>tmp</Key><Value>true</Value></Tag></And></Filter>`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in %s", expected, body)
+		}
+	}
+
+	var parsed LifecycleConfiguration
+	if err = xml.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter := parsed.Rules[2].Filter; filter.Prefix != "uploads/" || len(filter.Tags) != 1 {
+		t.Errorf("unexpected filter after round trip: %#v", filter)
+	}
+}
+
+func TestParseLifecycleConfigurationInvalid(t *testing.T) {
+	for name, value := range map[string]map[string]any{
+		"no rules":       {"Rules": []any{}},
+		"no action":      {"Rules": []any{map[string]any{"Status": "Enabled"}}},
+		"invalid status": {"Rules": []any{map[string]any{"Status": "On", "Expiration": map[string]any{"Days": float64(1)}}}},
+		"zero days":      {"Rules": []any{map[string]any{"Status": "Enabled", "Expiration": map[string]any{"Days": float64(0)}}}},
+		"days and date":  {"Rules": []any{map[string]any{"Status": "Enabled", "Expiration": map[string]any{"Days": float64(1), "Date": "2030-01-01"}}}},
+		"invalid date":   {"Rules": []any{map[string]any{"Status": "Enabled", "Expiration": map[string]any{"Date": "2030-01-01T10:00:00Z"}}}},
+	} {
+		_, err := parseLifecycleConfiguration(value)
+		if !errors.As(err, new(core.UsageError)) {
+			t.Errorf("%s: expected usage error, got %v", name, err)
+		}
+	}
+}
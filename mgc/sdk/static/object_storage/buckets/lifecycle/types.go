@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"encoding/xml"
+)
+
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	XMLns   string          `xml:"xmlns,attr"`
+	Rules   []LifecycleRule `xml:"Rule" json:"Rules"`
+}
+
+type LifecycleRule struct {
+	ID                             string                          `xml:"ID,omitempty" json:"ID,omitempty"`
+	Status                         string                          `xml:"Status" json:"Status"`
+	Filter                         LifecycleFilter                 `xml:"Filter" json:"Filter,omitempty"`
+	Expiration                     *Expiration                     `xml:"Expiration,omitempty" json:"Expiration,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty" json:"NoncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty" json:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+type Expiration struct {
+	Days                      int    `xml:"Days,omitempty" json:"Days,omitempty"`
+	Date                      string `xml:"Date,omitempty" json:"Date,omitempty"`
+	ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker,omitempty" json:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays          int `xml:"NoncurrentDays" json:"NoncurrentDays"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty" json:"NewerNoncurrentVersions,omitempty"`
+}
+
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation" json:"DaysAfterInitiation"`
+}
+
+type Tag struct {
+	Key   string `xml:"Key" json:"Key"`
+	Value string `xml:"Value" json:"Value"`
+}
+
+// The rule applies to the objects matching both the prefix and all the tags. Empty matches all objects
+type LifecycleFilter struct {
+	Prefix string `json:"Prefix,omitempty"`
+	Tags   []Tag  `json:"Tags,omitempty"`
+}
+
+type lifecycleFilterAnd struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag,omitempty"`
+}
+
+// S3 expects a single condition directly in the filter and multiple ones inside <And>
+type lifecycleFilterXML struct {
+	Prefix *string             `xml:"Prefix,omitempty"`
+	Tag    *Tag                `xml:"Tag,omitempty"`
+	And    *lifecycleFilterAnd `xml:"And,omitempty"`
+}
+
+func (f LifecycleFilter) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var out lifecycleFilterXML
+	switch {
+	case len(f.Tags) == 0:
+		out.Prefix = &f.Prefix
+	case len(f.Tags) == 1 && f.Prefix == "":
+		out.Tag = &f.Tags[0]
+	default:
+		out.And = &lifecycleFilterAnd{Prefix: f.Prefix, Tags: f.Tags}
+	}
+	return e.EncodeElement(out, start)
+}
+
+func (f *LifecycleFilter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var in lifecycleFilterXML
+	if err := d.DecodeElement(&in, &start); err != nil {
+		return err
+	}
+
+	*f = LifecycleFilter{}
+	if in.Prefix != nil {
+		f.Prefix = *in.Prefix
+	}
+	if in.Tag != nil {
+		f.Tags = []Tag{*in.Tag}
+	}
+	if in.And != nil {
+		f.Prefix = in.And.Prefix
+		f.Tags = in.And.Tags
+	}
+	return nil
+}
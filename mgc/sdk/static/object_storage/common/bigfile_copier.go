@@ -24,6 +24,7 @@ type bigFileCopier struct {
 	progressReporter *progress_report.BytesReporter
 	version          string
 	storageClass     string
+	metadata         ObjectMetadata
 }
 
 var _ copier = (*bigFileCopier)(nil)
@@ -39,15 +40,12 @@ func (u *bigFileCopier) newPreparationRequest(ctx context.Context) (*http.Reques
 	}
 	req.Method = http.MethodPost
 	req.Header.Set("Content-Type", "application/octet-stream")
+	u.metadata.setHeaders(req)
 	if u.storageClass != "" {
 		req.Header.Set("X-Amz-Storage-Class", u.storageClass)
 	}
 	q := req.URL.Query()
 	q.Set("uploads", "")
-
-	if u.version != "" {
-		q.Set("versionId", u.version)
-	}
 	req.URL.RawQuery = q.Encode()
 
 	return req, nil
@@ -190,7 +188,7 @@ func (u *bigFileCopier) Copy(ctx context.Context) error {
 	}
 
 	chunkChan := pipeline.PrepareWriteChunks(ctx, nil, u.fileSize, int64(u.cfg.chunkSizeInBytes()))
-	partChan := pipeline.ParallelProcess(ctx, u.cfg.Workers, chunkChan, u.createPartSenderProcessor(cancel, uploadId), nil)
+	partChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "copy parts"), u.cfg.Workers, chunkChan, u.createPartSenderProcessor(cancel, uploadId), nil)
 
 	parts, err := pipeline.SliceItemConsumer[[]completionPart](ctx, partChan)
 	if err != nil {
@@ -13,25 +13,48 @@ import (
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
 )
 
+// bigFileDownloader downloads the object with byte-range requests split across the
+// workers, writing into a ".part" file next to the destination. Finished chunks are
+// recorded in a ".part.state" file, so an interrupted download resumes where it stopped.
 type bigFileDownloader struct {
 	cfg              Config
 	src              mgcSchemaPkg.URI
 	dst              mgcSchemaPkg.FilePath
 	version          string
 	fileSize         int64
+	etag             string
+	head             HeadObjectResponse
+	encryption       *objectEncryption
 	progressReporter *progress_report.BytesReporter
+	state            *downloadState
 }
 
+var _ downloader = (*bigFileDownloader)(nil)
+
 func (u *bigFileDownloader) createPartDownloaderProcessor(cancel context.CancelCauseFunc, cfg Config) pipeline.Processor[pipeline.WriteableChunk, error] {
 	return func(ctx context.Context, chunk pipeline.WriteableChunk) (error, pipeline.ProcessStatus) {
+		if u.state.isDone(chunk.StartOffset) {
+			logger().Debugw("Skipping chunk downloaded by previous execution", "start", chunk.StartOffset, "end", chunk.EndOffset)
+			return nil, pipeline.ProcessSkip
+		}
+
 		req, err := NewDownloadRequest(ctx, cfg, u.src, u.version)
 		if err != nil {
 			cancel(err)
 			return err, pipeline.ProcessAbort
 		}
 
-		downloadByteRange := fmt.Sprintf("bytes=%d-%d", chunk.StartOffset, chunk.EndOffset)
+		rangeStart, rangeEnd := chunk.StartOffset, chunk.EndOffset
+		if u.encryption != nil {
+			// Chunks start at segment boundaries, so each one is decrypted on its own
+			rangeStart, rangeEnd = encryptedRange(chunk.StartOffset, chunk.EndOffset)
+		}
+		downloadByteRange := fmt.Sprintf("bytes=%d-%d", rangeStart, rangeEnd)
 		req.Header.Set("Range", downloadByteRange)
+		// Fail instead of mixing chunks of different contents if the object changes mid-download
+		if u.etag != "" {
+			req.Header.Set("If-Match", u.etag)
+		}
 
 		resp, err := SendRequest(ctx, req, cfg)
 		if err != nil {
@@ -44,14 +67,27 @@ func (u *bigFileDownloader) createPartDownloaderProcessor(cancel context.CancelC
 			cancel(err)
 			return err, pipeline.ProcessAbort
 		}
+		defer resp.Body.Close()
 
 		reporterWriter := progress_report.NewReporterWriter(chunk.Writer, u.progressReporter.Report)
 
-		_, err = io.Copy(reporterWriter, resp.Body)
+		var body io.Reader = resp.Body
+		if u.encryption != nil {
+			body = u.encryption.newDecryptingReader(resp.Body, chunk.StartOffset)
+		}
+
+		expected := chunk.EndOffset - chunk.StartOffset + 1
+		n, err := io.CopyN(reporterWriter, body, expected)
 		if err != nil {
+			err = fmt.Errorf("error writing chunk %s (wrote %d of %d bytes): %w", downloadByteRange, n, expected, err)
+			cancel(err)
 			return err, pipeline.ProcessAbort
 		}
 
+		if err = u.state.markDone(chunk.StartOffset); err != nil {
+			logger().Debugw("unable to persist download state, download won't be resumable", "error", err)
+		}
+
 		return nil, pipeline.ProcessOutput
 	}
 }
@@ -70,20 +106,60 @@ func (u *bigFileDownloader) Download(ctx context.Context) error {
 			return err
 		}
 	}
-	writer, err := os.OpenFile(u.dst.String(), os.O_WRONLY|os.O_CREATE, utils.FILE_PERMISSION)
+
+	partPath := u.dst.String() + downloadPartSuffix
+	chunkSize := int64(u.cfg.chunkSizeInBytes())
+	u.state = loadDownloadState(u.dst.String()+downloadStateSuffix, u.etag, u.version, u.fileSize, chunkSize)
+	if _, err := os.Stat(partPath); err != nil {
+		u.state.Chunks = nil
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if len(u.state.Chunks) == 0 {
+		flags |= os.O_TRUNC
+	} else {
+		logger().Debugw("Resuming download", "src", u.src, "chunks", len(u.state.Chunks))
+		u.progressReporter.Report(uint64(u.state.doneBytes()), nil)
+	}
+
+	writer, err := os.OpenFile(partPath, flags, utils.FILE_PERMISSION)
 	if err != nil {
 		return err
 	}
+	defer writer.Close()
 
-	chunkChan := pipeline.PrepareWriteChunks(ctx, writer, u.fileSize, int64(u.cfg.chunkSizeInBytes()))
+	if err = u.state.save(); err != nil {
+		logger().Debugw("unable to persist download state, download won't be resumable", "error", err)
+	}
+
+	chunkChan := pipeline.PrepareWriteChunks(ctx, writer, u.fileSize, chunkSize)
 
-	bigFileDownloadErrorChan := pipeline.ParallelProcess(ctx, u.cfg.Workers, chunkChan, u.createPartDownloaderProcessor(cancel, u.cfg), nil)
+	bigFileDownloadErrorChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "download parts"), u.cfg.Workers, chunkChan, u.createPartDownloaderProcessor(cancel, u.cfg), nil)
 	bigFileDownloadErrorChan = pipeline.Filter(ctx, bigFileDownloadErrorChan, pipeline.FilterNonNil[error]{})
 
-	objErr, _ := pipeline.SliceItemConsumer[utils.MultiError](ctx, bigFileDownloadErrorChan)
+	objErr, err := pipeline.SliceItemConsumer[utils.MultiError](ctx, bigFileDownloadErrorChan)
 	if len(objErr) > 0 {
 		return objErr
 	}
+	if err != nil {
+		return err
+	}
+
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	if err = verifyDownload(partPath, u.head, u.cfg); err != nil {
+		// Contents can't be trusted, so don't resume from them
+		u.state.remove()
+		_ = os.Remove(partPath)
+		return err
+	}
+
+	if err = os.Rename(partPath, u.dst.String()); err != nil {
+		return err
+	}
+	u.state.remove()
 
 	return nil
 }
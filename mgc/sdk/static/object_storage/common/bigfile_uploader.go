@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"math"
 	"net/http"
+	"path/filepath"
 	"sort"
 
 	"github.com/MagaluCloud/magalu/mgc/core/pipeline"
@@ -33,8 +34,10 @@ type preparationResponse struct {
 }
 
 type completionPart struct {
-	Etag       string `xml:",innerxml"`
-	PartNumber int
+	Etag           string `xml:",innerxml"`
+	PartNumber     int
+	ChecksumSHA256 string `xml:",omitempty"`
+	ChecksumCRC32C string `xml:",omitempty"`
 }
 
 func NewCompletionPart(partNumber int, etag string) completionPart {
@@ -47,6 +50,18 @@ func NewCompletionPart(partNumber int, etag string) completionPart {
 	}
 }
 
+// withChecksum returns the part with the checksum sent on its upload, which must be
+// repeated on completion for the server to compute the checksum of the whole object
+func (p completionPart) withChecksum(algorithm ChecksumAlgorithm, checksum string) completionPart {
+	switch algorithm {
+	case ChecksumSHA256:
+		p.ChecksumSHA256 = checksum
+	case ChecksumCRC32C:
+		p.ChecksumCRC32C = checksum
+	}
+	return p
+}
+
 type completionRequest struct {
 	XMLName   xml.Name         `xml:"CompleteMultipartUpload"`
 	Namespace string           `xml:"xmlns,attr"`
@@ -56,12 +71,15 @@ type completionRequest struct {
 type bigFileUploader struct {
 	cfg          Config
 	dst          mgcSchemaPkg.URI
-	mimeType     string
+	metadata     ObjectMetadata
 	fileInfo     fs.FileInfo
 	filePath     mgcSchemaPkg.FilePath
 	workerN      int
 	uploadId     string
 	storageClass string
+	// Parts already uploaded by a previous execution, keyed by part number
+	uploadedParts map[int]completionPart
+	encryption    *objectEncryption
 }
 
 var _ uploader = (*bigFileUploader)(nil)
@@ -73,11 +91,17 @@ func (u *bigFileUploader) newPreparationRequest(ctx context.Context) (*http.Requ
 	}
 	req.Method = http.MethodPost
 	req.Header.Set("Content-Type", "application/octet-stream")
+	// The object gets the headers of the multipart upload creation, not of its parts
+	u.metadata.setHeaders(req)
 
 	if u.storageClass != "" {
 		req.Header.Set("X-Amz-Storage-Class", u.storageClass)
 	}
 
+	if u.cfg.ChecksumAlgorithm != ChecksumNone {
+		req.Header.Set(checksumAlgorithmHeader, string(u.cfg.ChecksumAlgorithm))
+	}
+
 	q := req.URL.Query()
 	q.Set("uploads", "")
 	req.URL.RawQuery = q.Encode()
@@ -103,10 +127,89 @@ func (u *bigFileUploader) getUploadId(ctx context.Context) (string, error) {
 		}
 
 		u.uploadId = pr.UploadId
+		putUploadJournalEntry(ctx, u.newJournalEntry())
 	}
 	return u.uploadId, nil
 }
 
+func (u *bigFileUploader) newJournalEntry() *UploadJournalEntry {
+	source, err := filepath.Abs(u.filePath.String())
+	if err != nil {
+		source = u.filePath.String()
+	}
+	return &UploadJournalEntry{
+		Source:       source,
+		Destination:  u.dst.String(),
+		UploadId:     u.uploadId,
+		FileSize:     u.fileInfo.Size(),
+		ModTime:      u.fileInfo.ModTime().UTC(),
+		ChunkSize:    u.cfg.chunkSizeInBytes(),
+		StorageClass: u.storageClass,
+		Checksum:     u.cfg.ChecksumAlgorithm,
+	}
+}
+
+func (u *bigFileUploader) expectedPartSize(partNumber int) int64 {
+	chunkSize := int64(u.cfg.chunkSizeInBytes())
+	offset := int64(partNumber-1) * chunkSize
+	return min(chunkSize, u.fileInfo.Size()-offset)
+}
+
+// resumeUpload looks for a journaled upload of the same file to the same destination.
+// If the server still knows about it, its upload ID is reused and the parts listed
+// by the server are not sent again. Stale journal entries are aborted and discarded.
+func (u *bigFileUploader) resumeUpload(ctx context.Context) {
+	entry := getUploadJournalEntry(ctx, u.dst)
+	if entry == nil {
+		return
+	}
+
+	logger := bigfileUploaderLogger().With("dst", u.dst, "uploadId", entry.UploadId)
+
+	// The data key of the previous upload isn't kept, so its parts can't be completed with the new ones
+	if u.encryption != nil {
+		logger.Infow("encrypted uploads can't be resumed, aborting journaled upload", "entry", entry)
+		u.abortStaleUpload(ctx, entry)
+		return
+	}
+
+	expected := u.newJournalEntry()
+	if entry.Source != expected.Source ||
+		entry.FileSize != expected.FileSize ||
+		!entry.ModTime.Equal(expected.ModTime) ||
+		entry.ChunkSize != expected.ChunkSize ||
+		entry.StorageClass != expected.StorageClass ||
+		entry.Checksum != expected.Checksum {
+		logger.Infow("journaled upload does not match current file, aborting it", "entry", entry)
+		u.abortStaleUpload(ctx, entry)
+		return
+	}
+
+	parts, err := ListParts(ctx, u.cfg, u.dst, entry.UploadId)
+	if err != nil {
+		logger.Infow("journaled upload is no longer available, starting a new one", "err", err)
+		removeUploadJournalEntry(ctx, u.dst, entry.UploadId)
+		return
+	}
+
+	u.uploadId = entry.UploadId
+	u.uploadedParts = make(map[int]completionPart, len(parts))
+	for _, part := range parts {
+		if part.Size != u.expectedPartSize(part.PartNumber) {
+			continue
+		}
+		u.uploadedParts[part.PartNumber] = NewCompletionPart(part.PartNumber, part.ETag).withChecksum(u.cfg.ChecksumAlgorithm, part.Checksum(u.cfg.ChecksumAlgorithm))
+	}
+	logger.Infow("resuming upload", "uploadedParts", len(u.uploadedParts))
+}
+
+func (u *bigFileUploader) abortStaleUpload(ctx context.Context, entry *UploadJournalEntry) {
+	if err := AbortMultipartUpload(ctx, u.cfg, u.dst, entry.UploadId); err != nil {
+		bigfileUploaderLogger().Debugw("failed to abort stale upload", "uploadId", entry.UploadId, "err", err)
+		removeUploadJournalEntry(ctx, u.dst, entry.UploadId)
+	}
+}
+
 func (u *bigFileUploader) createMultipartRequest(ctx context.Context, partNumber int, body func() (io.ReadCloser, error)) (*http.Request, error) {
 	uploadId, err := u.getUploadId(ctx)
 	if err != nil {
@@ -121,7 +224,11 @@ func (u *bigFileUploader) createMultipartRequest(ctx context.Context, partNumber
 	q.Set("partNumber", fmt.Sprint(partNumber))
 	req.URL.RawQuery = q.Encode()
 
-	req.Header.Set("Content-Type", u.mimeType)
+	req.Header.Set("Content-Type", u.metadata.ContentType)
+
+	if _, err = setChecksumHeader(req, u.cfg.ChecksumAlgorithm); err != nil {
+		return nil, fmt.Errorf("unable to compute checksum of part %d: %w", partNumber, err)
+	}
 
 	return req, nil
 }
@@ -172,10 +279,20 @@ func (u *bigFileUploader) createPartSenderProcessor(cancel context.CancelCauseFu
 		var err error
 
 		newReader := func() (io.ReadCloser, error) {
-			return io.NopCloser(io.NewSectionReader(chunk.Reader, 0, int64(u.cfg.chunkSizeInBytes()))), nil
+			reader := io.NewSectionReader(chunk.Reader, 0, int64(u.cfg.chunkSizeInBytes()))
+			if u.encryption != nil {
+				// Chunks start at segment boundaries, so each part is encrypted on its own
+				return io.NopCloser(u.encryption.newEncryptingReader(reader, chunk.StartOffset)), nil
+			}
+			return io.NopCloser(reader), nil
 		}
 
 		partNumber := int(chunk.StartOffset/int64(u.cfg.chunkSizeInBytes())) + 1
+		if uploaded, ok := u.uploadedParts[partNumber]; ok {
+			bigfileUploaderLogger().Debugw("Skipping part uploaded by previous execution", "part", partNumber, "total", totalParts)
+			return uploaded, pipeline.ProcessOutput
+		}
+
 		req, err := u.createMultipartRequest(ctx, partNumber, newReader)
 		if err != nil {
 			cancel(err)
@@ -195,7 +312,11 @@ func (u *bigFileUploader) createPartSenderProcessor(cancel context.CancelCauseFu
 			return part, pipeline.ProcessAbort
 		}
 
-		return NewCompletionPart(partNumber, res.Header.Get("etag")), pipeline.ProcessOutput
+		etag := res.Header.Get("etag")
+		recordUploadJournalPart(ctx, u.dst, uploadId, partNumber, etag)
+
+		checksum := req.Header.Get(u.cfg.ChecksumAlgorithm.Header())
+		return NewCompletionPart(partNumber, etag).withChecksum(u.cfg.ChecksumAlgorithm, checksum), pipeline.ProcessOutput
 	}
 }
 
@@ -213,6 +334,8 @@ func (u *bigFileUploader) Upload(ctx context.Context) error {
 		cancel(err)
 	}()
 
+	u.resumeUpload(ctx)
+
 	uploadId, err := u.getUploadId(ctx)
 	if err != nil {
 		return err
@@ -226,12 +349,17 @@ func (u *bigFileUploader) Upload(ctx context.Context) error {
 	totalParts := int(math.Ceil(float64(u.fileInfo.Size()) / float64(u.cfg.chunkSizeInBytes())))
 	chunkChan := pipeline.ReadChunks(ctx, reader, u.fileInfo.Size(), int64(u.cfg.chunkSizeInBytes()))
 
-	partChan := pipeline.ParallelProcess(ctx, u.workerN, chunkChan, u.createPartSenderProcessor(cancel, totalParts, uploadId), nil)
+	partChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "upload parts"), u.workerN, chunkChan, u.createPartSenderProcessor(cancel, totalParts, uploadId), nil)
 
 	parts, err := pipeline.SliceItemConsumer[[]completionPart](ctx, partChan)
 	if err != nil {
 		return err
 	}
 
-	return u.sendCompletionRequest(ctx, parts, uploadId)
+	if err = u.sendCompletionRequest(ctx, parts, uploadId); err != nil {
+		return err
+	}
+
+	removeUploadJournalEntry(ctx, u.dst, uploadId)
+	return nil
 }
@@ -0,0 +1,186 @@
+package common
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ChecksumAlgorithm is one of the additional checksums the server can verify on upload and
+// store along the object, sent as the 'x-amz-checksum-<algorithm>' headers
+type ChecksumAlgorithm string
+
+const (
+	ChecksumNone   ChecksumAlgorithm = ""
+	ChecksumSHA256 ChecksumAlgorithm = "SHA256"
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+
+	checksumAlgorithmHeader = "X-Amz-Checksum-Algorithm"
+	checksumModeHeader      = "X-Amz-Checksum-Mode"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+func (a ChecksumAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumCRC32C:
+		return crc32.New(crc32cTable), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", string(a))
+	}
+}
+
+// Header is the name of the header with the checksum of the content, both on requests and responses
+func (a ChecksumAlgorithm) Header() string {
+	return "X-Amz-Checksum-" + strings.ToLower(string(a))
+}
+
+// setChecksumHeader computes the checksum of the request payload and sets it to the
+// algorithm header, so the server rejects the content if it was corrupted on the way.
+// Returns the base64 encoded checksum, or an empty string if no algorithm is set.
+func setChecksumHeader(req *http.Request, algorithm ChecksumAlgorithm) (string, error) {
+	if algorithm == ChecksumNone || req.GetBody == nil {
+		return "", nil
+	}
+
+	h, err := algorithm.newHash()
+	if err != nil {
+		return "", err
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	if _, err = io.Copy(h, body); err != nil {
+		return "", err
+	}
+
+	checksum := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	req.Header.Set(algorithm.Header(), checksum)
+	return checksum, nil
+}
+
+// ChecksumParts returns the number of parts of a composite checksum ("<checksum>-<parts>"),
+// or 0 if the checksum is of the whole content
+func ChecksumParts(checksum string) int {
+	idx := strings.LastIndex(checksum, "-")
+	if idx < 0 {
+		return 0
+	}
+	parts, err := strconv.Atoi(checksum[idx+1:])
+	if err != nil {
+		return 0
+	}
+	return parts
+}
+
+// FileChecksum computes the checksum the server would store for the local file at 'path' if it
+// was uploaded in parts of 'partSize' bytes. If 'multipart' is true, it's the composite checksum
+// of the part checksums followed by the part count, as for ETags.
+func FileChecksum(path string, algorithm ChecksumAlgorithm, partSize int64, multipart bool) (string, error) {
+	if _, err := algorithm.newHash(); err != nil {
+		return "", err
+	}
+	newHash := func() hash.Hash {
+		h, _ := algorithm.newHash()
+		return h
+	}
+
+	digest, parts, err := fileDigest(path, newHash, partSize, multipart)
+	if err != nil {
+		return "", err
+	}
+
+	checksum := base64.StdEncoding.EncodeToString(digest)
+	if multipart {
+		checksum = fmt.Sprintf("%s-%d", checksum, parts)
+	}
+	return checksum, nil
+}
+
+// VerifyLocalFile checks whether the local file at 'path' has the same content as the remote
+// object described by 'head'. The checksum stored with the object is preferred, falling back
+// to the ETag. 'verified' is false if neither can be computed locally, such as multipart
+// objects uploaded with a chunk size other than the configured one or encrypted objects.
+func VerifyLocalFile(path string, head HeadObjectResponse, cfg Config) (matches bool, verified bool, err error) {
+	// Only the encrypted content could be compared
+	if head.IsEncrypted() {
+		return false, false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, false, err
+	}
+	if info.Size() != head.ContentLength {
+		return false, true, nil
+	}
+
+	chunkSize := int64(cfg.chunkSizeInBytes())
+	expectedParts := int(math.Ceil(float64(head.ContentLength) / float64(chunkSize)))
+
+	algorithm, checksum := head.Checksum()
+	if algorithm != ChecksumNone {
+		parts := ChecksumParts(checksum)
+		if parts > 0 && parts != expectedParts {
+			return false, false, nil
+		}
+
+		localChecksum, err := FileChecksum(path, algorithm, chunkSize, parts > 0)
+		if err != nil {
+			return false, false, err
+		}
+		return localChecksum == checksum, true, nil
+	}
+
+	// Objects encrypted by the server don't have the MD5 of their content as ETag
+	etag := CleanETag(head.ETag)
+	if parts := ETagParts(etag); parts > 0 && parts != expectedParts || parts == 0 && !isMD5Hex(etag) {
+		return false, false, nil
+	}
+
+	matches, err = LocalFileMatchesETag(path, etag, cfg)
+	return matches, err == nil, err
+}
+
+func isMD5Hex(value string) bool {
+	decoded, err := hex.DecodeString(value)
+	return err == nil && len(decoded) == md5.Size
+}
+
+// verifyDownload checks the downloaded file at 'path' against the object described by 'head',
+// unless disabled with Config.SkipVerify
+func verifyDownload(path string, head HeadObjectResponse, cfg Config) error {
+	// Decryption already authenticates the content of encrypted objects
+	if cfg.SkipVerify || head.IsEncrypted() {
+		return nil
+	}
+
+	matches, verified, err := VerifyLocalFile(path, head, cfg)
+	if err != nil {
+		return fmt.Errorf("unable to verify downloaded file: %w", err)
+	}
+	if !verified {
+		logger().Debugw("downloaded file can't be verified, the object has no comparable checksum or ETag", "path", path, "etag", head.ETag)
+		return nil
+	}
+	if !matches {
+		return fmt.Errorf("downloaded file does not match the checksum of the object (ETag %q), it may have been corrupted", CleanETag(head.ETag))
+	}
+	return nil
+}
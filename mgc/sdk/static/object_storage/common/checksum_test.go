@@ -0,0 +1,127 @@
+package common
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileChecksum(t *testing.T) {
+	content := []byte("0123456789")
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	single := sha256.Sum256(content)
+	got, err := FileChecksum(path, ChecksumSHA256, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := base64.StdEncoding.EncodeToString(single[:]); got != expected {
+		t.Errorf("single part: expected %q, got %q", expected, got)
+	}
+
+	var digests []byte
+	for _, part := range [][]byte{content[0:4], content[4:8], content[8:]} {
+		sum := crc32.Checksum(part, crc32cTable)
+		digests = append(digests, byte(sum>>24), byte(sum>>16), byte(sum>>8), byte(sum))
+	}
+	composite := crc32.Checksum(digests, crc32cTable)
+	compositeBytes := []byte{byte(composite >> 24), byte(composite >> 16), byte(composite >> 8), byte(composite)}
+	got, err = FileChecksum(path, ChecksumCRC32C, 4, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := base64.StdEncoding.EncodeToString(compositeBytes) + "-3"; got != expected {
+		t.Errorf("multipart: expected %q, got %q", expected, got)
+	}
+
+	if _, err = FileChecksum(path, "MD4", 4, false); err == nil {
+		t.Error("expected error for unsupported algorithm")
+	}
+}
+
+func TestSetChecksumHeader(t *testing.T) {
+	content := []byte("hello")
+	newReader := func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "https://bucket.example.com/file", nil)
+	req.GetBody = newReader
+
+	if _, err := setChecksumHeader(req, ChecksumNone); err != nil || req.Header.Get(ChecksumSHA256.Header()) != "" {
+		t.Fatalf("expected no checksum without algorithm, got %q (err %v)", req.Header.Get(ChecksumSHA256.Header()), err)
+	}
+
+	checksum, err := setChecksumHeader(req, ChecksumSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	if expected := base64.StdEncoding.EncodeToString(sum[:]); checksum != expected || req.Header.Get("x-amz-checksum-sha256") != expected {
+		t.Errorf("expected checksum %q, got %q and header %q", expected, checksum, req.Header.Get("x-amz-checksum-sha256"))
+	}
+}
+
+func TestVerifyLocalFile(t *testing.T) {
+	content := []byte("0123456789")
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	md5Sum := md5.Sum(content)
+	etag := fmt.Sprintf("%q", hex.EncodeToString(md5Sum[:]))
+	sha256Sum := sha256.Sum256(content)
+	checksum := base64.StdEncoding.EncodeToString(sha256Sum[:])
+	size := int64(len(content))
+
+	for _, tc := range []struct {
+		name     string
+		head     HeadObjectResponse
+		matches  bool
+		verified bool
+	}{
+		{name: "etag", head: HeadObjectResponse{ContentLength: size, ETag: etag}, matches: true, verified: true},
+		{name: "etag mismatch", head: HeadObjectResponse{ContentLength: size, ETag: `"00000000000000000000000000000000"`}, verified: true},
+		{name: "size mismatch", head: HeadObjectResponse{ContentLength: size + 1, ETag: etag}, verified: true},
+		{name: "checksum", head: HeadObjectResponse{ContentLength: size, ETag: `"opaque"`, ChecksumSHA256: checksum}, matches: true, verified: true},
+		{name: "checksum mismatch", head: HeadObjectResponse{ContentLength: size, ETag: etag, ChecksumSHA256: strings.Repeat("A", 43) + "="}, verified: true},
+		{name: "non md5 etag", head: HeadObjectResponse{ContentLength: size, ETag: `"opaque"`}},
+		{name: "other part size", head: HeadObjectResponse{ContentLength: size, ETag: etag[:33] + `-2"`}},
+	} {
+		matches, verified, err := VerifyLocalFile(path, tc.head, Config{})
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if matches != tc.matches || verified != tc.verified {
+			t.Errorf("%s: expected matches=%v verified=%v, got matches=%v verified=%v", tc.name, tc.matches, tc.verified, matches, verified)
+		}
+	}
+}
+
+func TestCompletionPartChecksum(t *testing.T) {
+	part := NewCompletionPart(1, `"etag"`).withChecksum(ChecksumSHA256, "c2hh")
+	body, err := xml.Marshal(completionRequest{Parts: []completionPart{part, NewCompletionPart(2, `"etag2"`)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `<Part><ETag>"etag"</ETag><PartNumber>1</PartNumber><ChecksumSHA256>c2hh</ChecksumSHA256></Part><Part><ETag>"etag2"</ETag><PartNumber>2</PartNumber></Part>`
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("expected parts %s, got %s", expected, body)
+	}
+}
@@ -7,6 +7,10 @@ type Config struct {
 	ChunkSize uint64 `json:"chunkSize,omitempty" jsonschema:"description=Chunk size to consider when doing multipart requests. Specified in Mb,default=8,minimum=8,maximum=5120,required"`
 	Region    string `json:"region,omitempty" jsonschema:"description=Region to reach the service,default=br-se1"`
 
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksumAlgorithm,omitempty" jsonschema:"description=Additional checksum sent on uploads\\, verified by the server and stored with the object,enum=,enum=SHA256,enum=CRC32C,default="`
+	SkipVerify        bool              `json:"skipVerify,omitempty" jsonschema:"description=Don't verify downloaded files against the checksum or ETag of the object,default=false"`
+	EncryptKeyFile    string            `json:"encryptKeyFile,omitempty" jsonschema:"description=File with the key to encrypt uploads on the client and decrypt them on download. Either a 32 byte key\\, raw or base64 encoded\\, or a passphrase"`
+
 	// See more about the 'squash' directive here: https://pkg.go.dev/github.com/mitchellh/mapstructure#hdr-Embedded_Structs_and_Squashing
 	config.NetworkConfig `json:",squash"` // nolint
 }
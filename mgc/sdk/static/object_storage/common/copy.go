@@ -25,10 +25,11 @@ func copyAllLogger() *zap.SugaredLogger {
 }
 
 type CopyObjectParams struct {
-	Source       mgcSchemaPkg.URI `json:"src" jsonschema:"description=Path of the object in a bucket to be copied,example=bucket1/file.txt" mgc:"positional"`
-	Destination  mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Full destination path in the bucket with desired filename,example=bucket2/dir/file.txt" mgc:"positional"`
-	Version      string           `json:"obj_version,omitempty" jsonschema:"description=Version of the object to be copied"`
-	StorageClass string           `json:"storage_class,omitempty" jsonschema:"description=Copy objects to other storage classes,example=cold,enum=,enum=standard,enum=cold,enum=glacier_ir,enum=cold_instant,default="`
+	Source         mgcSchemaPkg.URI `json:"src" jsonschema:"description=Path of the object in a bucket to be copied,example=bucket1/file.txt" mgc:"positional"`
+	Destination    mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Full destination path in the bucket with desired filename,example=bucket2/dir/file.txt" mgc:"positional"`
+	ObjectVersion  `json:",squash"` // nolint
+	StorageClass   string           `json:"storage_class,omitempty" jsonschema:"description=Copy objects to other storage classes,example=cold,enum=,enum=standard,enum=cold,enum=glacier_ir,enum=cold_instant,default="`
+	ObjectMetadata `json:",squash"` // nolint
 }
 
 type CopyAllObjectsParams struct {
@@ -58,14 +59,13 @@ func newCopyRequest(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst m
 		return nil, core.UsageError{Err: fmt.Errorf("badly specified source URI: %w", err)}
 	}
 
-	req.Header.Set("x-amz-copy-source", copySource)
-
+	// The version selects which source object is copied, the destination always gets a new one
 	if version != "" {
-		query := req.URL.Query()
-		query.Set("versionId", version)
-		req.URL.RawQuery = query.Encode()
+		copySource += "?versionId=" + url.QueryEscape(version)
 	}
 
+	req.Header.Set("x-amz-copy-source", copySource)
+
 	return req, nil
 }
 
@@ -91,7 +91,7 @@ func createObjectCopyProcessor(cfg Config, params CopyAllObjectsParams, progress
 		}
 
 		copyAllLogger().Infow("Copying object", "uri", objURI)
-		err = CopySingleFile(ctx, cfg, objURI, params.Destination.JoinPath(dirEntry.Path()), params.StorageClass)
+		err = CopySingleFile(ctx, cfg, objURI, params.Destination.JoinPath(dirEntry.Path()), params.StorageClass, ObjectMetadata{})
 		if err != nil {
 			return err, pipeline.ProcessAbort
 		}
@@ -128,7 +128,7 @@ func CopyMultipleFiles(ctx context.Context, cfg Config, params CopyAllObjectsPar
 	objs := ListGenerator(ctx, listParams, cfg, onNewPage)
 	objs = ApplyFilters(ctx, objs, params.FilterParams, cancel)
 
-	copyObjectsErrorChan := pipeline.ParallelProcess(ctx, cfg.Workers, objs, createObjectCopyProcessor(cfg, params, progressReporter), nil)
+	copyObjectsErrorChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "copy objects"), cfg.Workers, objs, createObjectCopyProcessor(cfg, params, progressReporter), nil)
 	copyObjectsErrorChan = pipeline.Filter(ctx, copyObjectsErrorChan, pipeline.FilterNonNil[error]{})
 
 	objErr, err := pipeline.SliceItemConsumer[utils.MultiError](ctx, copyObjectsErrorChan)
@@ -144,11 +144,15 @@ func CopyMultipleFiles(ctx context.Context, cfg Config, params CopyAllObjectsPar
 	return nil
 }
 
-func CopySingleFile(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst mgcSchemaPkg.URI, storageClass string) error {
+func CopySingleFile(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst mgcSchemaPkg.URI, storageClass string, metadata ObjectMetadata) error {
 	if dst.IsRoot() {
 		dst = dst.JoinPath(src.Filename())
 	}
 
+	if planned := newPlannedTransfer(ctx, plannedCopy, src.String(), dst.String()); planned != nil {
+		return planned.Copy(ctx)
+	}
+
 	req, err := newCopyRequest(ctx, cfg, src, dst, "")
 	if err != nil {
 		return err
@@ -157,6 +161,7 @@ func CopySingleFile(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst m
 	if storageClass != "" {
 		req.Header.Set("X-Amz-Storage-Class", storageClass)
 	}
+	metadata.setCopyHeaders(req)
 
 	resp, err := SendRequest(ctx, req, cfg)
 	if err != nil {
@@ -166,29 +171,46 @@ func CopySingleFile(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst m
 	return ExtractErr(resp, req)
 }
 
-func NewCopier(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst mgcSchemaPkg.URI, version string, storageClass string) (copier, error) {
-	metadata, err := HeadFile(ctx, cfg, src, version)
+func NewCopier(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst mgcSchemaPkg.URI, version string, storageClass string, metadata ObjectMetadata) (copier, error) {
+	head, err := HeadFile(ctx, cfg, src, version)
 	if err != nil {
 		return nil, err
 	}
 
-	totalCopyParts := int(math.Ceil(float64(metadata.ContentLength) / float64(cfg.chunkSizeInBytes())))
+	if planned := newPlannedTransfer(ctx, plannedCopy, src.String(), dst.String()); planned != nil {
+		return planned, nil
+	}
+
+	totalCopyParts := int(math.Ceil(float64(head.ContentLength) / float64(cfg.chunkSizeInBytes())))
+
+	// Copies of objects encrypted on the client need the source key metadata to be decrypted
+	if head.IsEncrypted() && (totalCopyParts > 1 || metadata.hasHeaders()) {
+		metadata.Metadata = withSourceEncryptionMetadata(metadata.Metadata, head)
+	}
 
 	if totalCopyParts > 1 {
+		// Multipart copies don't keep the source headers, so at least keep its type
+		if metadata.ContentType == "" {
+			metadata.ContentType = head.ContentType
+		}
 		return &bigFileCopier{
 			cfg:          cfg,
 			src:          src,
 			dst:          dst,
-			fileSize:     metadata.ContentLength,
+			fileSize:     head.ContentLength,
 			totalParts:   totalCopyParts,
+			version:      version,
 			storageClass: storageClass,
+			metadata:     metadata,
 		}, nil
 	} else {
 		return &smallFileCopier{
 			cfg:          cfg,
 			src:          src,
 			dst:          dst,
+			version:      version,
 			storageClass: storageClass,
+			metadata:     metadata,
 		}, nil
 	}
 }
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	neturl "net/url"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
 	"github.com/MagaluCloud/magalu/mgc/core/pipeline"
 	"github.com/MagaluCloud/magalu/mgc/core/progress_report"
 	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
@@ -26,8 +28,8 @@ func deleteLogger() *zap.SugaredLogger {
 }
 
 type DeleteObjectParams struct {
-	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to be deleted,example=bucket1/file.txt" mgc:"positional"`
-	Version     string           `json:"objVersion,omitempty" jsonschema:"description=Version of the object to be deleted"`
+	Destination   mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to be deleted,example=bucket1/file.txt" mgc:"positional"`
+	ObjectVersion `json:",squash"` // nolint
 }
 
 type DeleteBucketParams struct {
@@ -42,9 +44,10 @@ type DeleteObjectsParams struct {
 }
 
 type DeleteAllObjectsInBucketParams struct {
-	BucketName BucketName       `json:"bucket" jsonschema:"description=Name of the bucket to delete objects from" mgc:"positional"`
-	BatchSize  int              `json:"batch_size,omitempty" jsonschema:"description=Limit of items per batch to delete,default=1000,minimum=1,maximum=1000,required" example:"1000"`
-	Filters    `json:",squash"` // nolint
+	BucketName      BucketName       `json:"bucket" jsonschema:"description=Name of the bucket to delete objects from" mgc:"positional"`
+	BatchSize       int              `json:"batch_size,omitempty" jsonschema:"description=Limit of items per batch to delete,default=1000,minimum=1,maximum=1000,required" example:"1000"`
+	IncludeVersions bool             `json:"include_versions,omitempty" jsonschema:"description=Also permanently delete all noncurrent versions and delete markers\\, leaving the bucket empty if versioning is enabled"`
+	Filters         `json:",squash"` // nolint
 }
 
 func newDeleteRequest(ctx context.Context, cfg Config, params DeleteBucketParams) (*http.Request, error) {
@@ -77,7 +80,7 @@ type deleteBatchRequestBody struct {
 
 func DeleteSingle(ctx context.Context, params DeleteObjectParams, cfg Config) error {
 	objectKey := params.Destination.AsFilePath().String()
-	versionID := params.Version
+	versionID := params.VersionID()
 
 	req, err := newDeleteSingleRequest(ctx, cfg, NewBucketNameFromURI(params.Destination), objectKey, versionID)
 	if err != nil {
@@ -106,7 +109,7 @@ func newDeleteSingleRequest(ctx context.Context, cfg Config, bucketName BucketNa
 	url := fmt.Sprintf("%s/%s", host, objectKey)
 
 	if versionID != "" {
-		url = fmt.Sprintf("%s?versionId=%s", url, versionID)
+		url = fmt.Sprintf("%s?versionId=%s", url, neturl.QueryEscape(versionID))
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
@@ -130,7 +133,8 @@ func newDeleteBatchRequest(ctx context.Context, cfg Config, bucketName BucketNam
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(host), bytes.NewBuffer(marshalledBody))
+	// Deleting the same keys again has no further effect, so the batch may be retried despite being a POST
+	req, err := http.NewRequestWithContext(mgcHttpPkg.NewIdempotentContext(ctx), http.MethodPost, string(host), bytes.NewBuffer(marshalledBody))
 	if err != nil {
 		return nil, err
 	}
@@ -157,18 +161,31 @@ func createObjectDeletionProcessor(cfg Config, bucketName BucketName, progressRe
 				return &ObjectError{Err: err}, pipeline.ProcessAbort
 			}
 
-			obj, ok := dirEntry.DirEntry().(*BucketContent)
-			if !ok {
+			switch obj := dirEntry.DirEntry().(type) {
+			case *BucketContent:
+				objIdentifiers = append(objIdentifiers, objectIdentifier{Key: obj.Key})
+			case *BucketObjectVersion:
+				objIdentifiers = append(objIdentifiers, objectIdentifier{Key: obj.Key, VersionId: obj.VersionID})
+			default:
 				err = fmt.Errorf("expected object, got directory")
 				progressReporter.Report(0, 0, err)
 				return &ObjectError{Err: err}, pipeline.ProcessAbort
 			}
-
-			objIdentifiers = append(objIdentifiers, objectIdentifier{Key: obj.Key})
 		}
 
 		defer func() { progressReporter.Report(uint64(len(dirEntries)), 0, err) }()
 
+		if core.DryRunPlanFromContext(ctx) != nil {
+			for _, obj := range objIdentifiers {
+				dst := bucketName.AsURI().JoinPath(obj.Key).String()
+				if obj.VersionId != "" {
+					dst += "?versionId=" + obj.VersionId
+				}
+				planDelete(ctx, dst)
+			}
+			return nil, pipeline.ProcessOutput
+		}
+
 		req, err := newDeleteBatchRequest(ctx, cfg, bucketName, objIdentifiers)
 		if err != nil {
 			return &ObjectError{Err: err}, pipeline.ProcessAbort
@@ -211,7 +228,12 @@ func DeleteAllObjectsInBucket(ctx context.Context, params DeleteAllObjectsInBuck
 		progressReporter.Report(0, objCount, nil)
 	}
 
-	objs := ListGenerator(ctx, listParams, cfg, onNewPage)
+	var objs <-chan pipeline.WalkDirEntry
+	if params.IncludeVersions {
+		objs = ListVersionsGenerator(ctx, dst, cfg, onNewPage)
+	} else {
+		objs = ListGenerator(ctx, listParams, cfg, onNewPage)
+	}
 	objs = ApplyFilters(ctx, objs, params.FilterParams, cancel)
 
 	if params.BatchSize < MinBatchSize || params.BatchSize > MaxBatchSize {
@@ -219,7 +241,7 @@ func DeleteAllObjectsInBucket(ctx context.Context, params DeleteAllObjectsInBuck
 	}
 
 	objsBatch := pipeline.Batch(ctx, objs, params.BatchSize)
-	deleteObjectsErrorChan := pipeline.ParallelProcess(ctx, cfg.Workers, objsBatch, createObjectDeletionProcessor(cfg, params.BucketName, progressReporter), nil)
+	deleteObjectsErrorChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "delete objects"), cfg.Workers, objsBatch, createObjectDeletionProcessor(cfg, params.BucketName, progressReporter), nil)
 	deleteObjectsErrorChan = pipeline.Filter(ctx, deleteObjectsErrorChan, pipeline.FilterNonNil[error]{})
 
 	objErr, err := pipeline.SliceItemConsumer[utils.MultiError](ctx, deleteObjectsErrorChan)
@@ -253,7 +275,11 @@ func DeleteBucket(ctx context.Context, params DeleteBucketParams, cfg Config) er
 }
 
 func Delete(ctx context.Context, params DeleteObjectParams, cfg Config) error {
-	objKeys := []objectIdentifier{{Key: params.Destination.AsFilePath().String(), VersionId: params.Version}}
+	if planDelete(ctx, params.Destination.String()) {
+		return nil
+	}
+
+	objKeys := []objectIdentifier{{Key: params.Destination.AsFilePath().String(), VersionId: params.VersionID()}}
 
 	if len(objKeys) > 1 {
 		req, err := newDeleteBatchRequest(ctx, cfg, NewBucketNameFromURI(params.Destination), objKeys)
@@ -293,7 +319,7 @@ func DeleteObjects(ctx context.Context, params DeleteObjectsParams, cfg Config)
 	}
 
 	objsBatch := pipeline.Batch(ctx, params.ToDelete, params.BatchSize)
-	deleteObjectsErrorChan := pipeline.ParallelProcess(ctx, cfg.Workers, objsBatch, createObjectDeletionProcessor(cfg, bucketName, progressReporter), nil)
+	deleteObjectsErrorChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "delete objects"), cfg.Workers, objsBatch, createObjectDeletionProcessor(cfg, bucketName, progressReporter), nil)
 	deleteObjectsErrorChan = pipeline.Filter(ctx, deleteObjectsErrorChan, pipeline.FilterNonNil[error]{})
 
 	objErr, _ := pipeline.SliceItemConsumer[utils.MultiError](ctx, deleteObjectsErrorChan)
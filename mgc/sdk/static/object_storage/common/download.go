@@ -15,9 +15,9 @@ import (
 )
 
 type DownloadObjectParams struct {
-	Source      mgcSchemaPkg.URI      `json:"src" jsonschema:"description=Path of the object to be downloaded,example=bucket1/file.txt" mgc:"positional"`
-	Destination mgcSchemaPkg.FilePath `json:"dst,omitempty" jsonschema:"description=Path and file name to be saved (relative or absolute).If not specified it defaults to the current working directory,example=file.txt" mgc:"positional"`
-	Version     string                `json:"obj_version,omitempty" jsonschema:"description=Version of the object to be downloaded"`
+	Source        mgcSchemaPkg.URI      `json:"src" jsonschema:"description=Path of the object to be downloaded,example=bucket1/file.txt" mgc:"positional"`
+	Destination   mgcSchemaPkg.FilePath `json:"dst,omitempty" jsonschema:"description=Path and file name to be saved (relative or absolute).If not specified it defaults to the current working directory,example=file.txt" mgc:"positional"`
+	ObjectVersion `json:",squash"`      // nolint
 }
 
 type downloader interface {
@@ -66,22 +66,41 @@ func NewDownloader(ctx context.Context, cfg Config, src mgcSchemaPkg.URI, dst mg
 		return nil, err
 	}
 
-	totalDownloadParts := int(math.Ceil(float64(metadata.ContentLength) / float64(cfg.chunkSizeInBytes())))
+	if planned := newPlannedTransfer(ctx, plannedDownload, src.String(), dst.String()); planned != nil {
+		return planned, nil
+	}
+
+	encryption, err := objectDecryption(cfg, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt %q: %w", src, err)
+	}
+
+	fileSize := metadata.ContentLength
+	if encryption != nil {
+		fileSize = encryption.plainSize
+	}
+
+	totalDownloadParts := int(math.Ceil(float64(fileSize) / float64(cfg.chunkSizeInBytes())))
 
 	if totalDownloadParts > 1 {
 		return &bigFileDownloader{
-			cfg:      cfg,
-			src:      src,
-			dst:      dst,
-			fileSize: metadata.ContentLength,
-			version:  version,
+			cfg:        cfg,
+			src:        src,
+			dst:        dst,
+			fileSize:   fileSize,
+			version:    version,
+			etag:       metadata.ETag,
+			head:       metadata,
+			encryption: encryption,
 		}, nil
 	} else {
 		return &smallFileDownloader{
-			cfg:     cfg,
-			src:     src,
-			dst:     dst,
-			version: version,
+			cfg:        cfg,
+			src:        src,
+			dst:        dst,
+			version:    version,
+			head:       metadata,
+			encryption: encryption,
 		}, nil
 	}
 }
@@ -0,0 +1,90 @@
+package common
+
+import (
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/invopop/yaml"
+)
+
+const (
+	downloadPartSuffix  = ".part"
+	downloadStateSuffix = ".part.state"
+)
+
+// downloadState records which chunks of an interrupted download were already written to
+// its ".part" file, so a later execution may resume it instead of starting over.
+type downloadState struct {
+	ETag      string  `json:"etag"`
+	Version   string  `json:"version,omitempty"`
+	Size      int64   `json:"size"`
+	ChunkSize int64   `json:"chunk_size"`
+	Chunks    []int64 `json:"chunks,omitempty"`
+
+	path  string
+	mutex sync.Mutex
+}
+
+// The state is only valid for the very same object contents and chunk layout, otherwise
+// the already written chunks can't be trusted and the download must restart
+func loadDownloadState(path string, etag string, version string, size int64, chunkSize int64) *downloadState {
+	fresh := &downloadState{ETag: etag, Version: version, Size: size, ChunkSize: chunkSize, path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fresh
+	}
+
+	state := &downloadState{}
+	if err = yaml.Unmarshal(data, state); err != nil {
+		logger().Debugw("ignored bad format download state", "path", path, "error", err)
+		return fresh
+	}
+
+	if etag == "" || state.ETag != etag || state.Version != version || state.Size != size || state.ChunkSize != chunkSize {
+		logger().Debugw("ignored stale download state", "path", path)
+		return fresh
+	}
+
+	state.path = path
+	return state
+}
+
+func (s *downloadState) isDone(startOffset int64) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Contains(s.Chunks, startOffset)
+}
+
+// Bytes of all chunks already written, used to resume the progress report
+func (s *downloadState) doneBytes() (total int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, start := range s.Chunks {
+		total += min(s.ChunkSize, s.Size-start)
+	}
+	return
+}
+
+func (s *downloadState) markDone(startOffset int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Chunks = append(s.Chunks, startOffset)
+	return s.save()
+}
+
+func (s *downloadState) save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, utils.FILE_PERMISSION)
+}
+
+func (s *downloadState) remove() {
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		logger().Debugw("unable to remove download state", "path", s.path, "error", err)
+	}
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.bin"+downloadStateSuffix)
+
+	state := loadDownloadState(path, `"etag"`, "", 20, 8)
+	if len(state.Chunks) != 0 {
+		t.Fatalf("expected fresh state, got chunks %v", state.Chunks)
+	}
+	if err := state.markDone(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := state.markDone(16); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := loadDownloadState(path, `"etag"`, "", 20, 8)
+	if !resumed.isDone(0) || !resumed.isDone(16) || resumed.isDone(8) {
+		t.Errorf("unexpected resumed chunks %v", resumed.Chunks)
+	}
+	if done := resumed.doneBytes(); done != 12 {
+		t.Errorf("expected 12 bytes done, got %d", done)
+	}
+
+	stale := []struct {
+		name      string
+		etag      string
+		version   string
+		size      int64
+		chunkSize int64
+	}{
+		{"etag", `"other"`, "", 20, 8},
+		{"version", `"etag"`, "v2", 20, 8},
+		{"size", `"etag"`, "", 21, 8},
+		{"chunk size", `"etag"`, "", 20, 4},
+		{"no etag", "", "", 20, 8},
+	}
+	for _, tc := range stale {
+		t.Run(tc.name, func(t *testing.T) {
+			s := loadDownloadState(path, tc.etag, tc.version, tc.size, tc.chunkSize)
+			if len(s.Chunks) != 0 {
+				t.Errorf("expected stale state to be ignored, got chunks %v", s.Chunks)
+			}
+		})
+	}
+
+	resumed.remove()
+	if s := loadDownloadState(path, `"etag"`, "", 20, 8); len(s.Chunks) != 0 {
+		t.Errorf("expected removed state, got chunks %v", s.Chunks)
+	}
+}
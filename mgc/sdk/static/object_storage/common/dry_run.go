@@ -0,0 +1,69 @@
+package common
+
+import (
+	"context"
+	"os"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+)
+
+const (
+	plannedUpload   = "upload"
+	plannedDownload = "download"
+	plannedCopy     = "copy"
+	plannedDelete   = "delete"
+)
+
+// plannedTransfer is returned by the uploader, downloader and copier factories in dry-run mode.
+// Instead of transferring, it adds itself to the plan
+type plannedTransfer struct {
+	plan   *core.DryRunPlan
+	action core.DryRunAction
+}
+
+func newPlannedTransfer(ctx context.Context, action, src, dst string) *plannedTransfer {
+	plan := core.DryRunPlanFromContext(ctx)
+	if plan == nil {
+		return nil
+	}
+	return &plannedTransfer{plan, core.DryRunAction{Action: action, Source: src, Destination: dst}}
+}
+
+func (t *plannedTransfer) add() error {
+	t.plan.Add(t.action)
+	return nil
+}
+
+func (t *plannedTransfer) Upload(context.Context) error {
+	return t.add()
+}
+
+func (t *plannedTransfer) Download(context.Context) error {
+	return t.add()
+}
+
+func (t *plannedTransfer) Copy(context.Context) error {
+	return t.add()
+}
+
+// planDelete returns true if the context is in dry-run mode, in which case the deletion was added to the plan
+func planDelete(ctx context.Context, dst string) bool {
+	plan := core.DryRunPlanFromContext(ctx)
+	if plan == nil {
+		return false
+	}
+	plan.Add(core.DryRunAction{Action: plannedDelete, Destination: dst})
+	return true
+}
+
+// RemoveLocalFile removes the file after it was moved or synced, unless in dry-run mode
+func RemoveLocalFile(ctx context.Context, path string) error {
+	if planDelete(ctx, path) {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+var _ uploader = (*plannedTransfer)(nil)
+var _ downloader = (*plannedTransfer)(nil)
+var _ copier = (*plannedTransfer)(nil)
@@ -0,0 +1,404 @@
+package common
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Client-side envelope encryption: each object is encrypted with its own random data key, which is
+// stored with the object wrapped by the key encryption key (KEK) of the user's key file. The content
+// is split in segments sealed independently with AES-256-GCM, so parts and byte ranges can be
+// encrypted and decrypted on their own, as long as they start at a segment boundary. The chunk
+// size is always a multiple of the segment size.
+const (
+	encryptionSegmentSize = 64 * 1024
+	encryptionKeySize     = 32
+	aesGCMTagSize         = 16
+
+	encryptionAlgorithm     = "AES256-GCM-64K"
+	encryptionWrapKey       = "AES256-GCM"
+	encryptionWrapPassword  = "PBKDF2-SHA256-AES256-GCM"
+	encryptionKDFIterations = 600_000
+
+	// Stored as user metadata, see ObjectMetadata.Metadata
+	encryptionMetadataPrefix    = "mgc-cse-"
+	encryptionMetadataAlgorithm = "mgc-cse-algorithm"
+	encryptionMetadataWrap      = "mgc-cse-wrap"
+	encryptionMetadataKey       = "mgc-cse-key"
+	encryptionMetadataNonce     = "mgc-cse-nonce"
+	encryptionMetadataSalt      = "mgc-cse-salt"
+	encryptionMetadataSize      = "mgc-cse-size"
+)
+
+// keyEncryptionKey is loaded from the key file, which has either a 32 byte key, raw or base64
+// encoded, or a passphrase from which keys are derived with the salt stored in each object
+type keyEncryptionKey struct {
+	key        []byte
+	passphrase string
+	// Uploads of the same execution share the salt, so the key is derived only once
+	uploadSalt []byte
+	derived    sync.Map
+}
+
+var keyEncryptionKeys sync.Map
+
+func loadKeyEncryptionKey(path string) (*keyEncryptionKey, error) {
+	if kek, ok := keyEncryptionKeys.Load(path); ok {
+		return kek.(*keyEncryptionKey), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read encryption key file: %w", err)
+	}
+
+	kek := &keyEncryptionKey{}
+	trimmed := bytes.TrimSpace(data)
+	if len(data) == encryptionKeySize {
+		kek.key = data
+	} else if decoded, err := base64.StdEncoding.DecodeString(string(trimmed)); err == nil && len(decoded) == encryptionKeySize {
+		kek.key = decoded
+	} else if len(trimmed) > 0 {
+		kek.passphrase = string(trimmed)
+		kek.uploadSalt = randomBytes(16)
+	} else {
+		return nil, fmt.Errorf("encryption key file %q is empty", path)
+	}
+
+	actual, _ := keyEncryptionKeys.LoadOrStore(path, kek)
+	return actual.(*keyEncryptionKey), nil
+}
+
+func (k *keyEncryptionKey) wrapName() string {
+	if k.key != nil {
+		return encryptionWrapKey
+	}
+	return encryptionWrapPassword
+}
+
+func (k *keyEncryptionKey) aead(salt []byte) (cipher.AEAD, error) {
+	key := k.key
+	if key == nil {
+		derived, ok := k.derived.Load(string(salt))
+		if !ok {
+			var err error
+			if derived, err = pbkdf2.Key(sha256.New, k.passphrase, salt, encryptionKDFIterations, encryptionKeySize); err != nil {
+				return nil, err
+			}
+			k.derived.Store(string(salt), derived)
+		}
+		key = derived.([]byte)
+	}
+	return newGCM(key)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never fails, see its documentation
+	_, _ = rand.Read(b)
+	return b
+}
+
+// objectEncryption encrypts or decrypts the content of a single object
+type objectEncryption struct {
+	aead      cipher.AEAD
+	baseNonce []byte
+	plainSize int64
+	metadata  map[string]string
+}
+
+// newObjectEncryption creates a data key for a new object of 'plainSize' bytes, or returns nil if
+// client-side encryption is not configured
+func newObjectEncryption(cfg Config, plainSize int64) (*objectEncryption, error) {
+	if cfg.EncryptKeyFile == "" {
+		return nil, nil
+	}
+
+	kek, err := loadKeyEncryptionKey(cfg.EncryptKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{
+		encryptionMetadataAlgorithm: encryptionAlgorithm,
+		encryptionMetadataWrap:      kek.wrapName(),
+		encryptionMetadataSize:      strconv.FormatInt(plainSize, 10),
+	}
+
+	if kek.uploadSalt != nil {
+		metadata[encryptionMetadataSalt] = base64.StdEncoding.EncodeToString(kek.uploadSalt)
+	}
+
+	wrapper, err := kek.aead(kek.uploadSalt)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := randomBytes(encryptionKeySize)
+	wrapNonce := randomBytes(wrapper.NonceSize())
+	wrappedKey := wrapper.Seal(wrapNonce, wrapNonce, dataKey, nil)
+	metadata[encryptionMetadataKey] = base64.StdEncoding.EncodeToString(wrappedKey)
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	baseNonce := randomBytes(aead.NonceSize())
+	metadata[encryptionMetadataNonce] = base64.StdEncoding.EncodeToString(baseNonce)
+
+	return &objectEncryption{aead: aead, baseNonce: baseNonce, plainSize: plainSize, metadata: metadata}, nil
+}
+
+// IsEncrypted tells whether the object was encrypted on the client, in which case its ETag and
+// checksums are of the encrypted content
+func (h HeadObjectResponse) IsEncrypted() bool {
+	return h.Metadata[encryptionMetadataAlgorithm] != ""
+}
+
+// PlainSize returns the size of the content before it was encrypted on the client
+func (h HeadObjectResponse) PlainSize() (int64, bool) {
+	if !h.IsEncrypted() {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(h.Metadata[encryptionMetadataSize], 10, 64)
+	return size, err == nil
+}
+
+// objectDecryption unwraps the data key of an object encrypted on the client, or returns nil if it's not encrypted
+func objectDecryption(cfg Config, head HeadObjectResponse) (*objectEncryption, error) {
+	if !head.IsEncrypted() {
+		return nil, nil
+	}
+
+	metadata := head.Metadata
+	if algorithm := metadata[encryptionMetadataAlgorithm]; algorithm != encryptionAlgorithm {
+		return nil, fmt.Errorf("object is encrypted with unsupported algorithm %q", algorithm)
+	}
+	if cfg.EncryptKeyFile == "" {
+		return nil, fmt.Errorf("object is encrypted on the client, set the key file used on upload with --encrypt-key-file to decrypt it")
+	}
+
+	kek, err := loadKeyEncryptionKey(cfg.EncryptKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if wrap := metadata[encryptionMetadataWrap]; wrap != kek.wrapName() {
+		return nil, fmt.Errorf("object key is wrapped with %q, which doesn't match the key file", wrap)
+	}
+
+	var salt, wrappedKey, baseNonce []byte
+	plainSize, err := strconv.ParseInt(metadata[encryptionMetadataSize], 10, 64)
+	if err == nil {
+		salt, err = base64.StdEncoding.DecodeString(metadata[encryptionMetadataSalt])
+	}
+	if err == nil {
+		wrappedKey, err = base64.StdEncoding.DecodeString(metadata[encryptionMetadataKey])
+	}
+	if err == nil {
+		baseNonce, err = base64.StdEncoding.DecodeString(metadata[encryptionMetadataNonce])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption metadata: %w", err)
+	}
+
+	wrapper, err := kek.aead(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < wrapper.NonceSize() {
+		return nil, fmt.Errorf("invalid encryption metadata: wrapped key is too short")
+	}
+	dataKey, err := wrapper.Open(nil, wrappedKey[:wrapper.NonceSize()], wrappedKey[wrapper.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the object key, it was encrypted with another key file")
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(baseNonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid encryption metadata: nonce size is %d", len(baseNonce))
+	}
+
+	return &objectEncryption{aead: aead, baseNonce: baseNonce, plainSize: plainSize}, nil
+}
+
+// withMetadata returns the user metadata with the entries needed to decrypt the object
+func (e *objectEncryption) withMetadata(metadata map[string]string) map[string]string {
+	result := make(map[string]string, len(metadata)+len(e.metadata))
+	for k, v := range metadata {
+		result[k] = v
+	}
+	for k, v := range e.metadata {
+		result[k] = v
+	}
+	return result
+}
+
+// withSourceEncryptionMetadata adds the encryption entries of the source object to the metadata
+// of a copy that doesn't keep the source metadata, so the copy can still be decrypted
+func withSourceEncryptionMetadata(metadata map[string]string, source HeadObjectResponse) map[string]string {
+	result := make(map[string]string, len(metadata)+len(source.Metadata))
+	for k, v := range metadata {
+		result[k] = v
+	}
+	for k, v := range source.Metadata {
+		if strings.HasPrefix(k, encryptionMetadataPrefix) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func (e *objectEncryption) lastSegment() uint64 {
+	if e.plainSize == 0 {
+		return 0
+	}
+	return uint64((e.plainSize - 1) / encryptionSegmentSize)
+}
+
+// Each segment has its own nonce, derived from its index, and is bound to its position and to
+// whether it's the last one, so segments can't be reordered or the content truncated
+func (e *objectEncryption) segmentParams(segment uint64) (nonce []byte, additionalData []byte) {
+	nonce = bytes.Clone(e.baseNonce)
+	tail := nonce[len(nonce)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^segment)
+
+	additionalData = binary.BigEndian.AppendUint64(nil, segment)
+	if segment == e.lastSegment() {
+		additionalData = append(additionalData, 1)
+	} else {
+		additionalData = append(additionalData, 0)
+	}
+	return
+}
+
+// EncryptedSize returns the size of the encrypted content of 'plainSize' bytes. Empty content
+// still has a segment, so it's authenticated as well
+func EncryptedSize(plainSize int64) int64 {
+	segments := max(1, (plainSize+encryptionSegmentSize-1)/encryptionSegmentSize)
+	return plainSize + segments*int64(aesGCMTagSize)
+}
+
+// encryptedRange returns the byte range of the encrypted content with the plain content from
+// 'start' to 'end', inclusive. 'start' must be at a segment boundary
+func encryptedRange(start int64, end int64) (int64, int64) {
+	segmentStart := start / encryptionSegmentSize
+	encryptedStart := segmentStart * (encryptionSegmentSize + aesGCMTagSize)
+	return encryptedStart, encryptedStart + EncryptedSize(end-start+1) - 1
+}
+
+// newEncryptingReader encrypts the plain content read from 'src', which starts at 'offset' of the object
+func (e *objectEncryption) newEncryptingReader(src io.Reader, offset int64) io.Reader {
+	return &segmentReader{
+		src:     src,
+		segment: uint64(offset / encryptionSegmentSize),
+		in:      make([]byte, encryptionSegmentSize),
+		process: func(dst, in []byte, segment uint64) ([]byte, error) {
+			nonce, additionalData := e.segmentParams(segment)
+			return e.aead.Seal(dst, nonce, in, additionalData), nil
+		},
+		allowEmpty: e.plainSize == 0,
+	}
+}
+
+// newDecryptingReader decrypts the encrypted content read from 'src', whose plain content starts
+// at 'offset' of the object. Reading it to the end fails if the content was truncated
+func (e *objectEncryption) newDecryptingReader(src io.Reader, offset int64) io.Reader {
+	r := &segmentReader{
+		src:     src,
+		segment: uint64(offset / encryptionSegmentSize),
+		in:      make([]byte, encryptionSegmentSize+aesGCMTagSize),
+		process: func(dst, in []byte, segment uint64) ([]byte, error) {
+			nonce, additionalData := e.segmentParams(segment)
+			plain, err := e.aead.Open(dst, nonce, in, additionalData)
+			if err != nil {
+				return nil, fmt.Errorf("unable to decrypt segment %d, the object was corrupted or modified", segment)
+			}
+			return plain, nil
+		},
+	}
+	r.atEOF = func() error {
+		if r.segment <= e.lastSegment() {
+			return fmt.Errorf("encrypted content is truncated at segment %d", r.segment)
+		}
+		return nil
+	}
+	return r
+}
+
+// readCloser closes the source of an encrypting or decrypting reader
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// segmentReader reads whole segments from 'src' and outputs them after processing
+type segmentReader struct {
+	src        io.Reader
+	segment    uint64
+	in         []byte
+	out        []byte
+	pending    []byte
+	done       bool
+	allowEmpty bool
+	process    func(dst, in []byte, segment uint64) ([]byte, error)
+	atEOF      func() error
+}
+
+func (r *segmentReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			if r.atEOF != nil {
+				if err := r.atEOF(); err != nil {
+					return 0, err
+				}
+			}
+			return 0, io.EOF
+		}
+
+		n, err := io.ReadFull(r.src, r.in)
+		switch {
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			r.done = true
+		case err != nil:
+			return 0, err
+		}
+		if n == 0 && !(r.allowEmpty && r.segment == 0) {
+			continue
+		}
+
+		r.out, err = r.process(r.out[:0], r.in[:n], r.segment)
+		if err != nil {
+			return 0, err
+		}
+		r.pending = r.out
+		r.segment++
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,159 @@
+package common
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKeyFile(t *testing.T, content []byte) string {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func encryptForTest(t *testing.T, cfg Config, plain []byte) (*objectEncryption, []byte) {
+	enc, err := newObjectEncryption(cfg, int64(len(plain)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := io.ReadAll(enc.newEncryptingReader(bytes.NewReader(plain), 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(encrypted)) != EncryptedSize(int64(len(plain))) {
+		t.Fatalf("expected %d encrypted bytes, got %d", EncryptedSize(int64(len(plain))), len(encrypted))
+	}
+	return enc, encrypted
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	key := make([]byte, encryptionKeySize)
+	_, _ = rand.Read(key)
+
+	for _, tc := range []struct {
+		name    string
+		keyFile []byte
+		wrap    string
+	}{
+		{name: "raw key", keyFile: key, wrap: encryptionWrapKey},
+		{name: "base64 key", keyFile: []byte(base64.StdEncoding.EncodeToString(key) + "\n"), wrap: encryptionWrapKey},
+		{name: "passphrase", keyFile: []byte("correct horse battery staple\n"), wrap: encryptionWrapPassword},
+	} {
+		cfg := Config{EncryptKeyFile: writeKeyFile(t, tc.keyFile)}
+
+		for _, size := range []int{0, 10, encryptionSegmentSize, 2*encryptionSegmentSize + 7} {
+			plain := make([]byte, size)
+			_, _ = rand.Read(plain)
+
+			enc, encrypted := encryptForTest(t, cfg, plain)
+			head := HeadObjectResponse{ContentLength: int64(len(encrypted)), Metadata: enc.withMetadata(map[string]string{"author": "me"})}
+			if !head.IsEncrypted() || head.Metadata[encryptionMetadataWrap] != tc.wrap || head.Metadata["author"] != "me" {
+				t.Fatalf("%s: unexpected metadata %v", tc.name, head.Metadata)
+			}
+
+			dec, err := objectDecryption(cfg, head)
+			if err != nil {
+				t.Fatalf("%s/%d: %s", tc.name, size, err)
+			}
+			if dec.plainSize != int64(size) {
+				t.Errorf("%s/%d: expected plain size %d, got %d", tc.name, size, size, dec.plainSize)
+			}
+
+			decrypted, err := io.ReadAll(dec.newDecryptingReader(bytes.NewReader(encrypted), 0))
+			if err != nil {
+				t.Fatalf("%s/%d: %s", tc.name, size, err)
+			}
+			if !bytes.Equal(decrypted, plain) {
+				t.Errorf("%s/%d: decrypted content doesn't match", tc.name, size)
+			}
+		}
+	}
+}
+
+func TestEncryptionChunks(t *testing.T) {
+	cfg := Config{EncryptKeyFile: writeKeyFile(t, []byte("chunks passphrase"))}
+	plain := make([]byte, 3*encryptionSegmentSize+100)
+	_, _ = rand.Read(plain)
+
+	enc, encrypted := encryptForTest(t, cfg, plain)
+
+	// Parts encrypted on their own must match the encryption of the whole content
+	start, end := int64(encryptionSegmentSize), int64(3*encryptionSegmentSize-1)
+	part, err := io.ReadAll(enc.newEncryptingReader(bytes.NewReader(plain[start:end+1]), start))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encStart, encEnd := encryptedRange(start, end)
+	if !bytes.Equal(part, encrypted[encStart:encEnd+1]) {
+		t.Fatalf("encrypted part doesn't match range %d-%d of the encrypted content", encStart, encEnd)
+	}
+
+	// The last chunk has a partial segment
+	start, end = int64(3*encryptionSegmentSize), int64(len(plain)-1)
+	encStart, encEnd = encryptedRange(start, end)
+	if encEnd != int64(len(encrypted)-1) {
+		t.Fatalf("expected last range to end at %d, got %d", len(encrypted)-1, encEnd)
+	}
+	decrypted, err := io.ReadAll(enc.newDecryptingReader(bytes.NewReader(encrypted[encStart:encEnd+1]), start))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plain[start:]) {
+		t.Error("decrypted chunk doesn't match")
+	}
+}
+
+func TestDecryptionFailures(t *testing.T) {
+	cfg := Config{EncryptKeyFile: writeKeyFile(t, []byte("failures passphrase"))}
+	plain := make([]byte, 2*encryptionSegmentSize)
+	_, _ = rand.Read(plain)
+
+	enc, encrypted := encryptForTest(t, cfg, plain)
+	head := HeadObjectResponse{Metadata: enc.withMetadata(nil)}
+
+	if _, err := objectDecryption(Config{}, head); err == nil || !strings.Contains(err.Error(), "--encrypt-key-file") {
+		t.Errorf("expected error asking for the key file, got %v", err)
+	}
+	if _, err := objectDecryption(Config{EncryptKeyFile: writeKeyFile(t, []byte("another passphrase"))}, head); err == nil {
+		t.Error("expected error with another passphrase")
+	}
+	if _, err := objectDecryption(Config{EncryptKeyFile: writeKeyFile(t, make([]byte, encryptionKeySize))}, head); err == nil {
+		t.Error("expected error with a key instead of the passphrase")
+	}
+	if dec, err := objectDecryption(cfg, HeadObjectResponse{}); dec != nil || err != nil {
+		t.Errorf("expected no decryption for plain objects, got %v, %v", dec, err)
+	}
+
+	dec, err := objectDecryption(cfg, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	truncated := encrypted[:encryptionSegmentSize+aesGCMTagSize]
+	if _, err := io.ReadAll(dec.newDecryptingReader(bytes.NewReader(truncated), 0)); err == nil {
+		t.Error("expected error for content truncated at a segment boundary")
+	}
+
+	modified := bytes.Clone(encrypted)
+	modified[10] ^= 1
+	if _, err := io.ReadAll(dec.newDecryptingReader(bytes.NewReader(modified), 0)); err == nil {
+		t.Error("expected error for modified content")
+	}
+}
+
+func TestWithSourceEncryptionMetadata(t *testing.T) {
+	head := HeadObjectResponse{Metadata: map[string]string{encryptionMetadataAlgorithm: encryptionAlgorithm, "author": "me"}}
+
+	metadata := withSourceEncryptionMetadata(map[string]string{"owner": "you"}, head)
+	if len(metadata) != 2 || metadata["owner"] != "you" || metadata[encryptionMetadataAlgorithm] != encryptionAlgorithm {
+		t.Errorf("unexpected metadata %v", metadata)
+	}
+}
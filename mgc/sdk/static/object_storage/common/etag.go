@@ -0,0 +1,101 @@
+package common
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CleanETag removes the surrounding quotes the server sends in ETag values
+func CleanETag(etag string) string {
+	return strings.Trim(etag, "\"")
+}
+
+// ETagParts returns the number of parts of a multipart upload ETag ("<md5>-<parts>"),
+// or 0 if the ETag is the MD5 of the whole content
+func ETagParts(etag string) int {
+	etag = CleanETag(etag)
+	idx := strings.LastIndex(etag, "-")
+	if idx < 0 {
+		return 0
+	}
+	parts, err := strconv.Atoi(etag[idx+1:])
+	if err != nil {
+		return 0
+	}
+	return parts
+}
+
+// FileETag computes the ETag the server would report for the local file at 'path' if it
+// was uploaded in parts of 'partSize' bytes. If 'multipart' is false, it's the MD5 of the
+// whole content, otherwise it's the MD5 of the concatenated part MD5s followed by the part count.
+func FileETag(path string, partSize int64, multipart bool) (string, error) {
+	digest, parts, err := fileDigest(path, md5.New, partSize, multipart)
+	if err != nil {
+		return "", err
+	}
+	if !multipart {
+		return hex.EncodeToString(digest), nil
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(digest), parts), nil
+}
+
+// fileDigest hashes the file at 'path' with 'newHash'. If 'multipart' is true, each part of
+// 'partSize' bytes is hashed separately and the result is the hash of the concatenated part
+// hashes, which is how both multipart ETags and composite checksums are built.
+func fileDigest(path string, newHash func() hash.Hash, partSize int64, multipart bool) (digest []byte, parts int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	if !multipart {
+		h := newHash()
+		if _, err = io.Copy(h, f); err != nil {
+			return nil, 0, err
+		}
+		return h.Sum(nil), 0, nil
+	}
+
+	var digests []byte
+	for {
+		h := newHash()
+		n, err := io.CopyN(h, f, partSize)
+		if n > 0 {
+			digests = h.Sum(digests)
+			parts++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	h := newHash()
+	h.Write(digests)
+	return h.Sum(nil), parts, nil
+}
+
+// LocalFileMatchesETag checks whether the local file at 'path' has the same content as
+// the remote object with 'etag'. Multipart ETags are only comparable if the object was
+// uploaded with the currently configured chunk size, otherwise they are reported as different.
+func LocalFileMatchesETag(path string, etag string, cfg Config) (bool, error) {
+	etag = CleanETag(etag)
+	if etag == "" {
+		return false, nil
+	}
+
+	localETag, err := FileETag(path, int64(cfg.chunkSizeInBytes()), ETagParts(etag) > 0)
+	if err != nil {
+		return false, err
+	}
+	return localETag == etag, nil
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestETagParts(t *testing.T) {
+	tests := map[string]int{
+		`"d41d8cd98f00b204e9800998ecf8427e"`:   0,
+		`"d41d8cd98f00b204e9800998ecf8427e-3"`: 3,
+		"d41d8cd98f00b204e9800998ecf8427e-x":   0,
+		"":                                     0,
+	}
+
+	for etag, expected := range tests {
+		if got := ETagParts(etag); got != expected {
+			t.Errorf("ETagParts(%q) = %d, want %d", etag, got, expected)
+		}
+	}
+}
+
+func TestFileETag(t *testing.T) {
+	content := []byte("0123456789")
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	single := md5.Sum(content)
+	got, err := FileETag(path, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := hex.EncodeToString(single[:]); got != expected {
+		t.Errorf("single part: expected %q, got %q", expected, got)
+	}
+
+	var digests []byte
+	for _, part := range [][]byte{content[0:4], content[4:8], content[8:]} {
+		sum := md5.Sum(part)
+		digests = append(digests, sum[:]...)
+	}
+	multi := md5.Sum(digests)
+	got, err = FileETag(path, 4, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := fmt.Sprintf("%s-3", hex.EncodeToString(multi[:])); got != expected {
+		t.Errorf("multipart: expected %q, got %q", expected, got)
+	}
+}
@@ -16,6 +16,26 @@ type HeadObjectResponse struct {
 	ETag          string
 	ContentType   string
 	StorageClass  string
+	// Stored by upload and copy, see ObjectMetadata
+	CacheControl       string            `json:",omitempty"`
+	ContentDisposition string            `json:",omitempty"`
+	Metadata           map[string]string `json:",omitempty"`
+	TagCount           string            `json:",omitempty"`
+	// Only if uploaded with a checksum algorithm, see Config.ChecksumAlgorithm
+	ChecksumSHA256 string `json:",omitempty"`
+	ChecksumCRC32C string `json:",omitempty"`
+}
+
+// Checksum returns the additional checksum stored with the object, if any
+func (h HeadObjectResponse) Checksum() (ChecksumAlgorithm, string) {
+	switch {
+	case h.ChecksumSHA256 != "":
+		return ChecksumSHA256, h.ChecksumSHA256
+	case h.ChecksumCRC32C != "":
+		return ChecksumCRC32C, h.ChecksumCRC32C
+	default:
+		return ChecksumNone, ""
+	}
 }
 
 func newHeadRequest(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, version string) (*http.Request, error) {
@@ -29,6 +49,9 @@ func newHeadRequest(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, versi
 		return nil, core.UsageError{Err: err}
 	}
 
+	// Otherwise the checksums stored with the object are not returned
+	req.Header.Set(checksumModeHeader, "ENABLED")
+
 	if version != "" {
 		query := req.URL.Query()
 		query.Set("versionId", version)
@@ -83,6 +106,14 @@ func getMetadataFromResponse(resp *http.Response) (HeadObjectResponse, error) {
 		ETag:          resp.Header.Get("ETag"),
 		ContentType:   resp.Header.Get("Content-Type"),
 		StorageClass:  resp.Header.Get("x-amz-storage-class"),
+
+		CacheControl:       resp.Header.Get("Cache-Control"),
+		ContentDisposition: resp.Header.Get("Content-Disposition"),
+		Metadata:           MetadataFromHeaders(resp.Header),
+		TagCount:           resp.Header.Get("x-amz-tagging-count"),
+
+		ChecksumSHA256: resp.Header.Get(ChecksumSHA256.Header()),
+		ChecksumCRC32C: resp.Header.Get(ChecksumCRC32C.Header()),
 	}
 
 	return metadata, nil
@@ -91,6 +91,7 @@ type BucketContent struct {
 	LastModified string `xml:"LastModified"`
 	ContentSize  int64  `xml:"Size"`
 	StorageClass string `xml:"StorageClass"`
+	ETag         string `xml:"ETag"`
 }
 
 type BucketContentDirEntry = *pipeline.SimpleWalkDirEntry[*BucketContent]
@@ -160,24 +161,7 @@ func newListRequest(ctx context.Context, cfg Config, bucketURI mgcSchemaPkg.URI,
 			prefix += delimiter
 		}
 
-		queryEscapedValue := url.QueryEscape(prefix)
-
-		// How for the "fun" part: the aws uri encoding scheme is not the same as go's.
-		//
-		// From the docs:
-		// URI encode every byte. UriEncode() must enforce the following rules:
-		//
-		//   - URI encode every byte except the unreserved characters: 'A'-'Z', 'a'-'z', '0'-'9', '-', '.', '_', and '~'.
-		//   - The space character is a reserved character and must be encoded as "%20" (and not as "+").
-		//   - Each URI encoded byte is formed by a '%' and the two-digit hexadecimal value of the byte.
-		//   - Letters in the hexadecimal value must be uppercase, for example "%1A".
-		//   - Encode the forward slash character, '/', everywhere except in the object key name. For example, if the object key name is photos/Jan/sample.jpg, the forward slash in the key name is not encoded.
-		//
-		// Source: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html#example-signature-calculations
-		awsEscapedValue := strings.ReplaceAll(queryEscapedValue, "+", "%20")
-		awsEscapedValue = strings.ReplaceAll(awsEscapedValue, "*", "%2A")
-		awsEscapedValue = strings.ReplaceAll(awsEscapedValue, "%7E", "~")
-		queryStringParts = append(queryStringParts, "prefix="+awsEscapedValue)
+		queryStringParts = append(queryStringParts, "prefix="+awsQueryEscape(prefix))
 	}
 
 	queryStringParts = append(queryStringParts, "list-type=2")
@@ -202,6 +186,27 @@ func newListRequest(ctx context.Context, cfg Config, bucketURI mgcSchemaPkg.URI,
 	return http.NewRequestWithContext(ctx, http.MethodGet, finalUrl.String(), nil)
 }
 
+func awsQueryEscape(value string) string {
+	queryEscapedValue := url.QueryEscape(value)
+
+	// How for the "fun" part: the aws uri encoding scheme is not the same as go's.
+	//
+	// From the docs:
+	// URI encode every byte. UriEncode() must enforce the following rules:
+	//
+	//   - URI encode every byte except the unreserved characters: 'A'-'Z', 'a'-'z', '0'-'9', '-', '.', '_', and '~'.
+	//   - The space character is a reserved character and must be encoded as "%20" (and not as "+").
+	//   - Each URI encoded byte is formed by a '%' and the two-digit hexadecimal value of the byte.
+	//   - Letters in the hexadecimal value must be uppercase, for example "%1A".
+	//   - Encode the forward slash character, '/', everywhere except in the object key name. For example, if the object key name is photos/Jan/sample.jpg, the forward slash in the key name is not encoded.
+	//
+	// Source: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html#example-signature-calculations
+	awsEscapedValue := strings.ReplaceAll(queryEscapedValue, "+", "%20")
+	awsEscapedValue = strings.ReplaceAll(awsEscapedValue, "*", "%2A")
+	awsEscapedValue = strings.ReplaceAll(awsEscapedValue, "%7E", "~")
+	return awsEscapedValue
+}
+
 func buildListRequestURL(cfg Config, bucketURI mgcSchemaPkg.URI) (*url.URL, error) {
 	u, err := BuildBucketHostURL(cfg, NewBucketNameFromURI(bucketURI))
 	if err != nil {
@@ -0,0 +1,131 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcHttpPkg "github.com/MagaluCloud/magalu/mgc/core/http"
+	"github.com/MagaluCloud/magalu/mgc/core/pipeline"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+// BucketObjectVersion is either a version of an object or a delete marker, as returned by ListObjectVersions
+type BucketObjectVersion struct {
+	BucketContent
+	VersionID      string `xml:"VersionId"`
+	IsLatest       bool   `xml:"IsLatest"`
+	IsDeleteMarker bool   `xml:"-"`
+}
+
+type BucketObjectVersionDirEntry = *pipeline.SimpleWalkDirEntry[*BucketObjectVersion]
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectVersions.html
+type listObjectVersionsResponse struct {
+	Versions            []*BucketObjectVersion `xml:"Version"`
+	DeleteMarkers       []*BucketObjectVersion `xml:"DeleteMarker"`
+	IsTruncated         bool                   `xml:"IsTruncated"`
+	NextKeyMarker       string                 `xml:"NextKeyMarker"`
+	NextVersionIdMarker string                 `xml:"NextVersionIdMarker"`
+}
+
+type listVersionsMarker struct {
+	key       string
+	versionID string
+}
+
+func newListVersionsRequest(ctx context.Context, cfg Config, bucketURI mgcSchemaPkg.URI, marker listVersionsMarker) (*http.Request, error) {
+	finalUrl, err := buildListRequestURL(cfg, bucketURI)
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	// Same signing caveats as newListRequest: parts must be AWS-escaped and sorted
+	queryStringParts := []string{"versions="}
+	if prefix := bucketURI.Path(); prefix != "" {
+		queryStringParts = append(queryStringParts, "prefix="+awsQueryEscape(prefix))
+	}
+	if marker.key != "" {
+		queryStringParts = append(queryStringParts, "key-marker="+awsQueryEscape(marker.key))
+	}
+	if marker.versionID != "" {
+		queryStringParts = append(queryStringParts, "version-id-marker="+awsQueryEscape(marker.versionID))
+	}
+
+	sort.Strings(queryStringParts)
+	finalUrl.RawQuery = strings.Join(queryStringParts, "&")
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, finalUrl.String(), nil)
+}
+
+// ListVersionsGenerator lists every version and delete marker of the objects under dst, including the
+// noncurrent ones. Each entry is a BucketObjectVersionDirEntry
+func ListVersionsGenerator(ctx context.Context, dst mgcSchemaPkg.URI, cfg Config, onNewPage func(objCount uint64)) (outputChan <-chan pipeline.WalkDirEntry) {
+	ch := make(chan pipeline.WalkDirEntry)
+	outputChan = ch
+
+	logger := listObjectsLogger().Named("ListVersionsGenerator").With("dst", dst)
+
+	generator := func() {
+		defer func() {
+			close(ch)
+			logger.Info("closed output channel")
+		}()
+
+		var marker listVersionsMarker
+		for {
+			req, err := newListVersionsRequest(ctx, cfg, dst, marker)
+			if err != nil {
+				logger.Warnw("failed to create request", "err", err)
+				return
+			}
+
+			resp, err := SendRequest(ctx, req, cfg)
+			if err != nil {
+				logger.Warnw("failed to send request", "err", err)
+				return
+			}
+
+			result, err := UnwrapResponse[listObjectVersionsResponse](resp, req)
+			if err != nil {
+				logger.Warnw("list versions request failed", "err", err, "req", (*mgcHttpPkg.LogRequest)(req))
+				select {
+				case <-ctx.Done():
+					logger.Debugw("context.Done()", "err", err)
+				case ch <- pipeline.NewSimpleWalkDirEntry[*BucketObjectVersion](dst.Path(), nil, err):
+				}
+				return
+			}
+
+			for _, deleteMarker := range result.DeleteMarkers {
+				deleteMarker.IsDeleteMarker = true
+			}
+			versions := append(result.Versions, result.DeleteMarkers...)
+
+			if onNewPage != nil {
+				onNewPage(uint64(len(versions)))
+			}
+
+			for _, version := range versions {
+				select {
+				case <-ctx.Done():
+					logger.Debugw("context.Done()", "err", ctx.Err())
+					return
+				case ch <- pipeline.NewSimpleWalkDirEntry(version.Key, version, nil):
+				}
+			}
+
+			if !result.IsTruncated {
+				logger.Info("finished reading versions")
+				return
+			}
+			marker = listVersionsMarker{key: result.NextKeyMarker, versionID: result.NextVersionIdMarker}
+		}
+	}
+
+	logger.Info("list versions generation start")
+	go generator()
+	return
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core/config"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+func TestListObjectVersionsResponseUnmarshal(t *testing.T) {
+	body := `<ListVersionsResult>
+	<IsTruncated>true</IsTruncated>
+	<NextKeyMarker>dir/b.txt</NextKeyMarker>
+	<NextVersionIdMarker>v3</NextVersionIdMarker>
+	<Version><Key>dir/a.txt</Key><VersionId>v1</VersionId><IsLatest>true</IsLatest><Size>10</Size></Version>
+	<DeleteMarker><Key>dir/b.txt</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest></DeleteMarker>
+	<Version><Key>dir/b.txt</Key><VersionId>v3</VersionId><IsLatest>false</IsLatest><Size>5</Size></Version>
+</ListVersionsResult>`
+
+	var result listObjectVersionsResponse
+	if err := xml.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Versions) != 2 || len(result.DeleteMarkers) != 1 {
+		t.Fatalf("expected 2 versions and 1 delete marker, got %d and %d", len(result.Versions), len(result.DeleteMarkers))
+	}
+	if v := result.Versions[1]; v.Key != "dir/b.txt" || v.VersionID != "v3" || v.IsLatest || v.ContentSize != 5 {
+		t.Errorf("unexpected version: %+v", v)
+	}
+	if m := result.DeleteMarkers[0]; m.Key != "dir/b.txt" || m.VersionID != "v2" || !m.IsLatest {
+		t.Errorf("unexpected delete marker: %+v", m)
+	}
+	if !result.IsTruncated || result.NextKeyMarker != "dir/b.txt" || result.NextVersionIdMarker != "v3" {
+		t.Errorf("unexpected pagination: %+v", result)
+	}
+}
+
+func TestNewListVersionsRequest(t *testing.T) {
+	cfg := Config{NetworkConfig: config.NetworkConfig{ServerUrl: "https://s3.example.com"}}
+
+	for _, tc := range []struct {
+		name     string
+		dst      mgcSchemaPkg.URI
+		marker   listVersionsMarker
+		expected string
+	}{
+		{name: "bucket", dst: "s3://bucket", expected: "versions="},
+		{name: "prefix", dst: "s3://bucket/my dir", expected: "prefix=my%20dir&versions="},
+		{
+			name:     "next page",
+			dst:      "s3://bucket",
+			marker:   listVersionsMarker{key: "a b.txt", versionID: "v1"},
+			expected: "key-marker=a%20b.txt&version-id-marker=v1&versions=",
+		},
+	} {
+		req, err := newListVersionsRequest(context.Background(), cfg, tc.dst, tc.marker)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if req.URL.RawQuery != tc.expected {
+			t.Errorf("%s: expected query %q, got %q", tc.name, tc.expected, req.URL.RawQuery)
+		}
+	}
+}
+
+func TestNewCopyRequestVersion(t *testing.T) {
+	cfg := Config{NetworkConfig: config.NetworkConfig{ServerUrl: "https://s3.example.com"}}
+
+	req, err := newCopyRequest(context.Background(), cfg, "s3://bucket/file.txt", "s3://bucket/file.txt", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := req.Header.Get("x-amz-copy-source"); got != "bucket/file.txt?versionId=v1" {
+		t.Errorf("expected source version in copy source, got %q", got)
+	}
+	if req.URL.Query().Has("versionId") {
+		t.Errorf("expected no version on the destination, got %q", req.URL.RawQuery)
+	}
+}
@@ -0,0 +1,186 @@
+package common
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+type MultipartUpload struct {
+	Key          string `xml:"Key" json:"key"`
+	UploadId     string `xml:"UploadId" json:"upload_id"`
+	Initiated    string `xml:"Initiated" json:"initiated"`
+	StorageClass string `xml:"StorageClass" json:"storage_class,omitempty"`
+}
+
+type listMultipartUploadsResponse struct {
+	XMLName            xml.Name           `xml:"ListMultipartUploadsResult"`
+	Bucket             string             `xml:"Bucket"`
+	Uploads            []*MultipartUpload `xml:"Upload"`
+	NextKeyMarker      string             `xml:"NextKeyMarker"`
+	NextUploadIdMarker string             `xml:"NextUploadIdMarker"`
+	IsTruncated        bool               `xml:"IsTruncated"`
+}
+
+type UploadedPart struct {
+	PartNumber     int    `xml:"PartNumber"`
+	ETag           string `xml:"ETag"`
+	Size           int64  `xml:"Size"`
+	ChecksumSHA256 string `xml:"ChecksumSHA256"`
+	ChecksumCRC32C string `xml:"ChecksumCRC32C"`
+}
+
+// Checksum returns the checksum of the part computed with 'algorithm', if it was sent on its upload
+func (p *UploadedPart) Checksum(algorithm ChecksumAlgorithm) string {
+	switch algorithm {
+	case ChecksumSHA256:
+		return p.ChecksumSHA256
+	case ChecksumCRC32C:
+		return p.ChecksumCRC32C
+	default:
+		return ""
+	}
+}
+
+type listPartsResponse struct {
+	XMLName              xml.Name        `xml:"ListPartsResult"`
+	Parts                []*UploadedPart `xml:"Part"`
+	NextPartNumberMarker int             `xml:"NextPartNumberMarker"`
+	IsTruncated          bool            `xml:"IsTruncated"`
+}
+
+func newListMultipartUploadsRequest(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, keyMarker, uploadIdMarker string) (*http.Request, error) {
+	url, err := BuildBucketHostURL(cfg, NewBucketNameFromURI(dst))
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListMultipartUploads.html
+	query := url.Query()
+	query.Set("uploads", "")
+	if prefix := dst.Path(); prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if keyMarker != "" {
+		query.Set("key-marker", keyMarker)
+	}
+	if uploadIdMarker != "" {
+		query.Set("upload-id-marker", uploadIdMarker)
+	}
+	url.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+}
+
+// ListMultipartUploads lists all in-progress multipart uploads in the bucket of 'dst',
+// restricted to the keys starting with the path of 'dst', if any
+func ListMultipartUploads(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI) (uploads []*MultipartUpload, err error) {
+	var keyMarker, uploadIdMarker string
+	for {
+		req, err := newListMultipartUploadsRequest(ctx, cfg, dst, keyMarker, uploadIdMarker)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := SendRequest(ctx, req, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := UnwrapResponse[listMultipartUploadsResponse](resp, req)
+		if err != nil {
+			return nil, err
+		}
+
+		uploads = append(uploads, result.Uploads...)
+		if !result.IsTruncated {
+			return uploads, nil
+		}
+
+		keyMarker, uploadIdMarker = result.NextKeyMarker, result.NextUploadIdMarker
+	}
+}
+
+func newListPartsRequest(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, uploadId string, partNumberMarker int) (*http.Request, error) {
+	url, err := BuildBucketHostWithPathURL(cfg, NewBucketNameFromURI(dst), dst.Path())
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListParts.html
+	query := url.Query()
+	query.Set("uploadId", uploadId)
+	if partNumberMarker > 0 {
+		query.Set("part-number-marker", fmt.Sprint(partNumberMarker))
+	}
+	url.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodGet, url.String(), nil)
+}
+
+// ListParts lists all parts already uploaded for the multipart upload 'uploadId' of 'dst'
+func ListParts(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, uploadId string) (parts []*UploadedPart, err error) {
+	var partNumberMarker int
+	for {
+		req, err := newListPartsRequest(ctx, cfg, dst, uploadId, partNumberMarker)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := SendRequest(ctx, req, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := UnwrapResponse[listPartsResponse](resp, req)
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, result.Parts...)
+		if !result.IsTruncated || result.NextPartNumberMarker <= partNumberMarker {
+			return parts, nil
+		}
+
+		partNumberMarker = result.NextPartNumberMarker
+	}
+}
+
+func newAbortMultipartUploadRequest(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, uploadId string) (*http.Request, error) {
+	url, err := BuildBucketHostWithPathURL(cfg, NewBucketNameFromURI(dst), dst.Path())
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	// https://docs.aws.amazon.com/AmazonS3/latest/API/API_AbortMultipartUpload.html
+	query := url.Query()
+	query.Set("uploadId", uploadId)
+	url.RawQuery = query.Encode()
+
+	return http.NewRequestWithContext(ctx, http.MethodDelete, url.String(), nil)
+}
+
+// AbortMultipartUpload aborts the multipart upload 'uploadId' of 'dst', discarding all
+// of its parts. The matching upload journal entry, if any, is also removed
+func AbortMultipartUpload(ctx context.Context, cfg Config, dst mgcSchemaPkg.URI, uploadId string) error {
+	req, err := newAbortMultipartUploadRequest(ctx, cfg, dst, uploadId)
+	if err != nil {
+		return err
+	}
+
+	resp, err := SendRequest(ctx, req, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err = ExtractErr(resp, req); err != nil {
+		return err
+	}
+
+	removeUploadJournalEntry(ctx, dst, uploadId)
+	return nil
+}
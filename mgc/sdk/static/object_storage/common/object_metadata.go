@@ -0,0 +1,126 @@
+package common
+
+import (
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	metadataHeaderPrefix    = "X-Amz-Meta-"
+	taggingHeader           = "X-Amz-Tagging"
+	metadataDirectiveHeader = "X-Amz-Metadata-Directive"
+	taggingDirectiveHeader  = "X-Amz-Tagging-Directive"
+)
+
+// Headers stored with the object. They're set on upload and, on copy, replace the ones of the source object
+type ObjectMetadata struct {
+	ContentType        string            `json:"content_type,omitempty" jsonschema:"description=MIME type of the object. On upload it's detected from the file extension or content if not set,example=text/html"`
+	CacheControl       string            `json:"cache_control,omitempty" jsonschema:"description=Caching behavior of the object when served,example=max-age=3600"`
+	ContentDisposition string            `json:"content_disposition,omitempty" jsonschema:"description=How the object is presented when downloaded by browsers,example=attachment"`
+	Metadata           map[string]string `json:"metadata,omitempty" jsonschema:"description=Custom metadata stored as x-amz-meta-* headers,example={\"author\": \"me\"}"`
+	Tags               map[string]string `json:"tags,omitempty" jsonschema:"description=Tags of the object,example={\"env\": \"prod\"}"`
+}
+
+func (o ObjectMetadata) JSONSchemaExtend(s *jsonschema.Schema) {
+	for _, name := range []string{"metadata", "tags"} {
+		if prop, exists := s.Properties.Get(name); exists {
+			prop.Type = "object"
+			prop.AdditionalProperties = &jsonschema.Schema{Type: "string"}
+		}
+	}
+}
+
+func (o ObjectMetadata) hasHeaders() bool {
+	return o.ContentType != "" || o.CacheControl != "" || o.ContentDisposition != "" || len(o.Metadata) > 0
+}
+
+func (o ObjectMetadata) IsEmpty() bool {
+	return !o.hasHeaders() && len(o.Tags) == 0
+}
+
+// Encodes the tags as expected by the x-amz-tagging header, ie: "env=prod&team=web"
+func EncodeTagging(tags map[string]string) string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// Sets the headers of PutObject and CreateMultipartUpload requests
+func (o ObjectMetadata) setHeaders(req *http.Request) {
+	if o.ContentType != "" {
+		req.Header.Set("Content-Type", o.ContentType)
+	}
+	if o.CacheControl != "" {
+		req.Header.Set("Cache-Control", o.CacheControl)
+	}
+	if o.ContentDisposition != "" {
+		req.Header.Set("Content-Disposition", o.ContentDisposition)
+	}
+	for k, v := range o.Metadata {
+		req.Header.Set(metadataHeaderPrefix+k, v)
+	}
+	if len(o.Tags) > 0 {
+		req.Header.Set(taggingHeader, EncodeTagging(o.Tags))
+	}
+}
+
+// CopyObject keeps the metadata and tags of the source unless told to replace them,
+// in which case all the source ones are dropped
+func (o ObjectMetadata) setCopyHeaders(req *http.Request) {
+	o.setHeaders(req)
+	if o.hasHeaders() {
+		req.Header.Set(metadataDirectiveHeader, "REPLACE")
+	}
+	if len(o.Tags) > 0 {
+		req.Header.Set(taggingDirectiveHeader, "REPLACE")
+	}
+}
+
+// Returns the user metadata in the x-amz-meta-* response headers, without the prefix
+func MetadataFromHeaders(header http.Header) map[string]string {
+	var metadata map[string]string
+	for k, v := range header {
+		if !strings.HasPrefix(http.CanonicalHeaderKey(k), metadataHeaderPrefix) || len(v) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[strings.ToLower(k[len(metadataHeaderPrefix):])] = v[0]
+	}
+	return metadata
+}
+
+// Detects the MIME type by the file extension, falling back to sniffing its first bytes
+func DetectContentType(filePath mgcSchemaPkg.FilePath, fileInfo fs.FileInfo) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(fileInfo.Name())); mimeType != "" {
+		return mimeType
+	}
+
+	f, err := readContent(filePath, fileInfo)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	// See http.DetectContentType(), which considers at most 512 bytes
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return ""
+	}
+	if n == 0 {
+		return ""
+	}
+	return http.DetectContentType(buf[:n])
+}
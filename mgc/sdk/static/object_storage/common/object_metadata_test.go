@@ -0,0 +1,87 @@
+package common
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+func TestObjectMetadataSetCopyHeaders(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		metadata          ObjectMetadata
+		metadataDirective string
+		taggingDirective  string
+	}{
+		{name: "keep source"},
+		{name: "replace headers", metadata: ObjectMetadata{CacheControl: "max-age=60"}, metadataDirective: "REPLACE"},
+		{name: "replace tags", metadata: ObjectMetadata{Tags: map[string]string{"env": "prod"}}, taggingDirective: "REPLACE"},
+		{
+			name:              "replace all",
+			metadata:          ObjectMetadata{Metadata: map[string]string{"author": "me"}, Tags: map[string]string{"env": "prod"}},
+			metadataDirective: "REPLACE",
+			taggingDirective:  "REPLACE",
+		},
+	} {
+		req, _ := http.NewRequest(http.MethodPut, "https://bucket.example.com/file", nil)
+		tc.metadata.setCopyHeaders(req)
+
+		if got := req.Header.Get(metadataDirectiveHeader); got != tc.metadataDirective {
+			t.Errorf("%s: expected metadata directive %q, got %q", tc.name, tc.metadataDirective, got)
+		}
+		if got := req.Header.Get(taggingDirectiveHeader); got != tc.taggingDirective {
+			t.Errorf("%s: expected tagging directive %q, got %q", tc.name, tc.taggingDirective, got)
+		}
+	}
+}
+
+func TestObjectMetadataSetHeaders(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPut, "https://bucket.example.com/file", nil)
+	ObjectMetadata{
+		ContentType: "text/css",
+		Metadata:    map[string]string{"author": "me"},
+		Tags:        map[string]string{"env": "prod", "team": "web & ops"},
+	}.setHeaders(req)
+
+	expected := map[string]string{
+		"Content-Type":      "text/css",
+		"X-Amz-Meta-Author": "me",
+		"X-Amz-Tagging":     "env=prod&team=web+%26+ops",
+	}
+	for k, v := range expected {
+		if got := req.Header.Get(k); got != v {
+			t.Errorf("expected header %s=%q, got %q", k, v, got)
+		}
+	}
+
+	metadata := MetadataFromHeaders(req.Header)
+	if len(metadata) != 1 || metadata["author"] != "me" {
+		t.Errorf("unexpected metadata from headers: %#v", metadata)
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	dir := t.TempDir()
+	for name, expected := range map[string]string{
+		"style.css": "text/css; charset=utf-8",
+		"README":    "text/plain; charset=utf-8",
+		"image":     "image/png",
+	} {
+		content := []byte("some text")
+		if name == "image" {
+			content = []byte("\x89PNG\x0D\x0A\x1A\x0A")
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Stat(path)
+
+		if got := DetectContentType(mgcSchemaPkg.FilePath(path), info); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+}
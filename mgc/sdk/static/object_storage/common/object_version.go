@@ -0,0 +1,15 @@
+package common
+
+// ObjectVersion selects a version of the object the command acts on. It's squashed in the parameters
+type ObjectVersion struct {
+	Version    string `json:"version_id,omitempty" jsonschema:"description=ID of the version of the object. See 'objects versions'"`
+	ObjVersion string `json:"obj_version,omitempty" jsonschema:"description=Deprecated: use version_id" mgc:"hidden"`
+}
+
+// VersionID accepts the deprecated 'obj_version' name of the parameter
+func (v ObjectVersion) VersionID() string {
+	if v.Version == "" {
+		return v.ObjVersion
+	}
+	return v.Version
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+func TestDeprecatedVersionParams(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		params   map[string]any
+		expected string
+	}{
+		{name: "version_id", params: map[string]any{"version_id": "v1"}, expected: "v1"},
+		{name: "deprecated", params: map[string]any{"obj_version": "v2"}, expected: "v2"},
+		{name: "both", params: map[string]any{"version_id": "v1", "obj_version": "v2"}, expected: "v1"},
+		{name: "none", params: map[string]any{}, expected: ""},
+	} {
+		download, err := utils.DecodeNewValue[DownloadObjectParams](tc.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		copyParams, err := utils.DecodeNewValue[CopyObjectParams](tc.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		deleteParams, err := utils.DecodeNewValue[DeleteObjectParams](tc.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for command, got := range map[string]string{"download": download.VersionID(), "copy": copyParams.VersionID(), "delete": deleteParams.VersionID()} {
+			if got != tc.expected {
+				t.Errorf("%s: expected %s version %q, got %q", tc.name, command, tc.expected, got)
+			}
+		}
+	}
+}
@@ -12,6 +12,7 @@ type smallFileCopier struct {
 	dst          mgcSchemaPkg.URI
 	version      string
 	storageClass string
+	metadata     ObjectMetadata
 }
 
 var _ copier = (*smallFileCopier)(nil)
@@ -25,6 +26,7 @@ func (u *smallFileCopier) Copy(ctx context.Context) error {
 	if u.storageClass != "" {
 		req.Header.Set("X-Amz-Storage-Class", u.storageClass)
 	}
+	u.metadata.setCopyHeaders(req)
 
 	resp, err := SendRequest(ctx, req, u.cfg)
 	if err != nil {
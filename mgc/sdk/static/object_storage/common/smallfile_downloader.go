@@ -12,10 +12,12 @@ import (
 )
 
 type smallFileDownloader struct {
-	cfg     Config
-	src     mgcSchemaPkg.URI
-	dst     mgcSchemaPkg.FilePath
-	version string
+	cfg        Config
+	src        mgcSchemaPkg.URI
+	dst        mgcSchemaPkg.FilePath
+	version    string
+	head       HeadObjectResponse
+	encryption *objectEncryption
 }
 
 var _ downloader = (*smallFileDownloader)(nil)
@@ -41,6 +43,9 @@ func (u *smallFileDownloader) Download(ctx context.Context) error {
 	defer progressReporter.End()
 
 	resp.Body = progress_report.NewReporterReader(resp.Body, progressReporter.Report)
+	if u.encryption != nil {
+		resp.Body = readCloser{u.encryption.newDecryptingReader(resp.Body, 0), resp.Body}
+	}
 
 	dir := path.Dir(u.dst.String())
 	if len(dir) != 0 {
@@ -53,5 +58,11 @@ func (u *smallFileDownloader) Download(ctx context.Context) error {
 		return err
 	}
 
+	if err := verifyDownload(u.dst.String(), u.head, u.cfg); err != nil {
+		// Contents can't be trusted, so don't leave them behind
+		_ = os.Remove(u.dst.String())
+		return err
+	}
+
 	return nil
 }
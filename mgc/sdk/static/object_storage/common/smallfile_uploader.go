@@ -12,10 +12,11 @@ import (
 type smallFileUploader struct {
 	cfg          Config
 	dst          mgcSchemaPkg.URI
-	mimeType     string
+	metadata     ObjectMetadata
 	fileInfo     fs.FileInfo
 	filePath     mgcSchemaPkg.FilePath
 	storageClass string
+	encryption   *objectEncryption
 }
 
 var _ uploader = (*smallFileUploader)(nil)
@@ -27,6 +28,9 @@ func (u *smallFileUploader) Upload(ctx context.Context) error {
 		if err != nil {
 			return nil, fmt.Errorf("error reading file: %w", err)
 		}
+		if u.encryption != nil {
+			return readCloser{u.encryption.newEncryptingReader(reader, 0), reader}, nil
+		}
 		return reader, nil
 	}
 
@@ -39,12 +43,16 @@ func (u *smallFileUploader) Upload(ctx context.Context) error {
 		return err
 	}
 
-	req.Header.Set("Content-Type", u.mimeType)
+	u.metadata.setHeaders(req)
 
 	if u.storageClass != "" {
 		req.Header.Set("X-Amz-Storage-Class", u.storageClass)
 	}
 
+	if _, err = setChecksumHeader(req, u.cfg.ChecksumAlgorithm); err != nil {
+		return fmt.Errorf("unable to compute checksum of the file: %w", err)
+	}
+
 	resp, err := SendRequest(ctx, req, u.cfg)
 	if err != nil {
 		return err
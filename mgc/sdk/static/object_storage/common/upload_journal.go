@@ -0,0 +1,157 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/invopop/yaml"
+	"go.uber.org/zap"
+)
+
+const uploadJournalFilename = "object-storage-uploads.yaml"
+
+// The journal refers to local files and pending uploads, so it's never exported or cloned with the workspace
+func init() {
+	profile_manager.RegisterLocalFile(uploadJournalFilename)
+}
+
+var uploadJournalLogger = utils.NewLazyLoader(func() *zap.SugaredLogger {
+	return logger().Named("uploadJournal")
+})
+
+// Serializes read-modify-write cycles of the journal file, as multiple uploads
+// (ie: upload-dir) may record their parts concurrently
+var uploadJournalMutex sync.Mutex
+
+// UploadJournalEntry records a pending multipart upload, so it may be resumed
+// by a later execution instead of sending all parts again.
+type UploadJournalEntry struct {
+	Source       string            `json:"source"`
+	Destination  string            `json:"destination"`
+	UploadId     string            `json:"upload_id"`
+	FileSize     int64             `json:"file_size"`
+	ModTime      time.Time         `json:"mod_time"`
+	ChunkSize    uint64            `json:"chunk_size"`
+	StorageClass string            `json:"storage_class,omitempty"`
+	Checksum     ChecksumAlgorithm `json:"checksum,omitempty"`
+	Parts        map[int]string    `json:"parts,omitempty"`
+}
+
+// Journal entries are keyed by destination URI, only one pending upload per object is tracked
+type uploadJournal map[string]*UploadJournalEntry
+
+func currentProfile(ctx context.Context) *profile_manager.Profile {
+	m := profile_manager.FromContext(ctx)
+	if m == nil {
+		return nil
+	}
+	return m.Current()
+}
+
+func readUploadJournal(p *profile_manager.Profile) uploadJournal {
+	journal := uploadJournal{}
+	data, err := p.Read(uploadJournalFilename)
+	if err != nil {
+		return journal
+	}
+
+	if err = yaml.Unmarshal(data, &journal); err != nil {
+		uploadJournalLogger().Warnw("ignored bad format upload journal", "error", err)
+		return uploadJournal{}
+	}
+	return journal
+}
+
+func writeUploadJournal(p *profile_manager.Profile, journal uploadJournal) {
+	var err error
+	if len(journal) == 0 {
+		err = p.Delete(uploadJournalFilename)
+	} else {
+		var data []byte
+		data, err = yaml.Marshal(journal)
+		if err == nil {
+			err = p.Write(uploadJournalFilename, data)
+		}
+	}
+
+	if err != nil {
+		uploadJournalLogger().Warnw("unable to persist upload journal", "error", err)
+	}
+}
+
+// Journal failures must never fail the upload itself, at most they make it not resumable
+func updateUploadJournal(ctx context.Context, update func(journal uploadJournal)) {
+	p := currentProfile(ctx)
+	if p == nil {
+		return
+	}
+
+	uploadJournalMutex.Lock()
+	defer uploadJournalMutex.Unlock()
+
+	journal := readUploadJournal(p)
+	update(journal)
+	writeUploadJournal(p, journal)
+}
+
+// ListUploadJournal returns all pending uploads recorded in the current workspace
+func ListUploadJournal(ctx context.Context) (entries []*UploadJournalEntry) {
+	p := currentProfile(ctx)
+	if p == nil {
+		return
+	}
+
+	uploadJournalMutex.Lock()
+	defer uploadJournalMutex.Unlock()
+
+	for _, entry := range readUploadJournal(p) {
+		entries = append(entries, entry)
+	}
+	return
+}
+
+func getUploadJournalEntry(ctx context.Context, dst mgcSchemaPkg.URI) *UploadJournalEntry {
+	p := currentProfile(ctx)
+	if p == nil {
+		return nil
+	}
+
+	uploadJournalMutex.Lock()
+	defer uploadJournalMutex.Unlock()
+
+	return readUploadJournal(p)[dst.String()]
+}
+
+func putUploadJournalEntry(ctx context.Context, entry *UploadJournalEntry) {
+	updateUploadJournal(ctx, func(journal uploadJournal) {
+		journal[entry.Destination] = entry
+	})
+}
+
+func recordUploadJournalPart(ctx context.Context, dst mgcSchemaPkg.URI, uploadId string, partNumber int, etag string) {
+	updateUploadJournal(ctx, func(journal uploadJournal) {
+		entry := journal[dst.String()]
+		if entry == nil || entry.UploadId != uploadId {
+			return
+		}
+		if entry.Parts == nil {
+			entry.Parts = map[int]string{}
+		}
+		entry.Parts[partNumber] = etag
+	})
+}
+
+// If 'uploadId' is empty, the entry is removed regardless of its upload ID
+func removeUploadJournalEntry(ctx context.Context, dst mgcSchemaPkg.URI, uploadId string) {
+	updateUploadJournal(ctx, func(journal uploadJournal) {
+		entry := journal[dst.String()]
+		if entry == nil || (uploadId != "" && entry.UploadId != uploadId) {
+			return
+		}
+		delete(journal, dst.String())
+	})
+}
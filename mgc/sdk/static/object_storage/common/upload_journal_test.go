@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+func TestUploadJournal(t *testing.T) {
+	m, _ := profile_manager.NewInMemoryProfileManager()
+	ctx := profile_manager.NewContext(context.Background(), m)
+
+	dst := mgcSchemaPkg.URI("s3://bucket/dir/file.bin")
+	entry := &UploadJournalEntry{
+		Source:      "/tmp/file.bin",
+		Destination: dst.String(),
+		UploadId:    "upload-1",
+		FileSize:    1024,
+		ModTime:     time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		ChunkSize:   512,
+	}
+
+	putUploadJournalEntry(ctx, entry)
+	recordUploadJournalPart(ctx, dst, "upload-1", 1, `"etag-1"`)
+	recordUploadJournalPart(ctx, dst, "other-upload", 2, `"etag-2"`)
+
+	archive, err := m.Export(m.Current(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := archive.Files[uploadJournalFilename]; ok {
+		t.Errorf("the journal must not be exported with the workspace")
+	}
+
+	got := getUploadJournalEntry(ctx, dst)
+	if got == nil {
+		t.Fatalf("expected journal entry for %q", dst)
+	}
+	if !got.ModTime.Equal(entry.ModTime) {
+		t.Errorf("expected mod time %v, got %v", entry.ModTime, got.ModTime)
+	}
+	if len(got.Parts) != 1 || got.Parts[1] != `"etag-1"` {
+		t.Errorf("expected only part 1 to be recorded, got %v", got.Parts)
+	}
+
+	removeUploadJournalEntry(ctx, dst, "other-upload")
+	if getUploadJournalEntry(ctx, dst) == nil {
+		t.Errorf("entry should not be removed for a different upload ID")
+	}
+
+	removeUploadJournalEntry(ctx, dst, "upload-1")
+	if entries := ListUploadJournal(ctx); len(entries) != 0 {
+		t.Errorf("expected empty journal, got %v", entries)
+	}
+}
+
+func TestUploadJournalWithoutProfile(t *testing.T) {
+	ctx := context.Background()
+	dst := mgcSchemaPkg.URI("s3://bucket/file.bin")
+
+	putUploadJournalEntry(ctx, &UploadJournalEntry{Destination: dst.String(), UploadId: "upload-1"})
+	if entry := getUploadJournalEntry(ctx, dst); entry != nil {
+		t.Errorf("expected no journal without profile, got %v", entry)
+	}
+}
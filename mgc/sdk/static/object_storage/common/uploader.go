@@ -6,10 +6,8 @@ import (
 	"io"
 	"io/fs"
 	"math"
-	"mime"
 	"net/http"
 	"os"
-	"path/filepath"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
 	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
@@ -19,7 +17,7 @@ type uploader interface {
 	Upload(context.Context) error
 }
 
-func NewUploader(cfg Config, src mgcSchemaPkg.FilePath, dst mgcSchemaPkg.URI, storageClass string) (uploader, error) {
+func NewUploader(ctx context.Context, cfg Config, src mgcSchemaPkg.FilePath, dst mgcSchemaPkg.URI, storageClass string, metadata ObjectMetadata) (uploader, error) {
 	fileInfo, err := os.Stat(src.String())
 	if err != nil {
 		return nil, fmt.Errorf("error reading object: %w", err)
@@ -28,8 +26,22 @@ func NewUploader(cfg Config, src mgcSchemaPkg.FilePath, dst mgcSchemaPkg.URI, st
 		return nil, fmt.Errorf("cannot upload a directory, use 'upload-dir' instead")
 	}
 
+	if planned := newPlannedTransfer(ctx, plannedUpload, src.String(), dst.String()); planned != nil {
+		return planned, nil
+	}
+
 	size := fileInfo.Size()
-	mimeType := mime.TypeByExtension(filepath.Ext(fileInfo.Name()))
+	if metadata.ContentType == "" {
+		metadata.ContentType = DetectContentType(src, fileInfo)
+	}
+
+	encryption, err := newObjectEncryption(cfg, size)
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+	if encryption != nil {
+		metadata.Metadata = encryption.withMetadata(metadata.Metadata)
+	}
 
 	chunkN := int(math.Ceil(float64(size) / float64(cfg.chunkSizeInBytes())))
 
@@ -37,20 +49,22 @@ func NewUploader(cfg Config, src mgcSchemaPkg.FilePath, dst mgcSchemaPkg.URI, st
 		return &bigFileUploader{
 			cfg:          cfg,
 			dst:          dst,
-			mimeType:     mimeType,
+			metadata:     metadata,
 			fileInfo:     fileInfo,
 			filePath:     src,
 			workerN:      cfg.Workers,
 			storageClass: storageClass,
+			encryption:   encryption,
 		}, nil
 	} else {
 		return &smallFileUploader{
 			cfg:          cfg,
 			dst:          dst,
-			mimeType:     mimeType,
+			metadata:     metadata,
 			fileInfo:     fileInfo,
 			filePath:     src,
 			storageClass: storageClass,
+			encryption:   encryption,
 		}, nil
 	}
 }
@@ -14,7 +14,7 @@ import (
 
 type setObjectACLParams struct {
 	Destination            mgcSchemaPkg.URI `json:"dst" jsonschema:"description=The full object URL to set the ACL information,example:my-bucket/file.txt" mgc:"positional"`
-	Version                string           `json:"obj_version,omitempty" jsonschema:"description=Version of the object to set the ACL" mgc:"hidden"`
+	common.ObjectVersion   `json:",squash"` // nolint
 	BucketOwnerRead        bool             `json:"bucket_owner_read,omitempty" mgc:"hidden"`
 	BucketOwnerFullControl bool             `json:"bucket_owner_full_control,omitempty" mgc:"hidden"`
 	common.ACLPermissions  `json:",squash"` // nolint
@@ -66,8 +66,8 @@ func newSetObjectAclRequest(ctx context.Context, p setObjectACLParams, cfg commo
 
 	query := url.Query()
 	query.Add("acl", "")
-	if p.Version != "" {
-		query.Add("versionId", p.Version)
+	if versionID := p.VersionID(); versionID != "" {
+		query.Add("versionId", versionID)
 	}
 
 	url.RawQuery = query.Encode()
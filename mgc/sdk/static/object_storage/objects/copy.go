@@ -14,18 +14,18 @@ var getCopy = utils.NewLazyLoader[core.Executor](func() core.Executor {
 	executor := core.NewStaticExecute(
 		core.DescriptorSpec{
 			Name:        "copy",
-			Description: "Copy an object from a bucket to another bucket",
+			Description: "Copy an object from a bucket to another bucket. Setting any content header or metadata replaces all the ones of the source object",
 		},
 		copy,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Copied from {{.src}} to {{.dst}}\n"
 	})
 })
 
 func copy(ctx context.Context, p common.CopyObjectParams, cfg common.Config) (result core.Value, err error) {
-	_, err = common.HeadFile(ctx, cfg, p.Source, p.Version)
+	_, err = common.HeadFile(ctx, cfg, p.Source, p.VersionID())
 	if err != nil {
 		return nil, fmt.Errorf("error validating source: %w", err)
 	}
@@ -45,7 +45,7 @@ func copy(ctx context.Context, p common.CopyObjectParams, cfg common.Config) (re
 		fullDstPath = fullDstPath.JoinPath(fileName)
 	}
 
-	copier, err := common.NewCopier(ctx, cfg, p.Source, fullDstPath, p.Version, p.StorageClass)
+	copier, err := common.NewCopier(ctx, cfg, p.Source, fullDstPath, p.VersionID(), p.StorageClass, p.ObjectMetadata)
 	if err != nil {
 		return nil, err
 	}
@@ -17,7 +17,7 @@ var getCopyAll = utils.NewLazyLoader[core.Executor](func() core.Executor {
 		copyAll,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Copied from {{.src}} to {{.dst}}\n"
 	})
 })
@@ -13,10 +13,11 @@ var getDelete = utils.NewLazyLoader[core.Executor](func() core.Executor {
 	var exec core.Executor = core.NewStaticExecute(
 		core.DescriptorSpec{
 			Name:        "delete",
-			Description: "Delete an object from a bucket",
+			Description: "Delete an object from a bucket. Deleting a version of the object removes it permanently",
 		},
 		deleteObject,
 	)
+	exec = core.NewDryRunExecutor(exec)
 	exec = core.NewExecuteFormat(exec, func(exec core.Executor, result core.Result) string {
 		return fmt.Sprintf("Deleted object %q", result.Source().Parameters["dst"])
 	})
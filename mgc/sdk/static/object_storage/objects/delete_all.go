@@ -18,7 +18,7 @@ var getDeleteAll = utils.NewLazyLoader[core.Executor](func() core.Executor {
 	)
 
 	return core.NewPromptInputExecutor(
-		exec,
+		core.NewDryRunExecutor(exec),
 		core.NewPromptInput(
 			`This command will delete all objects at {{.confirmationValue}}, and its result is NOT reversible.
 Please confirm by retyping: {{.confirmationValue}}`,
@@ -20,7 +20,7 @@ var getDownload = utils.NewLazyLoader[core.Executor](func() core.Executor {
 		download,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Downloaded from {{.src}} to {{.dst}}\n"
 	})
 })
@@ -35,7 +35,7 @@ func download(ctx context.Context, p common.DownloadObjectParams, cfg common.Con
 		return nil, fmt.Errorf("no destination specified and could not use local dir: %w", err)
 	}
 
-	downloader, err := common.NewDownloader(ctx, cfg, p.Source, dst, p.Version)
+	downloader, err := common.NewDownloader(ctx, cfg, p.Source, dst, p.VersionID())
 	if err != nil {
 		return nil, err
 	}
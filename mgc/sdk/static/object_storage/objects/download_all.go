@@ -38,7 +38,7 @@ var getDownloadAll = utils.NewLazyLoader[core.Executor](func() core.Executor {
 		downloadAll,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Downloaded from {{.src}} to {{.dst}}\n"
 	})
 })
@@ -111,7 +111,7 @@ func downloadMultipleFiles(ctx context.Context, cfg common.Config, params downlo
 	objs := common.ListGenerator(ctx, listParams, cfg, onNewPage)
 	objs = common.ApplyFilters(ctx, objs, params.FilterParams, cancel)
 
-	downloadObjectsErrorChan := pipeline.ParallelProcess(ctx, cfg.Workers, objs, createObjectDownloadProcessor(cfg, params, progressReporter), nil)
+	downloadObjectsErrorChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "download objects"), cfg.Workers, objs, createObjectDownloadProcessor(cfg, params, progressReporter), nil)
 	downloadObjectsErrorChan = pipeline.Filter(ctx, downloadObjectsErrorChan, pipeline.FilterNonNil[error]{})
 
 	objErr, err := pipeline.SliceItemConsumer[utils.MultiError](ctx, downloadObjectsErrorChan)
@@ -5,6 +5,8 @@ import (
 	"github.com/MagaluCloud/magalu/mgc/core/utils"
 	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/objects/acl"
 	object_lock "github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/objects/object-lock"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/objects/tags"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/objects/uploads"
 )
 
 var GetGroup = utils.NewLazyLoader[core.Grouper](func() core.Grouper {
@@ -28,10 +30,14 @@ var GetGroup = utils.NewLazyLoader[core.Grouper](func() core.Grouper {
 				getMove(),              // object-storage objects move
 				object_lock.GetGroup(), // object-storage objects object-lock
 				getSync(),              // object-storage objects sync
+				tags.GetGroup(),        // object-storage objects tags
 				getUpload(),            // object-storage objects upload
 				getUploadDir(),         // object-storage objects upload-dir
+				uploads.GetGroup(),     // object-storage objects uploads
 				getPresign(),           // object-storage objects presigned
+				getRestoreVersion(),    // object-storage objects restore-version
 				getPublicUrl(),         // object-storage objects public-url
+				getVerify(),            // object-storage objects verify
 				getVersions(),          // object-storage objects versions
 			}
 		},
@@ -10,8 +10,8 @@ import (
 )
 
 type headObjectParams struct {
-	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to be get metadata from,example=bucket1/file.txt" mgc:"positional"`
-	Version     string           `json:"objVersion,omitempty" jsonschema:"description=Version of the object to be get metadata from"`
+	Destination          mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to be get metadata from,example=bucket1/file.txt" mgc:"positional"`
+	common.ObjectVersion `json:",squash"` // nolint
 }
 
 var getHead = utils.NewLazyLoader[core.Executor](func() core.Executor {
@@ -29,5 +29,5 @@ var getHead = utils.NewLazyLoader[core.Executor](func() core.Executor {
 })
 
 func headObject(ctx context.Context, p headObjectParams, cfg common.Config) (common.HeadObjectResponse, error) {
-	return common.HeadFile(ctx, cfg, p.Destination, p.Version)
+	return common.HeadFile(ctx, cfg, p.Destination, p.VersionID())
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io/fs"
-	"os"
 	"path/filepath"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
@@ -30,7 +29,7 @@ var getMoveDir = utils.NewLazyLoader[core.Executor](func() core.Executor {
 		moveDir,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Moved from {{.src}} to {{.dst}}\n"
 	})
 })
@@ -85,7 +84,7 @@ func createObjectLocalRemoteMoveDirProcessor(cfg common.Config, destination mgcS
 			return &common.ObjectError{Url: mgcSchemaPkg.URI(objURI), Err: err}, pipeline.ProcessOutput
 		}
 
-		err = os.Remove(absEntry)
+		err = common.RemoveLocalFile(ctx, absEntry)
 		if err != nil {
 			return &common.ObjectError{Url: mgcSchemaPkg.URI(objURI), Err: err}, pipeline.ProcessOutput
 		}
@@ -132,7 +131,7 @@ func moveDirLocalRemote(ctx context.Context, params moveDirParams, cfg common.Co
 	}
 	srcDir := filepath.Dir(srcAbs)
 
-	uploadObjectsErrorChan := pipeline.ParallelProcess(ctx, cfg.Workers, srcObjects, createObjectLocalRemoteMoveDirProcessor(cfg, params.Destination, srcDir), nil)
+	uploadObjectsErrorChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "move objects"), cfg.Workers, srcObjects, createObjectLocalRemoteMoveDirProcessor(cfg, params.Destination, srcDir), nil)
 	uploadObjectsErrorChan = pipeline.Filter(ctx, uploadObjectsErrorChan, pipeline.FilterNonNil[error]{})
 
 	objErr, _ := pipeline.SliceItemConsumer[utils.MultiError](ctx, uploadObjectsErrorChan)
@@ -3,7 +3,6 @@ package objects
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
@@ -28,7 +27,7 @@ It can be either local or remote but not both local (Local -> Remote, Remote ->
 		move,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Moved from {{.src}} to {{.dst}}\n"
 	})
 })
@@ -78,7 +77,7 @@ func moveLocalRemote(ctx context.Context, params moveParams, cfg common.Config)
 		return params, err
 	}
 
-	err = os.Remove(srcAbs)
+	err = common.RemoveLocalFile(ctx, srcAbs)
 	if err != nil {
 		return params, err
 	}
@@ -0,0 +1,51 @@
+package objects
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type restoreVersionParams struct {
+	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to be restored,example=bucket1/file.txt" mgc:"positional"`
+	Version     string           `json:"version_id" jsonschema:"description=ID of the version to restore. See 'objects versions'"`
+}
+
+var getRestoreVersion = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	executor := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "restore-version",
+			Summary:     "Restore a previous version of an object",
+			Description: "Restore a previous version of an object by copying it over the current one. The bucket must have versioning enabled, and the current version is kept as a noncurrent one",
+		},
+		restoreVersion,
+	)
+
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
+		return "template=Restored {{.dst}} to version {{.version_id}}\n"
+	})
+})
+
+func restoreVersion(ctx context.Context, p restoreVersionParams, cfg common.Config) (result restoreVersionParams, err error) {
+	if p.Destination.Filename() == "" {
+		return result, core.UsageError{Err: fmt.Errorf("destination must be a URI to an object")}
+	}
+	if p.Version == "" {
+		return result, core.UsageError{Err: fmt.Errorf("version_id cannot be empty")}
+	}
+
+	copier, err := common.NewCopier(ctx, cfg, p.Destination, p.Destination, p.Version, "", common.ObjectMetadata{})
+	if err != nil {
+		return result, fmt.Errorf("error validating version %q: %w", p.Version, err)
+	}
+
+	if err = copier.Copy(ctx); err != nil {
+		return result, err
+	}
+
+	return p, nil
+}
@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	sy "sync"
-	"time"
+	"sync/atomic"
 
 	"github.com/MagaluCloud/magalu/mgc/core"
 	"github.com/MagaluCloud/magalu/mgc/core/pipeline"
@@ -17,304 +16,261 @@ import (
 	"github.com/pterm/pterm"
 )
 
-type UploadCounter struct {
-	mu sy.Mutex
-	v  uint64
-}
-
-type fileSyncStats struct {
-	SourceLength  int64
-	SourceModTime int64
-	Etag          string
-}
-
 type syncParams struct {
-	Local     mgcSchemaPkg.URI `json:"local" jsonschema:"description=Local path,example=./" mgc:"positional"`
-	Bucket    mgcSchemaPkg.URI `json:"bucket" jsonschema:"description=Bucket path,example=my-bucket/dir/" mgc:"positional"`
-	Delete    bool             `json:"delete,omitempty" jsonschema:"description=Deletes any item at the bucket not present on the local,default=false"`
-	BatchSize int              `json:"batch_size,omitempty" jsonschema:"description=Limit of items per batch to delete,default=1000,minimum=1,maximum=1000" example:"1000"`
+	Source                mgcSchemaPkg.URI `json:"src,omitempty" jsonschema:"description=Source path to sync from. Either a local path or a bucket path prefixed with s3://,example=./" mgc:"positional"`
+	Destination           mgcSchemaPkg.URI `json:"dst,omitempty" jsonschema:"description=Destination path to sync to. Either a local path or a bucket path prefixed with s3://. If the source is local\\, it's always a bucket path,example=my-bucket/dir/" mgc:"positional"`
+	Local                 mgcSchemaPkg.URI `json:"local,omitempty" jsonschema:"description=Deprecated: use src" mgc:"hidden"`
+	Bucket                mgcSchemaPkg.URI `json:"bucket,omitempty" jsonschema:"description=Deprecated: use dst" mgc:"hidden"`
+	Delete                bool             `json:"delete,omitempty" jsonschema:"description=Deletes any item at the destination not present on the source,default=false"`
+	BatchSize             int              `json:"batch_size,omitempty" jsonschema:"description=Limit of items per batch to delete,default=1000,minimum=1,maximum=1000" example:"1000"`
+	Checksum              bool             `json:"checksum,omitempty" jsonschema:"description=Compare files by their content checksum (ETag/MD5) instead of size and modification time,default=false"`
+	common.Filters        `json:",squash"` // nolint
+	common.ObjectMetadata `json:",squash"` // nolint
 }
 
 type syncResult struct {
-	Source        mgcSchemaPkg.URI `json:"src" jsonschema:"description=Source path to sync the remote with,example=./" mgc:"positional"`
-	Destination   mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Full destination path to sync with the source path,example=s3://my-bucket/dir/" mgc:"positional"`
-	FilesDeleted  int              `json:"deleted"`
-	FilesUploaded int              `json:"uploaded"`
-	Deleted       bool             `json:"hasDeleted"`
-	DeletedFiles  string           `json:"deletedFiles"`
+	Source          mgcSchemaPkg.URI `json:"src" jsonschema:"description=Source path to sync the remote with,example=./" mgc:"positional"`
+	Destination     mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Full destination path to sync with the source path,example=s3://my-bucket/dir/" mgc:"positional"`
+	FilesDeleted    int              `json:"deleted"`
+	FilesUploaded   int              `json:"uploaded"`
+	FilesDownloaded int              `json:"downloaded"`
+	FilesCopied     int              `json:"copied"`
+	Deleted         bool             `json:"hasDeleted"`
+	DeletedFiles    string           `json:"deletedFiles"`
 }
 
 var getSync = utils.NewLazyLoader[core.Executor](func() core.Executor {
 	executor := core.NewStaticExecute(
 		core.DescriptorSpec{
-			Name:        "sync",
-			Summary:     "Synchronizes a local path with a bucket",
-			Description: "This command uploads any file from the local path to the bucket if it is not already present or has modified time changed.",
+			Name:    "sync",
+			Summary: "Synchronizes a source path with a destination path",
+			Description: `This command transfers any file from the source to the destination if it is not already present or has changed.
+The source and destination may be a local path or a bucket path (prefixed with s3://), allowing local to bucket,
+bucket to local and bucket to bucket synchronization. Use --cli.dry-run to only show the transfers and deletions
+that would be done.`,
 		},
 		sync,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
-		return "template={{if and (eq .deleted 0) (eq .uploaded 0)}}Already Synced{{- else}}" +
-			"Synced files from {{.src}} to {{.dst}}\n- {{.uploaded}} files uploaded\n- {{if .hasDeleted}}{{.deleted}} files deleted\n\nDeleted files:\n-{{.deletedFiles}}{{- else}}{{.deleted}} files to be deleted with the --delete parameter{{- end}}{{- end}}\n"
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
+		return "template={{if and (eq .deleted 0) (eq .uploaded 0) (eq .downloaded 0) (eq .copied 0)}}Already Synced{{- else}}" +
+			"Synced files from {{.src}} to {{.dst}}\n{{if .uploaded}}- {{.uploaded}} files uploaded\n{{end}}{{if .downloaded}}- {{.downloaded}} files downloaded\n{{end}}{{if .copied}}- {{.copied}} files copied\n{{end}}" +
+			"- {{if .hasDeleted}}{{.deleted}} files deleted\n\nDeleted files:\n-{{.deletedFiles}}{{- else}}{{.deleted}} files to be deleted with the --delete parameter{{- end}}{{- end}}\n"
 	})
 })
 
-var (
-	allBucketFiles = make(map[string]bool)
-	uploadFiles    = &UploadCounter{}
-)
+// A local source can only be synced to a bucket, so its destination is a bucket
+// even without the s3:// prefix
+func newSyncEndpoints(params syncParams) (src syncEndpoint, dst syncEndpoint, err error) {
+	// 'local' and 'bucket' were the names before syncing in any direction was supported
+	if params.Source == "" {
+		params.Source = params.Local
+	}
+	if params.Destination == "" {
+		params.Destination = params.Bucket
+	}
+	if params.Source == "" || params.Destination == "" {
+		err = core.UsageError{Err: fmt.Errorf("both src and dst paths are required")}
+		return
+	}
 
-func sync(ctx context.Context, params syncParams, cfg common.Config) (result core.Value, err error) {
-	if !strings.HasPrefix(string(params.Bucket), common.URIPrefix) {
+	src = syncEndpoint{URI: params.Source, Remote: strings.HasPrefix(params.Source.String(), common.URIPrefix)}
+	dst = syncEndpoint{URI: params.Destination, Remote: strings.HasPrefix(params.Destination.String(), common.URIPrefix)}
+
+	if !src.Remote && !dst.Remote {
 		logger().Debugw("Bucket path missing prefix, adding prefix")
-		params.Bucket = common.URIPrefix + params.Bucket
+		dst.URI = common.URIPrefix + dst.URI
+		dst.Remote = true
 	}
 
-	if strings.HasPrefix(string(params.Local), common.URIPrefix) {
-		return nil, fmt.Errorf("local cannot be an bucket! To copy or move between buckets, use \"mgc object-storage objects copy/move\"")
+	for _, endpoint := range []*syncEndpoint{&src, &dst} {
+		if endpoint.Remote {
+			continue
+		}
+		endpoint.URI, err = common.GetAbsSystemURI(endpoint.URI)
+		if err != nil {
+			return
+		}
 	}
 
-	ctx, cancel := context.WithCancelCause(ctx)
-	defer cancel(nil)
+	if !src.Remote {
+		if f, statErr := os.Stat(src.URI.String()); statErr != nil || !f.IsDir() {
+			err = core.UsageError{Err: fmt.Errorf("local path must be a folder")}
+		}
+	}
+	return
+}
 
-	basePath, err := common.GetAbsSystemURI(params.Local)
+func sync(ctx context.Context, params syncParams, cfg common.Config) (result core.Value, err error) {
+	src, dst, err := newSyncEndpoints(params)
 	if err != nil {
 		return nil, err
 	}
 
-	if f, _ := os.Stat(basePath.String()); !f.IsDir() {
-		return nil, fmt.Errorf("local path must be a folder")
+	srcEntries, err := listSyncEntries(ctx, src, params.FilterParams, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	files, err := walkDir(ctx, basePath.String(), false)
+	dstEntries := map[string]syncEntry{}
+	if dst.Remote {
+		dstEntries, err = listSyncEntries(ctx, dst, params.FilterParams, cfg)
+	} else if _, statErr := os.Stat(dst.URI.String()); statErr == nil {
+		dstEntries, err = listSyncEntries(ctx, dst, params.FilterParams, cfg)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	totalFiles := len(files)
-	progressBar := pterm.DefaultProgressbar.
-		WithTotal(totalFiles).
-		WithTitle("Syncing files").
-		WithRemoveWhenDone(true)
-
-	if !openapi.GetRawOutputFlag(ctx) {
-		progressBar, _ = progressBar.Start()
+	transfers, deletions, err := planSync(ctx, src, dst, srcEntries, dstEntries, params.Checksum, cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	fillBucketFiles(ctx, params, cfg)
-
-	err = processSyncFiles(ctx, cfg, params.Local, params.Bucket, basePath.String(), files, progressBar)
+	res := syncResult{
+		Source:       src.URI,
+		Destination:  dst.URI,
+		FilesDeleted: len(deletions),
+	}
 
+	counters, err := executeSyncTransfers(ctx, cfg, transfers, params.ObjectMetadata)
 	if err != nil {
 		return nil, err
 	}
+	res.FilesUploaded = int(counters[syncActionUpload].Load())
+	res.FilesDownloaded = int(counters[syncActionDownload].Load())
+	res.FilesCopied = int(counters[syncActionCopy].Load())
 
-	_, _ = progressBar.Stop()
-
-	deletedFiles := make([]string, 0, len(allBucketFiles))
-
-	if params.Delete {
-		for file := range allBucketFiles {
-			if err != nil {
-				logger().Debugw("error deleting file", "error", err)
-			}
-			deletedFiles = append(deletedFiles, file)
+	if params.Delete && len(deletions) > 0 {
+		if err = executeSyncDeletions(ctx, cfg, dst, deletions, params.BatchSize); err != nil {
+			return nil, err
 		}
-		delOb := common.DeleteObjectsParams{
-			Destination: params.Bucket,
-			ToDelete:    bucketObjectsToWalkDirEntry(ctx, deletedFiles),
-			BatchSize:   params.BatchSize,
-		}
-		err = common.DeleteObjects(ctx, delOb, cfg)
-		if err != nil {
-			logger().Debugw("error deleting objects", "error", err)
+
+		deletedFiles := make([]string, 0, len(deletions))
+		for _, action := range deletions {
+			deletedFiles = append(deletedFiles, action.Destination)
 		}
+		res.Deleted = true
+		res.DeletedFiles = strings.Join(deletedFiles, ", ")
 	}
 
-	return syncResult{
-		Source:        params.Local,
-		Destination:   params.Bucket,
-		FilesDeleted:  len(allBucketFiles),
-		FilesUploaded: int(uploadFiles.Value()),
-		Deleted:       len(deletedFiles) > 0,
-		DeletedFiles:  strings.Join(deletedFiles, ", "),
-	}, nil
+	return res, nil
 }
 
-func bucketObjectsToWalkDirEntry(ctx context.Context, bucketObjects []string) <-chan pipeline.WalkDirEntry {
-	out := make(chan pipeline.WalkDirEntry)
-	go func() {
-		defer close(out)
+func createSyncTransferProcessor(
+	cfg common.Config,
+	metadata common.ObjectMetadata,
+	counters map[syncActionType]*atomic.Int64,
+	progressBar *pterm.ProgressbarPrinter,
+) pipeline.Processor[syncAction, error] {
+	return func(ctx context.Context, action syncAction) (error, pipeline.ProcessStatus) {
 		var err error
-		for _, obj := range bucketObjects {
-			if ctx.Err() != nil {
-				return
-			}
-			entry := pipeline.NewSimpleWalkDirEntry(obj, &common.BucketContent{
-				Key: strings.TrimPrefix(obj, "/"),
-			}, err)
-			out <- entry
+		switch action.Action {
+		case syncActionUpload:
+			_, err = upload(
+				ctx,
+				uploadParams{Source: mgcSchemaPkg.FilePath(action.Source), Destination: mgcSchemaPkg.URI(action.Destination), ObjectMetadata: metadata},
+				cfg,
+			)
+		case syncActionDownload:
+			err = downloadSyncObject(ctx, cfg, action)
+		case syncActionCopy:
+			err = copySyncObject(ctx, cfg, action, metadata)
 		}
-	}()
-	return out
-}
 
-func fillBucketFiles(ctx context.Context, params syncParams, cfg common.Config) {
-	logger().Debug("Getting bucket files")
-
-	dirBucketFiles := common.ListGenerator(ctx, common.ListObjectsParams{
-		Destination: params.Bucket,
-		Recursive:   true,
-		PaginationParams: common.PaginationParams{
-			MaxItems: common.MaxBatchSize,
-		},
-	}, cfg, nil)
+		if err != nil {
+			return &common.ObjectError{Url: mgcSchemaPkg.URI(action.Source), Err: err}, pipeline.ProcessOutput
+		}
 
-	for file := range dirBucketFiles {
-		allBucketFiles["/"+file.Path()] = true
+		counters[action.Action].Add(1)
+		progressBar.Increment()
+		return nil, pipeline.ProcessOutput
 	}
 }
 
-func getFileStats(ctx context.Context, destination mgcSchemaPkg.URI, cfg common.Config) (fileSyncStats, error) {
-	dstHead, err := headObject(ctx, headObjectParams{
-		Destination: destination,
-	}, cfg)
+func downloadSyncObject(ctx context.Context, cfg common.Config, action syncAction) error {
+	downloader, err := common.NewDownloader(ctx, cfg, mgcSchemaPkg.URI(action.Source), mgcSchemaPkg.FilePath(action.Destination), "")
 	if err != nil {
-		return fileSyncStats{}, err
+		return err
 	}
-	dstModTime, err := time.Parse(time.RFC1123, dstHead.LastModified)
+	return downloader.Download(ctx)
+}
+
+func copySyncObject(ctx context.Context, cfg common.Config, action syncAction, metadata common.ObjectMetadata) error {
+	copier, err := common.NewCopier(ctx, cfg, mgcSchemaPkg.URI(action.Source), mgcSchemaPkg.URI(action.Destination), "", "", metadata)
 	if err != nil {
-		logger().Debug("%s %s\n", dstModTime, err)
-		return fileSyncStats{}, err
+		return err
 	}
-	return fileSyncStats{
-		SourceLength:  dstHead.ContentLength,
-		SourceModTime: dstModTime.Unix(),
-		Etag:          cleanEtag(dstHead.ETag),
-	}, nil
+	return copier.Copy(ctx)
 }
 
-func cleanEtag(etag string) string {
-	return strings.Trim(etag, "\"")
-}
+func executeSyncTransfers(ctx context.Context, cfg common.Config, transfers []syncAction, metadata common.ObjectMetadata) (map[syncActionType]*atomic.Int64, error) {
+	counters := map[syncActionType]*atomic.Int64{
+		syncActionUpload:   {},
+		syncActionDownload: {},
+		syncActionCopy:     {},
+	}
+	if len(transfers) == 0 {
+		return counters, nil
+	}
 
-func uploadFile(ctx context.Context, local mgcSchemaPkg.URI, bucket mgcSchemaPkg.URI, cfg common.Config) error {
-	_, err := upload(
-		ctx,
-		uploadParams{Source: mgcSchemaPkg.FilePath(local), Destination: bucket},
-		cfg,
-	)
-	return err
-}
+	progressBar := pterm.DefaultProgressbar.
+		WithTotal(len(transfers)).
+		WithTitle("Syncing files").
+		WithRemoveWhenDone(true)
 
-func (c *UploadCounter) Increment() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.v++
-}
+	if !openapi.GetRawOutputFlag(ctx) {
+		progressBar, _ = progressBar.Start()
+	}
+	defer func() { _, _ = progressBar.Stop() }()
 
-func (c *UploadCounter) Value() uint64 {
-	return c.v
-}
+	transferErrChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "sync objects"), cfg.Workers, pipeline.SliceItemGenerator(ctx, transfers), createSyncTransferProcessor(cfg, metadata, counters, progressBar), nil)
+	transferErrChan = pipeline.Filter(ctx, transferErrChan, pipeline.FilterNonNil[error]{})
 
-func processSyncFiles(ctx context.Context, cfg common.Config, source, destination mgcSchemaPkg.URI, basePath string, files []string, progressBar *pterm.ProgressbarPrinter) error {
-	results := make(chan error, cfg.Workers)
-	filesChan := make(chan string, cfg.Workers)
-
-	var wg sy.WaitGroup
-	wg.Add(cfg.Workers)
-	for i := 0; i < cfg.Workers; i++ {
-		go func() {
-			defer wg.Done()
-			syncWorker(ctx, cfg, source, destination, basePath, filesChan, results, progressBar)
-		}()
+	objErr, err := pipeline.SliceItemConsumer[utils.MultiError](ctx, transferErrChan)
+	if err != nil {
+		return nil, err
+	}
+	if len(objErr) > 0 {
+		return nil, objErr
 	}
+	return counters, nil
+}
 
-	go func() {
-		defer close(filesChan)
-		for _, file := range files {
-			select {
-			case filesChan <- file:
-			case <-ctx.Done():
-				return
+func executeSyncDeletions(ctx context.Context, cfg common.Config, dst syncEndpoint, deletions []syncAction, batchSize int) error {
+	if !dst.Remote {
+		var errs utils.MultiError
+		for _, action := range deletions {
+			if err := common.RemoveLocalFile(ctx, action.Destination); err != nil {
+				errs = append(errs, err)
 			}
 		}
-	}()
-
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	for err := range results {
-		if err != nil {
-			return err
+		if len(errs) > 0 {
+			return errs
 		}
+		return nil
 	}
 
-	return nil
+	return common.DeleteObjects(ctx, common.DeleteObjectsParams{
+		Destination: dst.URI,
+		ToDelete:    bucketObjectsToWalkDirEntry(ctx, deletions),
+		BatchSize:   batchSize,
+	}, cfg)
 }
 
-func syncWorker(ctx context.Context, cfg common.Config, source, destination mgcSchemaPkg.URI, basePath string, files <-chan string, results chan<- error, progressBar *pterm.ProgressbarPrinter) {
-	for {
-		select {
-		case file, ok := <-files:
-			if !ok {
+func bucketObjectsToWalkDirEntry(ctx context.Context, deletions []syncAction) <-chan pipeline.WalkDirEntry {
+	out := make(chan pipeline.WalkDirEntry)
+	go func() {
+		defer close(out)
+		for _, action := range deletions {
+			key := mgcSchemaPkg.URI(action.Destination).Path()
+			entry := pipeline.NewSimpleWalkDirEntry(key, &common.BucketContent{Key: key}, nil)
+			select {
+			case <-ctx.Done():
 				return
+			case out <- entry:
 			}
-			err := processSyncFile(ctx, cfg, source, destination, basePath, file, progressBar)
-			if err != nil {
-				select {
-				case results <- err:
-				case <-ctx.Done():
-					return
-				}
-			}
-		case <-ctx.Done():
-			return
 		}
-	}
-}
-
-func processSyncFile(ctx context.Context, cfg common.Config, source, destination mgcSchemaPkg.URI, basePath, file string, progressBar *pterm.ProgressbarPrinter) error {
-	normalizedSource, err := common.GetAbsSystemURI(mgcSchemaPkg.URI(file))
-	if err != nil {
-		logger().Debugw("error with path", "error", err)
-		return nil
-	}
-
-	pathWithFolder := strings.TrimPrefix(file, basePath)
-	normalizedDestination := destination.JoinPath(pathWithFolder)
-
-	info, err := os.Stat(file)
-	if err != nil {
-		return err
-	}
-
-	if allBucketFiles[pathWithFolder] {
-		delete(allBucketFiles, pathWithFolder)
-	}
-
-	fileStats, err := getFileStats(ctx, normalizedDestination, cfg)
-	if err != nil {
-		logger().Debugw("error getting file stats", "error", err)
-	}
-
-	isSameSize := info.Size() == fileStats.SourceLength
-	isLocalOlderThenBucket := info.ModTime().Unix() < fileStats.SourceModTime
-	if err == nil && isSameSize && isLocalOlderThenBucket {
-		logger().Debug("Skipping file [%s] - no change", normalizedSource)
-		progressBar.Increment()
-		return nil
-	}
-
-	err = uploadFile(ctx, normalizedSource, normalizedDestination, cfg)
-	if err != nil {
-		return &common.ObjectError{Url: mgcSchemaPkg.URI(normalizedSource.Path()), Err: err}
-	}
-
-	uploadFiles.Increment()
-	progressBar.Increment()
-	return nil
+	}()
+	return out
 }
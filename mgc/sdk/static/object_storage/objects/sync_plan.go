@@ -0,0 +1,238 @@
+package objects
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/core/pipeline"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type syncActionType string
+
+const (
+	syncActionUpload   syncActionType = "upload"
+	syncActionDownload syncActionType = "download"
+	syncActionCopy     syncActionType = "copy"
+	syncActionDelete   syncActionType = "delete"
+)
+
+type syncAction struct {
+	Action      syncActionType `json:"action"`
+	Source      string         `json:"src,omitempty"`
+	Destination string         `json:"dst"`
+}
+
+// One side of the synchronization, either a local directory or a bucket path
+type syncEndpoint struct {
+	URI    mgcSchemaPkg.URI
+	Remote bool
+}
+
+func (e syncEndpoint) join(relPath string) string {
+	if e.Remote {
+		return e.URI.JoinPath(relPath).String()
+	}
+	return filepath.Join(e.URI.String(), filepath.FromSlash(relPath))
+}
+
+// syncEntry is a file or object relative to its syncEndpoint
+type syncEntry struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	ETag    string
+	// Set for objects encrypted on the client, whose Size is then of the plain content
+	Encrypted bool
+}
+
+func entryFromWalkDirEntry(root string, entry pipeline.WalkDirEntry) (result syncEntry, ok bool, err error) {
+	if err = entry.Err(); err != nil {
+		return
+	}
+
+	switch obj := entry.DirEntry().(type) {
+	case *common.BucketContent:
+		relPath := strings.TrimPrefix(strings.TrimPrefix(obj.Key, root), "/")
+		return syncEntry{Path: relPath, Size: obj.ContentSize, ModTime: obj.ModTime(), ETag: common.CleanETag(obj.ETag)}, true, nil
+	case *common.Prefix:
+		return
+	default:
+		if obj.IsDir() {
+			return
+		}
+		info, err := obj.Info()
+		if err != nil {
+			return result, false, err
+		}
+		relPath, err := filepath.Rel(root, entry.Path())
+		if err != nil {
+			return result, false, err
+		}
+		return syncEntry{Path: filepath.ToSlash(relPath), Size: info.Size(), ModTime: info.ModTime()}, true, nil
+	}
+}
+
+// listSyncEntries lists all files under the endpoint that pass the filters, keyed by their relative path
+func listSyncEntries(ctx context.Context, endpoint syncEndpoint, filters []common.FilterParams, cfg common.Config) (map[string]syncEntry, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var entries <-chan pipeline.WalkDirEntry
+	var root string
+	if endpoint.Remote {
+		root = endpoint.URI.Path()
+		entries = common.ListGenerator(ctx, common.ListObjectsParams{
+			Destination: endpoint.URI,
+			Recursive:   true,
+			PaginationParams: common.PaginationParams{
+				MaxItems: math.MaxInt64,
+			},
+		}, cfg, nil)
+	} else {
+		root = endpoint.URI.String()
+		entries = pipeline.WalkDirEntries(ctx, root, nil)
+	}
+	entries = common.ApplyFilters(ctx, entries, filters, cancel)
+
+	result := map[string]syncEntry{}
+	for walkEntry := range entries {
+		entry, ok, err := entryFromWalkDirEntry(root, walkEntry)
+		if err != nil {
+			return nil, &common.ObjectError{Url: mgcSchemaPkg.URI(walkEntry.Path()), Err: err}
+		}
+		if ok {
+			result[entry.Path] = entry
+		}
+	}
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolveEncryptedEntry reads the plain size of a remote object that may be the local file
+// encrypted on the client, as listings only have the size of the encrypted content
+func resolveEncryptedEntry(ctx context.Context, local syncEntry, remote syncEntry, remoteEndpoint syncEndpoint, cfg common.Config) (syncEntry, error) {
+	if cfg.EncryptKeyFile == "" || remote.Size == local.Size || remote.Size != common.EncryptedSize(local.Size) {
+		return remote, nil
+	}
+
+	head, err := common.HeadFile(ctx, cfg, mgcSchemaPkg.URI(remoteEndpoint.join(remote.Path)), "")
+	if err != nil {
+		return remote, err
+	}
+	if size, ok := head.PlainSize(); ok {
+		remote.Size = size
+		remote.Encrypted = true
+	}
+	return remote, nil
+}
+
+func isSyncEntryUpToDate(src syncEntry, dst syncEntry, srcEndpoint syncEndpoint, dstEndpoint syncEndpoint, checksum bool, cfg common.Config) (bool, error) {
+	if src.Size != dst.Size {
+		return false, nil
+	}
+
+	// The ETag of encrypted objects is of the encrypted content, so it can't be compared to the local file
+	if !checksum || src.Encrypted || dst.Encrypted {
+		return src.ModTime.Unix() < dst.ModTime.Unix(), nil
+	}
+
+	switch {
+	case srcEndpoint.Remote && dstEndpoint.Remote:
+		return src.ETag != "" && src.ETag == dst.ETag, nil
+	case srcEndpoint.Remote:
+		return common.LocalFileMatchesETag(dstEndpoint.join(dst.Path), src.ETag, cfg)
+	default:
+		return common.LocalFileMatchesETag(srcEndpoint.join(src.Path), dst.ETag, cfg)
+	}
+}
+
+func transferActionType(src syncEndpoint, dst syncEndpoint) syncActionType {
+	switch {
+	case src.Remote && dst.Remote:
+		return syncActionCopy
+	case src.Remote:
+		return syncActionDownload
+	default:
+		return syncActionUpload
+	}
+}
+
+// planSync compares both sides and returns the transfers needed to bring the destination
+// up to date, followed by the deletion of destination files not present in the source.
+// Comparisons may need to read whole files (checksum mode), so they're done by parallel workers.
+func planSync(
+	ctx context.Context,
+	src syncEndpoint,
+	dst syncEndpoint,
+	srcEntries map[string]syncEntry,
+	dstEntries map[string]syncEntry,
+	checksum bool,
+	cfg common.Config,
+) (transfers []syncAction, deletions []syncAction, err error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	actionType := transferActionType(src, dst)
+	compare := func(ctx context.Context, entry syncEntry) (action syncAction, status pipeline.ProcessStatus) {
+		if dstEntry, ok := dstEntries[entry.Path]; ok {
+			var err error
+			switch actionType {
+			case syncActionUpload:
+				dstEntry, err = resolveEncryptedEntry(ctx, entry, dstEntry, dst, cfg)
+			case syncActionDownload:
+				entry, err = resolveEncryptedEntry(ctx, dstEntry, entry, src, cfg)
+			}
+			if err != nil {
+				cancel(&common.ObjectError{Url: mgcSchemaPkg.URI(src.join(entry.Path)), Err: err})
+				return action, pipeline.ProcessAbort
+			}
+
+			upToDate, err := isSyncEntryUpToDate(entry, dstEntry, src, dst, checksum, cfg)
+			if err != nil {
+				cancel(&common.ObjectError{Url: mgcSchemaPkg.URI(src.join(entry.Path)), Err: err})
+				return action, pipeline.ProcessAbort
+			}
+			if upToDate {
+				logger().Debugw("skipping file, no change", "path", entry.Path)
+				return action, pipeline.ProcessSkip
+			}
+		}
+		return syncAction{Action: actionType, Source: src.join(entry.Path), Destination: dst.join(entry.Path)}, pipeline.ProcessOutput
+	}
+
+	srcSlice := make([]syncEntry, 0, len(srcEntries))
+	for _, entry := range srcEntries {
+		srcSlice = append(srcSlice, entry)
+	}
+
+	actionsChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "compare sync entries"), cfg.Workers, pipeline.SliceItemGenerator(ctx, srcSlice), compare, nil)
+	transfers, err = pipeline.SliceItemConsumer[[]syncAction](ctx, actionsChan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for relPath := range dstEntries {
+		if _, ok := srcEntries[relPath]; !ok {
+			deletions = append(deletions, syncAction{Action: syncActionDelete, Destination: dst.join(relPath)})
+		}
+	}
+
+	sortSyncActions(transfers)
+	sortSyncActions(deletions)
+	return transfers, deletions, nil
+}
+
+func sortSyncActions(actions []syncAction) {
+	slices.SortFunc(actions, func(a, b syncAction) int {
+		return strings.Compare(a.Destination, b.Destination)
+	})
+}
@@ -0,0 +1,85 @@
+package objects
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+func TestPlanSyncWithKeyFile(t *testing.T) {
+	cfg := common.Config{Workers: 2, EncryptKeyFile: "key"}
+	local := syncEndpoint{URI: "/tmp/dir"}
+	remote := syncEndpoint{URI: "s3://bucket/dir", Remote: true}
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	// Objects synced before the key file was set are plain and must not be transferred again
+	localEntries := map[string]syncEntry{
+		"plain.txt":   {Path: "plain.txt", Size: 10, ModTime: older},
+		"changed.txt": {Path: "changed.txt", Size: 10, ModTime: older},
+	}
+	remoteEntries := map[string]syncEntry{
+		"plain.txt":   {Path: "plain.txt", Size: 10, ModTime: newer},
+		"changed.txt": {Path: "changed.txt", Size: 11, ModTime: newer},
+	}
+
+	transfers, _, err := planSync(context.Background(), local, remote, localEntries, remoteEntries, false, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transfers) != 1 || transfers[0].Destination != "s3://bucket/dir/changed.txt" {
+		t.Errorf("expected only changed.txt to be uploaded, got %v", transfers)
+	}
+}
+
+func TestIsSyncEntryUpToDateEncrypted(t *testing.T) {
+	local := syncEndpoint{URI: "/tmp/dir"}
+	remote := syncEndpoint{URI: "s3://bucket/dir", Remote: true}
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	file := syncEntry{Path: "file.txt", Size: 10, ModTime: older}
+	object := syncEntry{Path: "file.txt", Size: 10, ModTime: newer, ETag: "not-the-md5-of-the-file", Encrypted: true}
+
+	// The file doesn't exist, so checksum mode must not try to read it for encrypted objects
+	for _, checksum := range []bool{false, true} {
+		upToDate, err := isSyncEntryUpToDate(file, object, local, remote, checksum, common.Config{})
+		if err != nil || !upToDate {
+			t.Errorf("checksum=%v: expected upload to be up to date, got %v (err %v)", checksum, upToDate, err)
+		}
+
+		upToDate, err = isSyncEntryUpToDate(object, syncEntry{Path: "file.txt", Size: 10, ModTime: older}, remote, local, checksum, common.Config{})
+		if err != nil || upToDate {
+			t.Errorf("checksum=%v: expected older local file to be downloaded, got %v (err %v)", checksum, upToDate, err)
+		}
+	}
+}
+
+func TestResolveEncryptedEntry(t *testing.T) {
+	remote := syncEndpoint{URI: "s3://bucket/dir", Remote: true}
+	file := syncEntry{Path: "file.txt", Size: 10}
+	object := syncEntry{Path: "file.txt", Size: common.EncryptedSize(10)}
+
+	// Without a key file, or with sizes that can't be of the encrypted file, there's no request
+	for _, tc := range []struct {
+		name   string
+		cfg    common.Config
+		object syncEntry
+	}{
+		{name: "no key file", cfg: common.Config{}, object: object},
+		{name: "same size", cfg: common.Config{EncryptKeyFile: "key"}, object: syncEntry{Path: "file.txt", Size: 10}},
+		{name: "other size", cfg: common.Config{EncryptKeyFile: "key"}, object: syncEntry{Path: "file.txt", Size: 42}},
+	} {
+		resolved, err := resolveEncryptedEntry(context.Background(), file, tc.object, remote, tc.cfg)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if resolved != tc.object {
+			t.Errorf("%s: expected entry to be unchanged, got %+v", tc.name, resolved)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package objects
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+)
+
+func TestNewSyncEndpointsDeprecatedNames(t *testing.T) {
+	dir := t.TempDir()
+
+	src, dst, err := newSyncEndpoints(syncParams{Local: "s3://bucket/dir", Bucket: "s3://other/dir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.URI != "s3://bucket/dir" || dst.URI != "s3://other/dir" {
+		t.Errorf("expected 'local' and 'bucket' to be used as src and dst, got %s %s", src.URI, dst.URI)
+	}
+
+	// The new names win when both are given
+	src, dst, err = newSyncEndpoints(syncParams{Source: "s3://bucket/new", Destination: "s3://other/new", Local: "s3://bucket/dir", Bucket: "s3://other/dir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.URI != "s3://bucket/new" || dst.URI != "s3://other/new" {
+		t.Errorf("expected src and dst to be used, got %s %s", src.URI, dst.URI)
+	}
+
+	_, _, err = newSyncEndpoints(syncParams{Local: "s3://bucket/dir"})
+	if !errors.As(err, &core.UsageError{}) {
+		t.Errorf("expected usage error without a destination, got %v", err)
+	}
+
+	_, dst, err = newSyncEndpoints(syncParams{Local: mgcSchemaPkg.URI(dir), Bucket: "bucket/dir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.URI != "s3://bucket/dir" || !dst.Remote {
+		t.Errorf("expected local source to be synced to a bucket, got %s", dst.URI)
+	}
+}
@@ -0,0 +1,48 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type deleteObjectTagsParams struct {
+	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to delete the tags from,example=my-bucket/file.txt" mgc:"positional"`
+	Version     string           `json:"version_id,omitempty" jsonschema:"description=ID of the version of the object"`
+}
+
+var getDelete = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "delete",
+			Description: "Delete all tags of the specified object",
+		},
+		deleteTags,
+	)
+
+	exec = core.NewExecuteFormat(exec, func(exec core.Executor, result core.Result) string {
+		return fmt.Sprintf("Successfully deleted tags of object %q", result.Source().Parameters["dst"])
+	})
+
+	return exec
+})
+
+func deleteTags(ctx context.Context, p deleteObjectTagsParams, cfg common.Config) (result core.Value, err error) {
+	req, err := newObjectTaggingRequest(ctx, cfg, http.MethodDelete, p.Destination, p.Version)
+	if err != nil {
+		return
+	}
+
+	resp, err := common.SendRequest(ctx, req, cfg)
+	if err != nil {
+		return
+	}
+
+	err = common.ExtractErr(resp, req)
+	return
+}
@@ -0,0 +1,66 @@
+package tags
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type getObjectTagsParams struct {
+	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the object to get the tags from,example=my-bucket/file.txt" mgc:"positional"`
+	Version     string           `json:"version_id,omitempty" jsonschema:"description=ID of the version of the object"`
+}
+
+var getGet = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "get",
+			Description: "Get the tags of the specified object",
+		},
+		getTags,
+	)
+	exec = core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "json"
+	})
+	return exec
+})
+
+func getTags(ctx context.Context, p getObjectTagsParams, cfg common.Config) (result map[string]string, err error) {
+	req, err := newObjectTaggingRequest(ctx, cfg, http.MethodGet, p.Destination, p.Version)
+	if err != nil {
+		return
+	}
+
+	resp, err := common.SendRequest(ctx, req, cfg)
+	if err != nil {
+		return
+	}
+
+	tagging, err := common.UnwrapResponse[tagging](resp, req)
+	if err != nil {
+		return
+	}
+
+	return tagging.toMap(), nil
+}
+
+func newObjectTaggingRequest(ctx context.Context, cfg common.Config, method string, dst mgcSchemaPkg.URI, version string) (*http.Request, error) {
+	bucketName := common.NewBucketNameFromURI(dst)
+	url, err := common.BuildBucketHostWithPathURL(cfg, bucketName, dst.Path())
+	if err != nil {
+		return nil, core.UsageError{Err: err}
+	}
+
+	q := url.Query()
+	q.Add("tagging", "")
+	if version != "" {
+		q.Set("versionId", version)
+	}
+	url.RawQuery = q.Encode()
+
+	return http.NewRequestWithContext(ctx, method, url.String(), nil)
+}
@@ -0,0 +1,83 @@
+package tags
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+	"github.com/invopop/jsonschema"
+)
+
+type setObjectTagsParams struct {
+	Destination mgcSchemaPkg.URI  `json:"dst" jsonschema:"description=Path of the object to set the tags for,example=my-bucket/file.txt" mgc:"positional"`
+	Tags        map[string]string `json:"tags" jsonschema:"description=Tags of the object\\, replacing the existing ones,example={\"env\": \"prod\"}"`
+	Version     string            `json:"version_id,omitempty" jsonschema:"description=ID of the version of the object"`
+}
+
+func (p setObjectTagsParams) JSONSchemaExtend(s *jsonschema.Schema) {
+	if prop, exists := s.Properties.Get("tags"); exists {
+		prop.Type = "object"
+		prop.AdditionalProperties = &jsonschema.Schema{Type: "string"}
+	}
+}
+
+var getSet = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "set",
+			Description: "Set the tags of the specified object, replacing the existing ones",
+		},
+		setTags,
+	)
+
+	exec = core.NewExecuteFormat(exec, func(exec core.Executor, result core.Result) string {
+		return fmt.Sprintf("Successfully set tags for object %q", result.Source().Parameters["dst"])
+	})
+
+	return exec
+})
+
+func setTags(ctx context.Context, p setObjectTagsParams, cfg common.Config) (result core.Value, err error) {
+	req, err := newSetObjectTagsRequest(ctx, p, cfg)
+	if err != nil {
+		return
+	}
+
+	resp, err := common.SendRequest(ctx, req, cfg)
+	if err != nil {
+		return
+	}
+
+	err = common.ExtractErr(resp, req)
+	return
+}
+
+func newSetObjectTagsRequest(ctx context.Context, p setObjectTagsParams, cfg common.Config) (*http.Request, error) {
+	req, err := newObjectTaggingRequest(ctx, cfg, http.MethodPut, p.Destination, p.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := xml.Marshal(newTagging(p.Tags))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to XML: %w", err)
+	}
+
+	getBody := func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	req.Header.Set("Content-Type", "application/xml")
+	req.Body, _ = getBody()
+	req.GetBody = getBody
+	req.ContentLength = int64(len(body))
+
+	return req, nil
+}
@@ -0,0 +1,34 @@
+package tags
+
+import (
+	"encoding/xml"
+	"maps"
+	"slices"
+)
+
+type tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	XMLns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []tag    `xml:"TagSet>Tag"`
+}
+
+type tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+func newTagging(tags map[string]string) tagging {
+	result := tagging{XMLns: "http://s3.amazonaws.com/doc/2006-03-01/"}
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		result.TagSet = append(result.TagSet, tag{Key: k, Value: tags[k]})
+	}
+	return result
+}
+
+func (t tagging) toMap() map[string]string {
+	result := make(map[string]string, len(t.TagSet))
+	for _, tag := range t.TagSet {
+		result[tag.Key] = tag.Value
+	}
+	return result
+}
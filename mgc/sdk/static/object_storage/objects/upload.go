@@ -12,9 +12,10 @@ import (
 )
 
 type uploadParams struct {
-	Source       mgcSchemaPkg.FilePath `json:"src" jsonschema:"description=Source file path to be uploaded,example=./file.txt" mgc:"positional"`
-	Destination  mgcSchemaPkg.URI      `json:"dst" jsonschema:"description=Full destination path in the bucket with desired filename,example=my-bucket/dir/file.txt" mgc:"positional"`
-	StorageClass string                `json:"storage_class,omitempty" jsonschema:"description=Type of Storage in which to store object,example=cold,enum=,enum=standard,enum=cold,enum=glacier_ir,enum=cold_instant,default="`
+	Source                mgcSchemaPkg.FilePath `json:"src" jsonschema:"description=Source file path to be uploaded,example=./file.txt" mgc:"positional"`
+	Destination           mgcSchemaPkg.URI      `json:"dst" jsonschema:"description=Full destination path in the bucket with desired filename,example=my-bucket/dir/file.txt" mgc:"positional"`
+	StorageClass          string                `json:"storage_class,omitempty" jsonschema:"description=Type of Storage in which to store object,example=cold,enum=,enum=standard,enum=cold,enum=glacier_ir,enum=cold_instant,default="`
+	common.ObjectMetadata `json:",squash"`      // nolint
 }
 
 type uploadTemplateResult struct {
@@ -31,7 +32,7 @@ var getUpload = utils.NewLazyLoader[core.Executor](func() core.Executor {
 		upload,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Uploaded file {{.file}} to {{.uri}}\n"
 	})
 })
@@ -49,7 +50,7 @@ func upload(ctx context.Context, params uploadParams, cfg common.Config) (*uploa
 		fullDstPath = fullDstPath.JoinPath(fileName)
 	}
 
-	uploader, err := common.NewUploader(cfg, params.Source, fullDstPath, params.StorageClass)
+	uploader, err := common.NewUploader(ctx, cfg, params.Source, fullDstPath, params.StorageClass, params.ObjectMetadata)
 	if err != nil {
 		return nil, err
 	}
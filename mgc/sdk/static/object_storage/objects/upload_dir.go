@@ -16,11 +16,12 @@ import (
 )
 
 type uploadDirParams struct {
-	Source         mgcSchemaPkg.DirPath `json:"src" jsonschema:"description=Source directory path for upload,example=path/to/folder" mgc:"positional"`
-	Destination    mgcSchemaPkg.URI     `json:"dst" jsonschema:"description=Full destination path in the bucket,example=my-bucket/dir/" mgc:"positional"`
-	Shallow        bool                 `json:"shallow,omitempty" jsonschema:"description=Don't upload subdirectories,default=false"`
-	StorageClass   string               `json:"storage_class,omitempty" jsonschema:"description=Type of Storage in which to store object,example=cold,enum=,enum=standard,enum=cold,enum=glacier_ir,enum=cold_instant,default="`
-	common.Filters `json:",squash"`     // nolint
+	Source                mgcSchemaPkg.DirPath `json:"src" jsonschema:"description=Source directory path for upload,example=path/to/folder" mgc:"positional"`
+	Destination           mgcSchemaPkg.URI     `json:"dst" jsonschema:"description=Full destination path in the bucket,example=my-bucket/dir/" mgc:"positional"`
+	Shallow               bool                 `json:"shallow,omitempty" jsonschema:"description=Don't upload subdirectories,default=false"`
+	StorageClass          string               `json:"storage_class,omitempty" jsonschema:"description=Type of Storage in which to store object,example=cold,enum=,enum=standard,enum=cold,enum=glacier_ir,enum=cold_instant,default="`
+	common.Filters        `json:",squash"`     // nolint
+	common.ObjectMetadata `json:",squash"`     // nolint
 }
 
 type uploadDirResult struct {
@@ -37,7 +38,7 @@ var getUploadDir = utils.NewLazyLoader[core.Executor](func() core.Executor {
 		uploadDir,
 	)
 
-	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+	return core.NewExecuteResultOutputOptions(core.NewDryRunExecutor(executor), func(exec core.Executor, result core.Result) string {
 		return "template=Uploaded directory {{.dir}} to {{.uri}}\n"
 	})
 })
@@ -70,7 +71,7 @@ func uploadDir(ctx context.Context, params uploadDirParams, cfg common.Config) (
 		progressBar, _ = progressBar.Start()
 	}
 
-	err = processCurrentAndSubfolders(ctx, cfg, params.Destination, params.StorageClass, basePath.String(), files, progressBar)
+	err = processCurrentAndSubfolders(ctx, cfg, params.Destination, params.StorageClass, params.ObjectMetadata, basePath.String(), files, progressBar)
 
 	if err != nil {
 		return &uploadDirResult{}, err
@@ -84,7 +85,7 @@ func uploadDir(ctx context.Context, params uploadDirParams, cfg common.Config) (
 	}, nil
 }
 
-func processFile(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI, basePath string, storageClass string, file string, progressBar *pterm.ProgressbarPrinter) error {
+func processFile(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI, basePath string, storageClass string, metadata common.ObjectMetadata, file string, progressBar *pterm.ProgressbarPrinter) error {
 
 	relPath := common.GetRelativePath(basePath, file)
 
@@ -92,7 +93,7 @@ func processFile(ctx context.Context, cfg common.Config, destination mgcSchemaPk
 
 	_, err := upload(
 		ctx,
-		uploadParams{Source: mgcSchemaPkg.FilePath(file), Destination: dst, StorageClass: storageClass},
+		uploadParams{Source: mgcSchemaPkg.FilePath(file), Destination: dst, StorageClass: storageClass, ObjectMetadata: metadata},
 		cfg,
 	)
 
@@ -105,14 +106,14 @@ func processFile(ctx context.Context, cfg common.Config, destination mgcSchemaPk
 	return nil
 }
 
-func worker(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI, basePath string, storageClass string, files <-chan string, results chan<- error, progressBar *pterm.ProgressbarPrinter) {
+func worker(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI, basePath string, storageClass string, metadata common.ObjectMetadata, files <-chan string, results chan<- error, progressBar *pterm.ProgressbarPrinter) {
 	for {
 		select {
 		case file, ok := <-files:
 			if !ok {
 				return
 			}
-			err := processFile(ctx, cfg, destination, basePath, storageClass, file, progressBar)
+			err := processFile(ctx, cfg, destination, basePath, storageClass, metadata, file, progressBar)
 			if err != nil {
 				select {
 				case results <- err:
@@ -126,7 +127,7 @@ func worker(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI
 	}
 }
 
-func processCurrentAndSubfolders(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI, storageClass string, path string, files []string, progressBar *pterm.ProgressbarPrinter) error {
+func processCurrentAndSubfolders(ctx context.Context, cfg common.Config, destination mgcSchemaPkg.URI, storageClass string, metadata common.ObjectMetadata, path string, files []string, progressBar *pterm.ProgressbarPrinter) error {
 	results := make(chan error, cfg.Workers)
 	filesChan := make(chan string, cfg.Workers)
 
@@ -135,7 +136,7 @@ func processCurrentAndSubfolders(ctx context.Context, cfg common.Config, destina
 	for i := 0; i < cfg.Workers; i++ {
 		go func() {
 			defer wg.Done()
-			worker(ctx, cfg, destination, path, storageClass, filesChan, results, progressBar)
+			worker(ctx, cfg, destination, path, storageClass, metadata, filesChan, results, progressBar)
 		}()
 	}
 
@@ -0,0 +1,58 @@
+package uploads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type abortUploadParams struct {
+	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Full path of the object being uploaded,example=my-bucket/dir/file.txt" mgc:"positional"`
+	UploadId    string           `json:"upload_id,omitempty" jsonschema:"description=ID of the multipart upload to abort. If omitted\\, the upload started by this workspace for the object is used"`
+}
+
+var getAbort = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "abort",
+			Description: "Abort a pending multipart upload, discarding all of its uploaded parts",
+		},
+		abortUpload,
+	)
+
+	msg := "This operation will discard all uploaded parts of {{.parameters.dst}}. Do you wish to continue?"
+
+	exec = core.NewConfirmableExecutor(
+		exec,
+		core.ConfirmPromptWithTemplate(msg),
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Aborted upload {{.upload_id}} of {{.dst}}\n"
+	})
+})
+
+func abortUpload(ctx context.Context, params abortUploadParams, cfg common.Config) (*abortUploadParams, error) {
+	if params.UploadId == "" {
+		for _, entry := range common.ListUploadJournal(ctx) {
+			if entry.Destination == params.Destination.String() {
+				params.UploadId = entry.UploadId
+				break
+			}
+		}
+	}
+
+	if params.UploadId == "" {
+		return nil, core.UsageError{Err: fmt.Errorf("no pending upload of %q was started by this workspace, specify the upload ID", params.Destination)}
+	}
+
+	if err := common.AbortMultipartUpload(ctx, cfg, params.Destination, params.UploadId); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
@@ -0,0 +1,21 @@
+package uploads
+
+import (
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+var GetGroup = utils.NewLazyLoader(func() core.Grouper {
+	return core.NewStaticGroup(
+		core.DescriptorSpec{
+			Name:        "uploads",
+			Description: "Pending multipart uploads related operations",
+		},
+		func() []core.Descriptor {
+			return []core.Descriptor{
+				getAbort(), // object-storage objects uploads abort
+				getList(),  // object-storage objects uploads list
+			}
+		},
+	)
+})
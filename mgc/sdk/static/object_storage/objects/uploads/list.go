@@ -0,0 +1,69 @@
+package uploads
+
+import (
+	"context"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type listUploadsParams struct {
+	Destination mgcSchemaPkg.URI `json:"dst" jsonschema:"description=Path of the bucket to list pending uploads from. A path prefix may be given to filter the objects,example=my-bucket/dir/" mgc:"positional"`
+}
+
+type listUploadsResult struct {
+	Key           string `json:"key"`
+	UploadId      string `json:"upload_id"`
+	Initiated     string `json:"initiated"`
+	StorageClass  string `json:"storage_class,omitempty"`
+	Resumable     bool   `json:"resumable"`
+	Source        string `json:"source,omitempty"`
+	UploadedParts int    `json:"uploaded_parts"`
+}
+
+var getList = utils.NewLazyLoader(func() core.Executor {
+	var exec core.Executor = core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "list",
+			Description: "List pending multipart uploads of a bucket",
+			Observations: `Uploads marked as resumable were started by this workspace and are
+resumed by running the same upload command again.`,
+		},
+		listUploads,
+	)
+	exec = core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "table"
+	})
+	return exec
+})
+
+func listUploads(ctx context.Context, params listUploadsParams, cfg common.Config) (result []listUploadsResult, err error) {
+	uploads, err := common.ListMultipartUploads(ctx, cfg, params.Destination)
+	if err != nil {
+		return
+	}
+
+	journaled := map[string]*common.UploadJournalEntry{}
+	for _, entry := range common.ListUploadJournal(ctx) {
+		journaled[entry.UploadId] = entry
+	}
+
+	result = make([]listUploadsResult, 0, len(uploads))
+	for _, upload := range uploads {
+		item := listUploadsResult{
+			Key:          upload.Key,
+			UploadId:     upload.UploadId,
+			Initiated:    upload.Initiated,
+			StorageClass: upload.StorageClass,
+		}
+		if entry, ok := journaled[upload.UploadId]; ok {
+			item.Resumable = true
+			item.Source = entry.Source
+			item.UploadedParts = len(entry.Parts)
+		}
+		result = append(result, item)
+	}
+	return
+}
@@ -0,0 +1,173 @@
+package objects
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/pipeline"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/MagaluCloud/magalu/mgc/sdk/static/object_storage/common"
+)
+
+type verifyParams struct {
+	Local          mgcSchemaPkg.FilePath `json:"local" jsonschema:"description=Local file or directory to verify,example=./dir" mgc:"positional"`
+	Remote         mgcSchemaPkg.URI      `json:"remote" jsonschema:"description=Object or bucket path to verify the local content against,example=my-bucket/dir/" mgc:"positional"`
+	common.Filters `json:",squash"`      // nolint
+}
+
+type verifyStatus string
+
+const (
+	verifyStatusMismatch      verifyStatus = "mismatch"
+	verifyStatusUnverifiable  verifyStatus = "unverifiable"
+	verifyStatusMissingRemote verifyStatus = "missing_remote"
+	verifyStatusMissingLocal  verifyStatus = "missing_local"
+)
+
+type verifyEntry struct {
+	Path   string       `json:"path"`
+	Status verifyStatus `json:"status"`
+}
+
+type verifyResult struct {
+	Local    mgcSchemaPkg.FilePath `json:"local"`
+	Remote   mgcSchemaPkg.URI      `json:"remote"`
+	Verified int                   `json:"verified"`
+	// Relative path of each file that doesn't match to the reason
+	Mismatches map[string]verifyStatus `json:"mismatches,omitempty"`
+}
+
+var getVerify = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	executor := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "verify",
+			Summary: "Verify local files against the objects in a bucket",
+			Description: `Compares the content of a local file or directory with the objects at the remote path, using the
+checksum stored with each object or, if there is none, its ETag. Files missing on either side are reported,
+as well as files that can't be verified, such as objects uploaded in parts of a size other than the configured chunk size.`,
+		},
+		verify,
+	)
+
+	return core.NewExecuteResultOutputOptions(executor, func(exec core.Executor, result core.Result) string {
+		return "template={{if .mismatches}}Found {{len .mismatches}} mismatches between {{.local}} and {{.remote}}:{{range $path, $status := .mismatches}}\n- {{$status}}: {{$path}}{{end}}" +
+			"{{- else}}All {{.verified}} files at {{.local}} match {{.remote}}{{- end}}\n"
+	})
+})
+
+func verify(ctx context.Context, params verifyParams, cfg common.Config) (result verifyResult, err error) {
+	local, err := common.GetAbsSystemURI(mgcSchemaPkg.URI(params.Local.String()))
+	if err != nil {
+		return result, err
+	}
+	remote := params.Remote
+	if !strings.HasPrefix(remote.String(), common.URIPrefix) {
+		remote = common.URIPrefix + remote
+	}
+	result = verifyResult{Local: params.Local, Remote: params.Remote}
+
+	info, err := os.Stat(local.String())
+	if err != nil {
+		return result, core.UsageError{Err: fmt.Errorf("unable to read local path: %w", err)}
+	}
+
+	if !info.IsDir() {
+		if remote.IsRoot() || strings.HasSuffix(remote.String(), "/") {
+			remote = remote.JoinPath(info.Name())
+		}
+		status, err := verifyFile(ctx, local.String(), remote, cfg)
+		if err != nil {
+			return result, err
+		}
+		result.addEntry(info.Name(), status)
+		return result, nil
+	}
+
+	localEndpoint := syncEndpoint{URI: local}
+	remoteEndpoint := syncEndpoint{URI: remote, Remote: true}
+
+	localEntries, err := listSyncEntries(ctx, localEndpoint, params.FilterParams, cfg)
+	if err != nil {
+		return result, err
+	}
+	remoteEntries, err := listSyncEntries(ctx, remoteEndpoint, params.FilterParams, cfg)
+	if err != nil {
+		return result, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	compare := func(ctx context.Context, entry syncEntry) (verifyEntry, pipeline.ProcessStatus) {
+		if _, ok := remoteEntries[entry.Path]; !ok {
+			return verifyEntry{Path: entry.Path, Status: verifyStatusMissingRemote}, pipeline.ProcessOutput
+		}
+
+		status, err := verifyFile(ctx, localEndpoint.join(entry.Path), mgcSchemaPkg.URI(remoteEndpoint.join(entry.Path)), cfg)
+		if err != nil {
+			cancel(&common.ObjectError{Url: mgcSchemaPkg.URI(remoteEndpoint.join(entry.Path)), Err: err})
+			return verifyEntry{}, pipeline.ProcessAbort
+		}
+		return verifyEntry{Path: entry.Path, Status: status}, pipeline.ProcessOutput
+	}
+
+	localSlice := make([]syncEntry, 0, len(localEntries))
+	for _, entry := range localEntries {
+		localSlice = append(localSlice, entry)
+	}
+
+	entriesChan := pipeline.ParallelProcess(pipeline.WithStageName(ctx, "verify entries"), cfg.Workers, pipeline.SliceItemGenerator(ctx, localSlice), compare, nil)
+	entries, err := pipeline.SliceItemConsumer[[]verifyEntry](ctx, entriesChan)
+	if err != nil {
+		return result, err
+	}
+	if err = context.Cause(ctx); err != nil {
+		return result, err
+	}
+
+	for relPath := range remoteEntries {
+		if _, ok := localEntries[relPath]; !ok {
+			entries = append(entries, verifyEntry{Path: relPath, Status: verifyStatusMissingLocal})
+		}
+	}
+
+	for _, entry := range entries {
+		result.addEntry(entry.Path, entry.Status)
+	}
+	return result, nil
+}
+
+// An empty status means the file matches the object
+func (r *verifyResult) addEntry(path string, status verifyStatus) {
+	if status == "" {
+		r.Verified++
+		return
+	}
+	if r.Mismatches == nil {
+		r.Mismatches = map[string]verifyStatus{}
+	}
+	r.Mismatches[path] = status
+}
+
+func verifyFile(ctx context.Context, local string, remote mgcSchemaPkg.URI, cfg common.Config) (verifyStatus, error) {
+	head, err := common.HeadFile(ctx, cfg, remote, "")
+	if err != nil {
+		return "", err
+	}
+
+	matches, verified, err := common.VerifyLocalFile(local, head, cfg)
+	switch {
+	case err != nil:
+		return "", err
+	case !verified:
+		return verifyStatusUnverifiable, nil
+	case !matches:
+		return verifyStatusMismatch, nil
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,16 @@
+package workspace
+
+import (
+	mgcAuthPkg "github.com/MagaluCloud/magalu/mgc/core/auth"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+)
+
+// Keyring markers are never moved, the keyring data is bound to the original workspace directory
+func archiveFilter(withoutCredentials bool) profile_manager.ArchiveFilter {
+	return func(name string) bool {
+		if !mgcAuthPkg.IsPortableFile(name) {
+			return false
+		}
+		return !withoutCredentials || !mgcAuthPkg.IsCredentialFile(name)
+	}
+}
@@ -0,0 +1,54 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+)
+
+type cloneParams struct {
+	Src                string `json:"src" jsonschema_description:"Name of the workspace to be cloned" mgc:"positional"`
+	Dst                string `json:"dst" jsonschema_description:"Name of the new workspace" mgc:"positional"`
+	WithoutCredentials bool   `json:"without-credentials,omitempty" jsonschema_description:"Do not copy the auth tokens and keys, the new workspace will require a new login"`
+}
+
+var getClone = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "clone",
+			Summary: "Clone a workspace",
+			Description: `Create a new workspace with the configuration and auth settings of an existing one, ie: to use
+another region with the same settings.
+
+Credentials stored in the system keyring are never cloned, nor state bound to this machine, such as
+pending uploads and completion caches.`,
+		},
+		clone,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Cloned workspace {{.name}}\n"
+	})
+})
+
+func clone(ctx context.Context, params cloneParams, _ struct{}) (*profile_manager.Profile, error) {
+	m := profile_manager.FromContext(ctx)
+	if m == nil {
+		return nil, WorkspaceError{Name: "", Err: errors.New("couldn't get ProfileManager from context")}
+	}
+
+	src, err := m.Get(params.Src)
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Src, Err: err}
+	}
+
+	p, err := m.Clone(src, params.Dst, archiveFilter(params.WithoutCredentials))
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Dst, Err: err}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/invopop/yaml"
+)
+
+type exportParams struct {
+	Name               string                `json:"name" jsonschema_description:"Workspace name" mgc:"positional"`
+	Dst                mgcSchemaPkg.FilePath `json:"dst" jsonschema:"description=Path of the YAML file to be written,example=./workspace.yaml" mgc:"positional"`
+	WithoutCredentials bool                  `json:"without-credentials,omitempty" jsonschema_description:"Leave out the auth tokens and keys, only the configuration is exported"`
+}
+
+type exportResult struct {
+	Name  string                `json:"name"`
+	Dst   mgcSchemaPkg.FilePath `json:"dst"`
+	Files []string              `json:"files"`
+}
+
+var getExport = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:    "export",
+			Summary: "Export a workspace to a YAML file",
+			Description: `Export the configuration and auth settings of a workspace to a single YAML file, which may be
+imported in another machine with 'workspace import'.
+
+The file holds the workspace credentials unless '--without-credentials' is used, keep it safe.
+Credentials stored in the system keyring are never exported, nor state bound to this machine, such as
+pending uploads and completion caches.`,
+		},
+		export,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Exported workspace {{.name}} to {{.dst}}\n"
+	})
+})
+
+func export(ctx context.Context, params exportParams, _ struct{}) (*exportResult, error) {
+	m := profile_manager.FromContext(ctx)
+	if m == nil {
+		return nil, WorkspaceError{Name: "", Err: errors.New("couldn't get ProfileManager from context")}
+	}
+
+	p, err := m.Get(params.Name)
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: err}
+	}
+
+	archive, err := m.Export(p, archiveFilter(params.WithoutCredentials))
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: err}
+	}
+
+	data, err := yaml.Marshal(archive)
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: err}
+	}
+
+	// Only the owner may read it, as it may contain credentials
+	err = os.WriteFile(params.Dst.String(), data, 0600)
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: err}
+	}
+
+	files := make([]string, 0, len(archive.Files))
+	for name := range archive.Files {
+		files = append(files, name)
+	}
+	slices.Sort(files)
+
+	return &exportResult{Name: p.Name, Dst: params.Dst, Files: files}, nil
+}
@@ -22,6 +22,9 @@ current workspace, so users can alter and switch between workspaces without loos
 				getSet(),
 				getList(),
 				getDelete(),
+				getClone(),
+				getExport(),
+				getImport(),
 			}
 		},
 	)
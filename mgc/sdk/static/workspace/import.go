@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/MagaluCloud/magalu/mgc/core"
+	"github.com/MagaluCloud/magalu/mgc/core/profile_manager"
+	mgcSchemaPkg "github.com/MagaluCloud/magalu/mgc/core/schema"
+	"github.com/MagaluCloud/magalu/mgc/core/utils"
+	"github.com/invopop/yaml"
+)
+
+type importParams struct {
+	Src                mgcSchemaPkg.FilePath `json:"src" jsonschema:"description=Path of the YAML file written by 'workspace export',example=./workspace.yaml" mgc:"positional"`
+	Name               string                `json:"name,omitempty" jsonschema_description:"Name of the new workspace. If not passed, the exported workspace name is used" mgc:"positional"`
+	WithoutCredentials bool                  `json:"without-credentials,omitempty" jsonschema_description:"Do not import the auth tokens and keys present in the file"`
+}
+
+var getImport = utils.NewLazyLoader[core.Executor](func() core.Executor {
+	exec := core.NewStaticExecute(
+		core.DescriptorSpec{
+			Name:        "import",
+			Summary:     "Import a workspace from a YAML file",
+			Description: "Create a new workspace with the contents of a file written by 'workspace export'",
+		},
+		importWorkspace,
+	)
+
+	return core.NewExecuteResultOutputOptions(exec, func(exec core.Executor, result core.Result) string {
+		return "template=Imported workspace {{.name}}\n"
+	})
+})
+
+func importWorkspace(ctx context.Context, params importParams, _ struct{}) (*profile_manager.Profile, error) {
+	m := profile_manager.FromContext(ctx)
+	if m == nil {
+		return nil, WorkspaceError{Name: "", Err: errors.New("couldn't get ProfileManager from context")}
+	}
+
+	data, err := os.ReadFile(params.Src.String())
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: err}
+	}
+
+	archive := &profile_manager.Archive{}
+	if err = yaml.Unmarshal(data, archive); err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: fmt.Errorf("bad format workspace file %q: %w", params.Src, err)}
+	}
+
+	filter := archiveFilter(params.WithoutCredentials)
+	maps.DeleteFunc(archive.Files, func(name string, _ []byte) bool {
+		return !filter(name)
+	})
+
+	p, err := m.Import(params.Name, archive)
+	if err != nil {
+		return nil, WorkspaceError{Name: params.Name, Err: err}
+	}
+
+	return p, nil
+}
@@ -225,8 +225,10 @@ SUPPORTED_WAIT_TERMINATION_CUSTOMIZATIONS = {
     "maxRetries": check_is_number,
     "interval": check_is_string,
     "jsonPathQuery": check_is_string,
+    "jmesPathQuery": check_is_string,
     "templateQuery": check_is_string,
     "errorJsonPathQuery": check_is_string,
+    "errorJmesPathQuery": check_is_string,
     "errorTemplateQuery": check_is_string,
 }
 
@@ -238,8 +240,30 @@ def check_is_wait_termination(v: Any, b: Any) -> None:
         SUPPORTED_WAIT_TERMINATION_CUSTOMIZATIONS,
     )
     assert isinstance(v, dict)
-    if not v.get("jsonPathQuery", v.get("templateQuery")):
-        raise ValueError("missing jsonPathQuery and templateQuery")
+    if not (v.get("jsonPathQuery") or v.get("jmesPathQuery") or v.get("templateQuery")):
+        raise ValueError("missing jsonPathQuery, jmesPathQuery and templateQuery")
+
+
+SUPPORTED_PAGINATION_CUSTOMIZATIONS = {
+    "strategy": check_is_string,
+    "items": check_is_string,
+    "limitParameter": check_is_string,
+    "offsetParameter": check_is_string,
+    "pageSize": check_is_number,
+    "tokenParameter": check_is_string,
+    "nextToken": check_is_string,
+}
+
+
+def check_is_pagination(v: Any, b: Any) -> None:
+    check_is_object(
+        v,
+        b,
+        SUPPORTED_PAGINATION_CUSTOMIZATIONS,
+    )
+    assert isinstance(v, dict)
+    if v.get("strategy") not in ("offset", "token", "link"):
+        raise ValueError("strategy must be 'offset', 'token' or 'link'")
 
 
 SUPPORTED_TAG_MATCH = {"name", "description"}
@@ -467,6 +491,7 @@ SUPPORTED_PATH_METHOD_CUSTOMIZATIONS = {
     "x-mgc-wait-termination": check_is_wait_termination,
     "x-mgc-confirmable": check_is_confirmable,
     "x-mgc-confirmPrompt": check_is_promptInput,
+    "x-mgc-pagination": check_is_pagination,
     "parameters": check_is_path_parameters,
     "responses": check_is_responses,
     "security": check_is_security_list,